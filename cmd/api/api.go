@@ -4,17 +4,39 @@ import (
 	"database/sql"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/services/comments"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/history"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/lookups"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/milestones"
+	"github.com/norrico31/it210-core-service-backend/services/mywork"
+	"github.com/norrico31/it210-core-service-backend/services/notifications"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/outbox"
 	"github.com/norrico31/it210-core-service-backend/services/priorities"
 	"github.com/norrico31/it210-core-service-backend/services/projects"
+	"github.com/norrico31/it210-core-service-backend/services/realtime"
+	"github.com/norrico31/it210-core-service-backend/services/recurrences"
 	"github.com/norrico31/it210-core-service-backend/services/roles"
+	"github.com/norrico31/it210-core-service-backend/services/search"
 	"github.com/norrico31/it210-core-service-backend/services/segments"
+	"github.com/norrico31/it210-core-service-backend/services/sprints"
 	"github.com/norrico31/it210-core-service-backend/services/statuses"
+	"github.com/norrico31/it210-core-service-backend/services/tasks"
 	"github.com/norrico31/it210-core-service-backend/services/tasksproject"
+	"github.com/norrico31/it210-core-service-backend/services/templates"
 	"github.com/norrico31/it210-core-service-backend/services/users"
+	"github.com/norrico31/it210-core-service-backend/services/views"
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
+	"github.com/norrico31/it210-core-service-backend/services/workload"
 	"github.com/norrico31/it210-core-service-backend/services/workspaces"
 )
 
@@ -56,38 +78,122 @@ func (s *APIServer) Run() {
 
 	subrouterv1 := router.PathPrefix("/api/v1/core").Subrouter()
 
-	roleStore := roles.NewStore(s.db)
-	roleHandler := roles.NewHandler(roleStore)
-	roles.RegisterRoutes(subrouterv1, roleHandler)
+	memberStore := members.NewStore(s.db)
+	memberHandler := members.NewHandler(memberStore)
+	members.RegisterRoutes(subrouterv1, memberHandler)
 
-	statusStore := statuses.NewStore(s.db)
-	statusHandler := statuses.NewHandler(statusStore)
-	statuses.RegisterRoutes(subrouterv1, statusHandler)
+	roleStore := lookups.NewStore(s.db, roles.Kind)
+	roleHandler := lookups.NewHandler(roleStore, roles.Kind, memberStore)
+	lookups.RegisterRoutes(subrouterv1, roleHandler)
 
-	proprityStore := priorities.NewStore(s.db)
-	proprityHandler := priorities.NewHandler(proprityStore)
-	priorities.RegisterRoutes(subrouterv1, proprityHandler)
+	statusStore := lookups.NewStore(s.db, statuses.Kind)
+	statusHandler := lookups.NewHandler(statusStore, statuses.Kind, memberStore)
+	lookups.RegisterRoutes(subrouterv1, statusHandler)
+
+	proprityStore := lookups.NewStore(s.db, priorities.Kind)
+	proprityHandler := lookups.NewHandler(proprityStore, priorities.Kind, memberStore)
+	lookups.RegisterRoutes(subrouterv1, proprityHandler)
+
+	priorityRankStore := priorities.NewStore(s.db)
+	priorityRankHandler := priorities.NewHandler(priorityRankStore, memberStore)
+	priorities.RegisterRoutes(subrouterv1, priorityRankHandler)
 
 	segmentStore := segments.NewStore(s.db)
-	segmentHandler := segments.NewHandler(segmentStore)
+	segmentHandler := segments.NewHandler(segmentStore, memberStore)
 	segments.RegisterRoutes(subrouterv1, segmentHandler)
 
+	organizationStore := organizations.NewStore(s.db)
+	organizationHandler := organizations.NewHandler(organizationStore, memberStore)
+	organizations.RegisterRoutes(subrouterv1, organizationHandler)
+
 	workspaceStore := workspaces.NewStore(s.db)
-	workspaceHandler := workspaces.NewHandler(workspaceStore)
+	workspaceHandler := workspaces.NewHandler(workspaceStore, memberStore)
 	workspaces.RegisterRoutes(subrouterv1, workspaceHandler)
 
 	usersStore := users.NewStore(s.db)
-	usersHandler := users.NewHandler(usersStore)
+	usersHandler := users.NewHandler(usersStore, memberStore)
 	users.RegisterRoutes(subrouterv1, usersHandler)
 
+	customFieldStore := customfields.NewStore(s.db)
+	customFieldHandler := customfields.NewHandler(customFieldStore, memberStore)
+	customfields.RegisterRoutes(subrouterv1, customFieldHandler)
+
 	tasksProject := tasksproject.NewStore(s.db)
-	tasksProjectStore := tasksproject.NewHandler(tasksProject)
+	tasksProjectStore := tasksproject.NewHandler(tasksProject, customFieldStore, memberStore)
 	tasksproject.RegisterRoutes(subrouterv1, tasksProjectStore)
 
 	projectStore := projects.NewStore(s.db)
-	projecthandler := projects.NewHandler(projectStore)
+	projecthandler := projects.NewHandler(projectStore, memberStore)
 	projects.RegisterRoutes(subrouterv1, projecthandler)
 
+	templateStore := templates.NewStore(s.db)
+	templateHandler := templates.NewHandler(templateStore, memberStore)
+	templates.RegisterRoutes(subrouterv1, templateHandler)
+
+	sprintStore := sprints.NewStore(s.db)
+	sprintHandler := sprints.NewHandler(sprintStore, memberStore)
+	sprints.RegisterRoutes(subrouterv1, sprintHandler)
+
+	milestoneStore := milestones.NewStore(s.db)
+	milestoneHandler := milestones.NewHandler(milestoneStore, memberStore)
+	milestones.RegisterRoutes(subrouterv1, milestoneHandler)
+
+	workflowStore := workflows.NewStore(s.db)
+	workflowHandler := workflows.NewHandler(workflowStore, memberStore)
+	workflows.RegisterRoutes(subrouterv1, workflowHandler)
+
+	searchStore := search.NewStore(s.db)
+	searchHandler := search.NewHandler(searchStore, memberStore)
+	search.RegisterRoutes(subrouterv1, searchHandler)
+
+	viewStore := views.NewStore(s.db)
+	viewHandler := views.NewHandler(viewStore, tasks.NewStore(s.db), tasksProject, projectStore, memberStore)
+	views.RegisterRoutes(subrouterv1, viewHandler)
+
+	myWorkStore := mywork.NewStore(s.db)
+	myWorkHandler := mywork.NewHandler(myWorkStore, memberStore)
+	mywork.RegisterRoutes(subrouterv1, myWorkHandler)
+
+	workloadStore := workload.NewStore(s.db)
+	workloadHandler := workload.NewHandler(workloadStore, memberStore)
+	workload.RegisterRoutes(subrouterv1, workloadHandler)
+
+	labelStore := labels.NewStore(s.db)
+	labelHandler := labels.NewHandler(labelStore, memberStore)
+	labels.RegisterRoutes(subrouterv1, labelHandler)
+
+	recurrenceStore := recurrences.NewStore(s.db)
+	recurrenceHandler := recurrences.NewHandler(recurrenceStore, memberStore)
+	recurrences.RegisterRoutes(subrouterv1, recurrenceHandler)
+	recurrences.StartScheduler(recurrenceStore, time.Duration(config.Envs.RecurrenceIntervalSeconds)*time.Second)
+
+	historyStore := history.NewStore(s.db)
+	historyHandler := history.NewHandler(historyStore, memberStore)
+	history.RegisterRoutes(subrouterv1, historyHandler)
+
+	notificationStore := notifications.NewStore(s.db)
+	notificationHandler := notifications.NewHandler(notificationStore, memberStore)
+	notifications.RegisterRoutes(subrouterv1, notificationHandler)
+
+	commentStore := comments.NewStore(s.db)
+	commentHandler := comments.NewHandler(commentStore, memberStore)
+	comments.RegisterRoutes(subrouterv1, commentHandler)
+
+	realtimeHandler := realtime.NewHandler(realtime.Broker(), memberStore)
+	realtime.RegisterRoutes(subrouterv1, realtimeHandler)
+
+	webhookStore := webhooks.NewStore(s.db)
+	webhookHandler := webhooks.NewHandler(webhookStore, memberStore)
+	webhooks.RegisterRoutes(subrouterv1, webhookHandler)
+	webhooks.StartDispatcher(webhookStore, time.Duration(config.Envs.WebhookIntervalSeconds)*time.Second)
+
+	outboxSink, err := outbox.NewSink(config.Envs.OutboxSink, config.Envs.OutboxHTTPURL)
+	if err != nil {
+		log.Fatalf("could not configure outbox: %v", err)
+	}
+	outboxRelay := outbox.NewRelay(s.db, outboxSink, int(config.Envs.OutboxBatchSize))
+	outbox.StartRelay(outboxRelay, time.Duration(config.Envs.OutboxIntervalSeconds)*time.Second)
+
 	// CORS configuration
 	corsHandler := handlers.CORS(
 		// url frontend (vercel?railway?aws)
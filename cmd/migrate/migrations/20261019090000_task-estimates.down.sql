@@ -0,0 +1,4 @@
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS estimateHours,
+    DROP COLUMN IF EXISTS storyPoints;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimateHours,
+    DROP COLUMN IF EXISTS storyPoints;
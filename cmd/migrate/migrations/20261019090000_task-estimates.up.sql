@@ -0,0 +1,6 @@
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS storyPoints INT CHECK (storyPoints >= 0),
+    ADD COLUMN IF NOT EXISTS estimateHours DECIMAL(7, 2) CHECK (estimateHours >= 0);
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS storyPoints INT CHECK (storyPoints >= 0),
+    ADD COLUMN IF NOT EXISTS estimateHours DECIMAL(7, 2) CHECK (estimateHours >= 0);
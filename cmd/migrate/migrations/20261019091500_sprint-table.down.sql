@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS sprint_task_events;
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS completedAt,
+    DROP COLUMN IF EXISTS sprintId;
+DROP TABLE IF EXISTS sprints;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS sprints (
+    id SERIAL PRIMARY KEY,
+    projectId INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    goal TEXT,
+    dateStart TIMESTAMP NOT NULL,
+    dateEnd TIMESTAMP NOT NULL,
+    state VARCHAR(20) NOT NULL DEFAULT 'planned' CHECK (state IN ('planned', 'active', 'closed')),
+    closedAt TIMESTAMP,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL,
+        CHECK (dateEnd > dateStart)
+);
+-- Only one sprint per project can be running at a time
+CREATE UNIQUE INDEX IF NOT EXISTS sprints_one_active_per_project ON sprints (projectId)
+WHERE state = 'active'
+    AND deletedAt IS NULL;
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS sprintId INT REFERENCES sprints(id) ON DELETE
+SET NULL,
+    ADD COLUMN IF NOT EXISTS completedAt TIMESTAMP;
+-- Scope and completion history used to build burndown/burnup series
+CREATE TABLE IF NOT EXISTS sprint_task_events (
+    id SERIAL PRIMARY KEY,
+    sprintId INT NOT NULL REFERENCES sprints(id) ON DELETE CASCADE,
+    taskId INT NOT NULL REFERENCES project_tasks(id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL CHECK (
+        event IN ('added', 'removed', 'completed', 'reopened')
+    ),
+    storyPoints INT,
+    occurredAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS sprint_task_events_sprint_idx ON sprint_task_events (sprintId, occurredAt);
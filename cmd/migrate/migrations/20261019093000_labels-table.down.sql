@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS labels_project_tasks;
+DROP TABLE IF EXISTS labels_tasks;
+DROP TABLE IF EXISTS labels_projects;
+DROP TABLE IF EXISTS labels;
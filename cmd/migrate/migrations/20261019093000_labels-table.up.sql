@@ -0,0 +1,29 @@
+-- projectId NULL means the label is global and can be used by every project
+CREATE TABLE IF NOT EXISTS labels (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#6B7280',
+    projectId INT REFERENCES projects(id) ON DELETE CASCADE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS labels_unique_name ON labels (COALESCE(projectId, 0), LOWER(name))
+WHERE deletedAt IS NULL;
+CREATE TABLE IF NOT EXISTS labels_projects (
+    labelId INT REFERENCES labels(id) ON DELETE CASCADE,
+    projectId INT REFERENCES projects(id) ON DELETE CASCADE,
+    PRIMARY KEY (labelId, projectId)
+);
+CREATE TABLE IF NOT EXISTS labels_tasks (
+    labelId INT REFERENCES labels(id) ON DELETE CASCADE,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (labelId, taskId)
+);
+CREATE TABLE IF NOT EXISTS labels_project_tasks (
+    labelId INT REFERENCES labels(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (labelId, projectTaskId)
+);
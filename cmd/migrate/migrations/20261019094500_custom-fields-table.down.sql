@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS custom_field_values_project_tasks;
+DROP TABLE IF EXISTS custom_field_values_tasks;
+DROP TABLE IF EXISTS custom_fields;
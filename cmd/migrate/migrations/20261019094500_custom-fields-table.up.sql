@@ -0,0 +1,37 @@
+-- options holds the allowed values of select and multi_select fields
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id SERIAL PRIMARY KEY,
+    projectId INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    fieldType VARCHAR(20) NOT NULL CHECK (
+        fieldType IN (
+            'text',
+            'number',
+            'date',
+            'select',
+            'multi_select',
+            'user'
+        )
+    ),
+    options TEXT [] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS custom_fields_unique_name ON custom_fields (projectId, LOWER(name))
+WHERE deletedAt IS NULL;
+CREATE TABLE IF NOT EXISTS custom_field_values_tasks (
+    fieldId INT REFERENCES custom_fields(id) ON DELETE CASCADE,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    value JSONB NOT NULL,
+    PRIMARY KEY (fieldId, taskId)
+);
+CREATE TABLE IF NOT EXISTS custom_field_values_project_tasks (
+    fieldId INT REFERENCES custom_fields(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    value JSONB NOT NULL,
+    PRIMARY KEY (fieldId, projectTaskId)
+);
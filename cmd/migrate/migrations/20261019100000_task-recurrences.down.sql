@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS task_recurrences;
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS dueDate;
+ALTER TABLE tasks DROP COLUMN IF EXISTS completedAt,
+    DROP COLUMN IF EXISTS dueDate;
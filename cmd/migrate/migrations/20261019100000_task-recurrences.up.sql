@@ -0,0 +1,22 @@
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS dueDate TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS completedAt TIMESTAMP;
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS dueDate TIMESTAMP;
+-- A recurrence follows its current occurrence: exactly one of taskId or
+-- projectTaskId is set and moves to the new task each time one is materialized
+CREATE TABLE IF NOT EXISTS task_recurrences (
+    id SERIAL PRIMARY KEY,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    rule VARCHAR(255) NOT NULL,
+    occurrences INT NOT NULL DEFAULT 1,
+    endedAt TIMESTAMP,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((taskId IS NULL) <> (projectTaskId IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS task_recurrences_task ON task_recurrences (taskId)
+WHERE taskId IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS task_recurrences_project_task ON task_recurrences (projectTaskId)
+WHERE projectTaskId IS NOT NULL;
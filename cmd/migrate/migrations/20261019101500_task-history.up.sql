@@ -0,0 +1,17 @@
+-- Field level change log of board tasks (taskId) and project tasks (projectTaskId)
+CREATE TABLE IF NOT EXISTS task_history (
+    id SERIAL PRIMARY KEY,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    oldValue TEXT,
+    newValue TEXT,
+    changedBy INT REFERENCES users(id) ON DELETE
+    SET NULL,
+        changedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        CHECK ((taskId IS NULL) <> (projectTaskId IS NULL))
+);
+CREATE INDEX IF NOT EXISTS task_history_task ON task_history (taskId, changedAt)
+WHERE taskId IS NOT NULL;
+CREATE INDEX IF NOT EXISTS task_history_project_task ON task_history (projectTaskId, changedAt)
+WHERE projectTaskId IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS watchers_project_tasks;
+DROP TABLE IF EXISTS watchers_tasks;
+DROP TABLE IF EXISTS watchers_projects;
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id SERIAL PRIMARY KEY,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    userId INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    CHECK ((taskId IS NULL) <> (projectTaskId IS NULL))
+);
+CREATE INDEX IF NOT EXISTS comments_task ON comments (taskId)
+WHERE taskId IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_project_task ON comments (projectTaskId)
+WHERE projectTaskId IS NOT NULL;
+CREATE TABLE IF NOT EXISTS watchers_projects (
+    userId INT REFERENCES users(id) ON DELETE CASCADE,
+    projectId INT REFERENCES projects(id) ON DELETE CASCADE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (userId, projectId)
+);
+CREATE TABLE IF NOT EXISTS watchers_tasks (
+    userId INT REFERENCES users(id) ON DELETE CASCADE,
+    taskId INT REFERENCES tasks(id) ON DELETE CASCADE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (userId, taskId)
+);
+CREATE TABLE IF NOT EXISTS watchers_project_tasks (
+    userId INT REFERENCES users(id) ON DELETE CASCADE,
+    projectTaskId INT REFERENCES project_tasks(id) ON DELETE CASCADE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (userId, projectTaskId)
+);
+-- target/targetId point at the task or project the notification is about
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    userId INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL CHECK (
+        type IN (
+            'assigned',
+            'moved',
+            'status_changed',
+            'commented',
+            'mentioned'
+        )
+    ),
+    actorId INT REFERENCES users(id) ON DELETE
+    SET NULL,
+        target VARCHAR(20) NOT NULL,
+        targetId INT NOT NULL,
+        projectId INT REFERENCES projects(id) ON DELETE CASCADE,
+        message TEXT NOT NULL,
+        readAt TIMESTAMP,
+        createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS notifications_inbox ON notifications (userId, createdAt DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread ON notifications (userId)
+WHERE readAt IS NULL;
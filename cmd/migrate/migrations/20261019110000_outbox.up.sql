@@ -0,0 +1,15 @@
+-- Domain events written in the same transaction as the change they describe.
+-- The relay publishes them in id order and stamps publishedAt.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    aggregateType VARCHAR(50) NOT NULL,
+    aggregateId INT NOT NULL,
+    eventType VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    lastError TEXT,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    publishedAt TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished ON outbox_events (id)
+WHERE publishedAt IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE users_projects DROP COLUMN IF EXISTS role;
@@ -0,0 +1,2 @@
+ALTER TABLE users_projects
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'maintainer', 'member', 'viewer'));
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS labels_unique_name;
+ALTER TABLE labels DROP COLUMN IF EXISTS orgId;
+CREATE UNIQUE INDEX IF NOT EXISTS labels_unique_name ON labels (COALESCE(projectId, 0), LOWER(name))
+WHERE deletedAt IS NULL;
+ALTER TABLE roles DROP COLUMN IF EXISTS orgId;
+ALTER TABLE priorities DROP COLUMN IF EXISTS orgId;
+ALTER TABLE statuses DROP COLUMN IF EXISTS orgId;
+ALTER TABLE segments DROP COLUMN IF EXISTS orgId;
+ALTER TABLE projects DROP COLUMN IF EXISTS orgId;
+ALTER TABLE users DROP COLUMN IF EXISTS orgId;
+DROP TABLE IF EXISTS organizations;
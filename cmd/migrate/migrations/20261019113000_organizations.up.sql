@@ -0,0 +1,38 @@
+-- Every tenant owned row points at an organization; existing data moves to
+-- the default organization with id 1
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL
+);
+INSERT INTO organizations (id, name)
+VALUES (1, 'Default') ON CONFLICT (id) DO NOTHING;
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), GREATEST((SELECT MAX(id) FROM organizations), 1));
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE segments
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE statuses
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE priorities
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE roles
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE labels
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+-- Label names are unique per organization and project
+DROP INDEX IF EXISTS labels_unique_name;
+CREATE UNIQUE INDEX IF NOT EXISTS labels_unique_name ON labels (orgId, COALESCE(projectId, 0), LOWER(name))
+WHERE deletedAt IS NULL;
+CREATE INDEX IF NOT EXISTS users_org ON users (orgId);
+CREATE INDEX IF NOT EXISTS projects_org ON projects (orgId);
+CREATE INDEX IF NOT EXISTS segments_org ON segments (orgId);
+CREATE INDEX IF NOT EXISTS statuses_org ON statuses (orgId);
+CREATE INDEX IF NOT EXISTS priorities_org ON priorities (orgId);
+CREATE INDEX IF NOT EXISTS roles_org ON roles (orgId);
@@ -0,0 +1,19 @@
+-- definition is a JSON snapshot of the columns, labels, tasks and optionally
+-- members of the source project
+CREATE TABLE IF NOT EXISTS project_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    orgId INT NOT NULL REFERENCES organizations(id),
+    sourceProjectId INT REFERENCES projects(id) ON DELETE
+    SET NULL,
+        includesMembers BOOLEAN NOT NULL DEFAULT FALSE,
+        definition JSONB NOT NULL,
+        createdBy INT REFERENCES users(id) ON DELETE
+    SET NULL,
+        createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        deletedAt TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS project_templates_org ON project_templates (orgId)
+WHERE deletedAt IS NULL;
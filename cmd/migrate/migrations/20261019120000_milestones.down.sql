@@ -0,0 +1,2 @@
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS milestoneId;
+DROP TABLE IF EXISTS milestones;
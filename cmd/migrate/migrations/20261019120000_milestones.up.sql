@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS milestones (
+    id SERIAL PRIMARY KEY,
+    projectId INT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    dueDate TIMESTAMP NOT NULL,
+    completedAt TIMESTAMP,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL
+);
+CREATE INDEX IF NOT EXISTS milestones_project_idx ON milestones (projectId, dueDate);
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS milestoneId INT REFERENCES milestones(id) ON DELETE
+SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS archivedBy,
+    DROP COLUMN IF EXISTS archivedAt;
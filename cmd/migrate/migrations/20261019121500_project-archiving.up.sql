@@ -0,0 +1,4 @@
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS archivedAt TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS archivedBy INT REFERENCES users(id) ON DELETE
+SET NULL;
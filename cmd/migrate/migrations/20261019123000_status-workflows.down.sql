@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS workflow_transitions;
+DROP TABLE IF EXISTS workflows;
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS statusId;
+ALTER TABLE tasks DROP COLUMN IF EXISTS statusId;
+ALTER TABLE statuses DROP COLUMN IF EXISTS category;
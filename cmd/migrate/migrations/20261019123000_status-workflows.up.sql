@@ -0,0 +1,39 @@
+-- Statuses fall into a category that tells whether work is done
+ALTER TABLE statuses
+ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT 'todo' CHECK (category IN ('todo', 'in_progress', 'done'));
+UPDATE statuses
+SET category = 'in_progress'
+WHERE LOWER(name) IN ('active', 'in progress');
+UPDATE statuses
+SET category = 'done'
+WHERE LOWER(name) IN ('completed', 'done', 'archived');
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS statusId INT REFERENCES statuses(id) ON DELETE
+SET NULL;
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS statusId INT REFERENCES statuses(id) ON DELETE
+SET NULL;
+-- An organization has at most one workflow per kind of record; without one
+-- any status change is allowed
+CREATE TABLE IF NOT EXISTS workflows (
+    id SERIAL PRIMARY KEY,
+    orgId INT NOT NULL REFERENCES organizations(id),
+    name VARCHAR(100) NOT NULL,
+    target VARCHAR(20) NOT NULL CHECK (target IN ('projects', 'tasks', 'project-tasks')),
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    deletedBy INT REFERENCES users(id) ON DELETE
+    SET NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS workflows_one_per_target ON workflows (orgId, target)
+WHERE deletedAt IS NULL;
+-- A NULL fromStatusId allows the move from any status
+CREATE TABLE IF NOT EXISTS workflow_transitions (
+    id SERIAL PRIMARY KEY,
+    workflowId INT NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    fromStatusId INT REFERENCES statuses(id) ON DELETE CASCADE,
+    toStatusId INT NOT NULL REFERENCES statuses(id) ON DELETE CASCADE,
+    requiredFields TEXT [] NOT NULL DEFAULT '{}'
+);
+CREATE UNIQUE INDEX IF NOT EXISTS workflow_transitions_unique ON workflow_transitions (workflowId, COALESCE(fromStatusId, 0), toStatusId);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS priorities_rank;
+ALTER TABLE priorities DROP COLUMN IF EXISTS resolutionSlaHours,
+    DROP COLUMN IF EXISTS responseSlaHours,
+    DROP COLUMN IF EXISTS icon,
+    DROP COLUMN IF EXISTS color,
+    DROP COLUMN IF EXISTS rank;
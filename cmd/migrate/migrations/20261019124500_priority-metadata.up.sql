@@ -0,0 +1,21 @@
+-- Priorities are ordered by rank, 1 being the most urgent, and may carry
+-- response and resolution targets in hours
+ALTER TABLE priorities
+ADD COLUMN IF NOT EXISTS rank INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS color VARCHAR(7) NOT NULL DEFAULT '#6B7280',
+    ADD COLUMN IF NOT EXISTS icon VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS responseSlaHours INT CHECK (responseSlaHours > 0),
+    ADD COLUMN IF NOT EXISTS resolutionSlaHours INT CHECK (resolutionSlaHours > 0);
+-- Existing priorities were seeded from the least to the most urgent
+UPDATE priorities p
+SET rank = ranked.rank
+FROM (
+        SELECT id,
+            ROW_NUMBER() OVER (
+                PARTITION BY orgId
+                ORDER BY id DESC
+            ) AS rank
+        FROM priorities
+    ) ranked
+WHERE ranked.id = p.id;
+CREATE INDEX IF NOT EXISTS priorities_rank ON priorities (orgId, rank);
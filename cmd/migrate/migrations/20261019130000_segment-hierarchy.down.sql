@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS segments_parent;
+ALTER TABLE segments DROP COLUMN IF EXISTS parentId;
@@ -0,0 +1,6 @@
+-- Segments nest into divisions and sub-units; a segment without a parent is
+-- at the top of the tree
+ALTER TABLE segments
+ADD COLUMN IF NOT EXISTS parentId INT REFERENCES segments(id) ON DELETE
+SET NULL CHECK (parentId <> id);
+CREATE INDEX IF NOT EXISTS segments_parent ON segments (parentId);
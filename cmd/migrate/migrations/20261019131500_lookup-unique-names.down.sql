@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS priorities_unique_name;
+DROP INDEX IF EXISTS statuses_unique_name;
+DROP INDEX IF EXISTS roles_unique_name;
@@ -0,0 +1,41 @@
+-- Live lookup names are unique per organization regardless of case. Existing
+-- duplicates keep the oldest name and have their id appended.
+UPDATE roles r
+SET name = r.name || ' (' || r.id || ')'
+WHERE r.deletedAt IS NULL
+    AND EXISTS (
+        SELECT 1
+        FROM roles o
+        WHERE o.orgId = r.orgId
+            AND LOWER(o.name) = LOWER(r.name)
+            AND o.deletedAt IS NULL
+            AND o.id < r.id
+    );
+UPDATE statuses s
+SET name = s.name || ' (' || s.id || ')'
+WHERE s.deletedAt IS NULL
+    AND EXISTS (
+        SELECT 1
+        FROM statuses o
+        WHERE o.orgId = s.orgId
+            AND LOWER(o.name) = LOWER(s.name)
+            AND o.deletedAt IS NULL
+            AND o.id < s.id
+    );
+UPDATE priorities p
+SET name = p.name || ' (' || p.id || ')'
+WHERE p.deletedAt IS NULL
+    AND EXISTS (
+        SELECT 1
+        FROM priorities o
+        WHERE o.orgId = p.orgId
+            AND LOWER(o.name) = LOWER(p.name)
+            AND o.deletedAt IS NULL
+            AND o.id < p.id
+    );
+CREATE UNIQUE INDEX IF NOT EXISTS roles_unique_name ON roles (orgId, LOWER(name))
+WHERE deletedAt IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS statuses_unique_name ON statuses (orgId, LOWER(name))
+WHERE deletedAt IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS priorities_unique_name ON priorities (orgId, LOWER(name))
+WHERE deletedAt IS NULL;
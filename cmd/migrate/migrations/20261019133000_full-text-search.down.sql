@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS users_search ON users;
+DROP TRIGGER IF EXISTS project_tasks_search ON project_tasks;
+DROP TRIGGER IF EXISTS tasks_search ON tasks;
+DROP TRIGGER IF EXISTS projects_search ON projects;
+DROP FUNCTION IF EXISTS users_search_vector();
+DROP FUNCTION IF EXISTS project_tasks_search_vector();
+DROP FUNCTION IF EXISTS tasks_search_vector();
+DROP FUNCTION IF EXISTS projects_search_vector();
+DROP INDEX IF EXISTS users_search;
+DROP INDEX IF EXISTS project_tasks_search;
+DROP INDEX IF EXISTS tasks_search;
+DROP INDEX IF EXISTS projects_search;
+ALTER TABLE users DROP COLUMN IF EXISTS searchVector;
+ALTER TABLE project_tasks DROP COLUMN IF EXISTS searchVector;
+ALTER TABLE tasks DROP COLUMN IF EXISTS searchVector;
+ALTER TABLE projects DROP COLUMN IF EXISTS searchVector;
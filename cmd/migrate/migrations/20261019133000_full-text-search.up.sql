@@ -0,0 +1,65 @@
+-- Each searchable table keeps a weighted tsvector in searchVector, kept current
+-- by a trigger; names weigh more than descriptions
+ALTER TABLE projects
+ADD COLUMN IF NOT EXISTS searchVector tsvector;
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS searchVector tsvector;
+ALTER TABLE project_tasks
+ADD COLUMN IF NOT EXISTS searchVector tsvector;
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS searchVector tsvector;
+CREATE OR REPLACE FUNCTION projects_search_vector() RETURNS TRIGGER AS $$ BEGIN
+    NEW.searchVector := setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B');
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE OR REPLACE FUNCTION tasks_search_vector() RETURNS TRIGGER AS $$ BEGIN
+    NEW.searchVector := setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B');
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE OR REPLACE FUNCTION project_tasks_search_vector() RETURNS TRIGGER AS $$ BEGIN
+    NEW.searchVector := setweight(to_tsvector('english', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B');
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- Emails are also split on their punctuation so "jane" finds jane.doe@example.com
+CREATE OR REPLACE FUNCTION users_search_vector() RETURNS TRIGGER AS $$ BEGIN
+    NEW.searchVector := setweight(to_tsvector('simple', NEW.firstName || ' ' || NEW.lastName), 'A') ||
+        setweight(to_tsvector('simple', NEW.email || ' ' || regexp_replace(NEW.email, '[@._+-]', ' ', 'g')), 'B');
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER projects_search BEFORE
+INSERT
+    OR
+UPDATE OF name,
+    description ON projects FOR EACH ROW EXECUTE FUNCTION projects_search_vector();
+CREATE TRIGGER tasks_search BEFORE
+INSERT
+    OR
+UPDATE OF title,
+    description ON tasks FOR EACH ROW EXECUTE FUNCTION tasks_search_vector();
+CREATE TRIGGER project_tasks_search BEFORE
+INSERT
+    OR
+UPDATE OF name,
+    description ON project_tasks FOR EACH ROW EXECUTE FUNCTION project_tasks_search_vector();
+CREATE TRIGGER users_search BEFORE
+INSERT
+    OR
+UPDATE OF firstName,
+    lastName,
+    email ON users FOR EACH ROW EXECUTE FUNCTION users_search_vector();
+-- Touching the columns fires the triggers for existing rows
+UPDATE projects SET name = name;
+UPDATE tasks SET title = title;
+UPDATE project_tasks SET name = name;
+UPDATE users SET email = email;
+CREATE INDEX IF NOT EXISTS projects_search ON projects USING GIN (searchVector);
+CREATE INDEX IF NOT EXISTS tasks_search ON tasks USING GIN (searchVector);
+CREATE INDEX IF NOT EXISTS project_tasks_search ON project_tasks USING GIN (searchVector);
+CREATE INDEX IF NOT EXISTS users_search ON users USING GIN (searchVector);
@@ -0,0 +1,24 @@
+-- A saved view stores the filters and sort of a task or project listing for
+-- its owner; shared views are visible to the members of projectId
+CREATE TABLE IF NOT EXISTS saved_views (
+    id SERIAL PRIMARY KEY,
+    orgId INT NOT NULL REFERENCES organizations(id),
+    userId INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    entityType VARCHAR(20) NOT NULL CHECK (entityType IN ('tasks', 'projectTasks', 'projects')),
+    projectId INT REFERENCES projects(id) ON DELETE CASCADE,
+    shared BOOLEAN NOT NULL DEFAULT FALSE CHECK (
+        NOT shared
+        OR projectId IS NOT NULL
+    ),
+    filters JSONB NOT NULL DEFAULT '{}',
+    sort VARCHAR(20) NOT NULL DEFAULT '',
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS saved_views_user ON saved_views (orgId, userId)
+WHERE deletedAt IS NULL;
+CREATE INDEX IF NOT EXISTS saved_views_project ON saved_views (projectId)
+WHERE shared
+    AND deletedAt IS NULL;
@@ -0,0 +1,7 @@
+-- Hours a user can work in a week; workload reports show utilization against
+-- it when it is set
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS weeklyCapacityHours DECIMAL(5, 2) CHECK (
+        weeklyCapacityHours >= 0
+        AND weeklyCapacityHours <= 168
+    );
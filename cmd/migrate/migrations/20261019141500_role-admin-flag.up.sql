@@ -0,0 +1,7 @@
+-- Admin rights come from an explicit flag instead of the role name, which any
+-- role edit could change
+ALTER TABLE roles
+ADD COLUMN IF NOT EXISTS isAdmin BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE roles
+SET isAdmin = TRUE
+WHERE LOWER(name) = 'admin';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS webhooks_org;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS orgId;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS orgId;
@@ -0,0 +1,8 @@
+-- Webhooks and outbox events belong to the organization whose data they carry;
+-- existing rows stay with the default organization
+ALTER TABLE webhooks
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+ALTER TABLE outbox_events
+ADD COLUMN IF NOT EXISTS orgId INT NOT NULL DEFAULT 1 REFERENCES organizations(id);
+CREATE INDEX IF NOT EXISTS webhooks_org ON webhooks (orgId)
+WHERE deletedAt IS NULL;
@@ -0,0 +1,4 @@
+-- Lets the relay find the failed events that hold back the rest of their
+-- aggregate
+CREATE INDEX IF NOT EXISTS outbox_events_aggregate ON outbox_events (aggregateType, aggregateId, id)
+WHERE publishedAt IS NULL;
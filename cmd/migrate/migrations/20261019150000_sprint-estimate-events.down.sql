@@ -0,0 +1,7 @@
+DELETE FROM sprint_task_events
+WHERE event = 'estimated';
+ALTER TABLE sprint_task_events DROP CONSTRAINT IF EXISTS sprint_task_events_event_check;
+ALTER TABLE sprint_task_events
+ADD CONSTRAINT sprint_task_events_event_check CHECK (
+        event IN ('added', 'removed', 'completed', 'reopened')
+    );
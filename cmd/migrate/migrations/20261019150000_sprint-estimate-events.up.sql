@@ -0,0 +1,6 @@
+-- Story point changes of tasks in a sprint are part of its history
+ALTER TABLE sprint_task_events DROP CONSTRAINT IF EXISTS sprint_task_events_event_check;
+ALTER TABLE sprint_task_events
+ADD CONSTRAINT sprint_task_events_event_check CHECK (
+        event IN ('added', 'removed', 'completed', 'reopened', 'estimated')
+    );
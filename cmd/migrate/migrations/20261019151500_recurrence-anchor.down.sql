@@ -0,0 +1 @@
+ALTER TABLE task_recurrences DROP COLUMN IF EXISTS anchorAt;
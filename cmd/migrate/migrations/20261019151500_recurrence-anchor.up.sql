@@ -0,0 +1,5 @@
+-- Occurrences are counted from the due date the series started at, so a day
+-- clamped to a short month comes back the month after. NULL until the first
+-- occurrence is materialized, and again when the rule changes.
+ALTER TABLE task_recurrences
+ADD COLUMN IF NOT EXISTS anchorAt TIMESTAMP;
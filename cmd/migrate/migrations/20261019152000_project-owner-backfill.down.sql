@@ -0,0 +1 @@
+-- The owners picked by the backfill cannot be told apart from chosen ones, so they stay
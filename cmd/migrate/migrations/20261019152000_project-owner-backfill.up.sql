@@ -0,0 +1,22 @@
+-- Projects that predate member roles have no owner, so only admins could
+-- manage their members. Their first member becomes the owner; memberships
+-- carry no join date, so the lowest user id stands in for it.
+UPDATE users_projects up
+SET role = 'owner'
+FROM (
+        SELECT DISTINCT ON (project_id) project_id,
+            user_id
+        FROM users_projects
+        WHERE deletedAt IS NULL
+        ORDER BY project_id,
+            user_id
+    ) first
+WHERE up.project_id = first.project_id
+    AND up.user_id = first.user_id
+    AND NOT EXISTS (
+        SELECT 1
+        FROM users_projects o
+        WHERE o.project_id = up.project_id
+            AND o.role = 'owner'
+            AND o.deletedAt IS NULL
+    );
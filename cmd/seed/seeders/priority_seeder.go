@@ -12,27 +12,35 @@ func SeedPriorities(db *sql.DB) error {
 	priorities := []entities.Priority{
 		{
 			Name:        "Low",
+			Rank:        4,
+			Color:       "#6B7280",
 			Description: "low level",
 		},
 		{
 			Name:        "Medium",
+			Rank:        3,
+			Color:       "#3B82F6",
 			Description: "medium level",
 		},
 		{
 			Name:        "High",
+			Rank:        2,
+			Color:       "#F59E0B",
 			Description: "high level",
 		},
 		{
 			Name:        "Critical",
+			Rank:        1,
+			Color:       "#EF4444",
 			Description: "critical level",
 		},
 	}
 
 	for _, priority := range priorities {
 		_, err := db.Exec(`
-				INSERT INTO priorities (name, description, createdAt, updatedAt)
-				VALUES ($1, $2, $3, $4)
-			`, priority.Name, priority.Description, time.Now(), time.Now())
+				INSERT INTO priorities (name, description, rank, color, createdAt, updatedAt)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, priority.Name, priority.Description, priority.Rank, priority.Color, time.Now(), time.Now())
 
 		if err != nil {
 			log.Printf("Failed to insert priority %s: %v\n", priority.Name, err)
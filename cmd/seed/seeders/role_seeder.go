@@ -14,6 +14,7 @@ func SeedRoles(db *sql.DB) error {
 		{
 			Name:        "Admin",
 			Description: "administrator",
+			IsAdmin:     true,
 		},
 		{
 			Name:        "Employee",
@@ -27,9 +28,9 @@ func SeedRoles(db *sql.DB) error {
 
 	for _, role := range roles {
 		_, err := db.Exec(`
-				INSERT INTO roles (name, description, createdAt, updatedAt)
-				VALUES ($1, $2, $3, $4)
-			`, role.Name, role.Description, time.Now(), time.Now())
+				INSERT INTO roles (name, description, isAdmin, createdAt, updatedAt)
+				VALUES ($1, $2, $3, $4, $5)
+			`, role.Name, role.Description, role.IsAdmin, time.Now(), time.Now())
 
 		if err != nil {
 			log.Printf("Failed to insert role %s: %v\n", role.Name, err)
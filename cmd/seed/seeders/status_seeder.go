@@ -13,34 +13,40 @@ func SeedStatuses(db *sql.DB) error {
 		{
 			Name:        "Active",
 			Description: "The status is active and in progress.",
+			Category:    entities.StatusCategoryInProgress,
 		},
 		{
 			Name:        "Completed",
 			Description: "The task or project is completed.",
+			Category:    entities.StatusCategoryDone,
 		},
 		{
 			Name:        "Pending",
 			Description: "The task or project is pending and waiting to be started.",
+			Category:    entities.StatusCategoryTodo,
 		},
 		{
 			Name:        "Archived",
 			Description: "The task or project is archived and no longer active.",
+			Category:    entities.StatusCategoryDone,
 		},
 		{
 			Name:        "Not Started",
 			Description: "The task or project is not yet started.",
+			Category:    entities.StatusCategoryTodo,
 		},
 		{
 			Name:        "In Progress",
 			Description: "The task or project is currently in progress.",
+			Category:    entities.StatusCategoryInProgress,
 		},
 	}
 
 	for _, status := range statuses {
 		_, err := db.Exec(`
-			INSERT INTO statuses (name, description, createdAt, updatedAt)
-			VALUES ($1, $2, $3, $4)
-		`, status.Name, status.Description, time.Now(), time.Now())
+			INSERT INTO statuses (name, description, category, createdAt, updatedAt)
+			VALUES ($1, $2, $3, $4, $5)
+		`, status.Name, status.Description, status.Category, time.Now(), time.Now())
 
 		if err != nil {
 			log.Printf("Failed to insert status %s: %v\n", status.Name, err)
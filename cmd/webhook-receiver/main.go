@@ -0,0 +1,50 @@
+// Command webhook-receiver is a local endpoint for trying out webhooks. It
+// verifies and logs every delivery, and can be told to fail to exercise retries:
+//
+//	go run ./cmd/webhook-receiver -secret <webhook secret> -addr :9090 -fail-rate 0.5
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	secret := flag.String("secret", "", "webhook secret used to verify signatures")
+	failRate := flag.Float64("fail-rate", 0, "fraction of deliveries answered with 500")
+	flag.Parse()
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		event := r.Header.Get(webhooks.HeaderEvent)
+		delivery := r.Header.Get(webhooks.HeaderDelivery)
+		if *secret != "" && !webhooks.Verify(*secret, r.Header.Get(webhooks.HeaderTimestamp), body, r.Header.Get(webhooks.HeaderSignature)) {
+			log.Printf("delivery %s (%s): invalid signature", delivery, event)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		if rand.Float64() < *failRate {
+			log.Printf("delivery %s (%s): failing on purpose", delivery, event)
+			http.Error(w, "failing on purpose", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("delivery %s (%s): %s", delivery, event, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("Webhook receiver: listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -15,8 +15,34 @@ type Config struct {
 	DBName       string
 	JWTSecret    string
 	DATABASE_URL string
+
+	StoryPointScale  string
+	EstimateMaxHours int64
+
+	RecurrenceIntervalSeconds int64
+
+	MilestoneRiskDays int64
+
+	WebhookIntervalSeconds int64
+	WebhookTimeoutSeconds  int64
+	WebhookMaxAttempts     int64
+
+	OutboxSink            string
+	OutboxHTTPURL         string
+	OutboxIntervalSeconds int64
+	OutboxBatchSize       int64
 }
 
+// Defaults of the background jobs, which also replace settings that are not
+// positive
+const (
+	DefaultRecurrenceIntervalSeconds = 60
+	DefaultWebhookIntervalSeconds    = 10
+	DefaultWebhookTimeoutSeconds     = 10
+	DefaultOutboxIntervalSeconds     = 5
+	DefaultOutboxBatchSize           = 100
+)
+
 var Envs = initConfig()
 
 func initConfig() Config {
@@ -37,6 +63,22 @@ func initConfig() Config {
 		DBName:       getEnv("POSTGRES_DB", ""),
 		JWTSecret:    getEnv("JWT_SECRET", "IS-IT_REALL-A_SECRET-?~JWT-NOT_SO-SURE"),
 		DATABASE_URL: getEnv("DATABASE_PUBLIC_URL", ""),
+
+		StoryPointScale:  getEnv("STORY_POINT_SCALE", "1,2,3,5,8,13,21"),
+		EstimateMaxHours: getEnvAsInt("ESTIMATE_MAX_HOURS", 200),
+
+		RecurrenceIntervalSeconds: getEnvAsInt("RECURRENCE_INTERVAL_SECONDS", DefaultRecurrenceIntervalSeconds),
+
+		MilestoneRiskDays: getEnvAsInt("MILESTONE_RISK_DAYS", 7),
+
+		WebhookIntervalSeconds: getEnvAsInt("WEBHOOK_INTERVAL_SECONDS", DefaultWebhookIntervalSeconds),
+		WebhookTimeoutSeconds:  getEnvAsInt("WEBHOOK_TIMEOUT_SECONDS", DefaultWebhookTimeoutSeconds),
+		WebhookMaxAttempts:     getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+
+		OutboxSink:            getEnv("OUTBOX_SINK", "log"),
+		OutboxHTTPURL:         getEnv("OUTBOX_HTTP_URL", ""),
+		OutboxIntervalSeconds: getEnvAsInt("OUTBOX_INTERVAL_SECONDS", DefaultOutboxIntervalSeconds),
+		OutboxBatchSize:       getEnvAsInt("OUTBOX_BATCH_SIZE", DefaultOutboxBatchSize),
 	}
 }
 
@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+// Comment targets, also used as the {target} route segment
+const (
+	CommentTargetTask        = "tasks"
+	CommentTargetProjectTask = "project-tasks"
+)
+
+type CommentStore interface {
+	GetComments(string, int) ([]Comment, error)
+	GetComment(int) (*Comment, error)
+	CreateComment(CommentPayload) (*Comment, error)
+	UpdateComment(CommentPayload) error
+	DeleteComment(int) error
+}
+
+type Comment struct {
+	ID            int        `json:"id"`
+	TaskID        *int       `json:"taskId"`
+	ProjectTaskID *int       `json:"projectTaskId"`
+	UserID        int        `json:"userId"`
+	User          User       `json:"user"`
+	Body          string     `json:"body"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
+}
+
+// CommentPayload mentions users by writing @ followed by their email, e.g.
+// "@jane@example.com can you check this?"
+type CommentPayload struct {
+	ID       int    `json:"id"`
+	Target   string `json:"-"`
+	TargetID int    `json:"-"`
+	UserID   int    `json:"-"`
+	Body     string `json:"body" validate:"required,max=5000"`
+}
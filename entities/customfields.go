@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Targets that carry custom field values
+const (
+	CustomFieldTargetTask        = "tasks"
+	CustomFieldTargetProjectTask = "project-tasks"
+)
+
+// Custom field types
+const (
+	CustomFieldText        = "text"
+	CustomFieldNumber      = "number"
+	CustomFieldDate        = "date"
+	CustomFieldSelect      = "select"
+	CustomFieldMultiSelect = "multi_select"
+	CustomFieldUser        = "user"
+)
+
+type CustomFieldStore interface {
+	GetCustomFields(int) ([]CustomField, error)
+	GetCustomField(int) (*CustomField, error)
+	GetWorkspaceCustomFields(int) ([]CustomField, error)
+	CreateCustomField(CustomFieldPayload) (*CustomField, error)
+	UpdateCustomField(CustomFieldPayload) error
+	DeleteCustomField(int) error
+	ValidateValues([]CustomField, CustomFieldValues, bool) error
+}
+
+type CustomField struct {
+	ID        int        `json:"id"`
+	ProjectID int        `json:"projectId"`
+	Name      string     `json:"name"`
+	FieldType string     `json:"fieldType"`
+	Options   []string   `json:"options"`
+	Required  bool       `json:"required"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy *int       `json:"deletedBy,omitempty"`
+}
+
+type CustomFieldPayload struct {
+	ID        int      `json:"id"`
+	ProjectID int      `json:"projectId"`
+	Name      string   `json:"name" validate:"required,min=1,max=50"`
+	FieldType string   `json:"fieldType" validate:"required,oneof=text number date select multi_select user"`
+	Options   []string `json:"options"`
+	Required  *bool    `json:"required"`
+}
+
+// CustomFieldValue is a field value as returned on a task
+type CustomFieldValue struct {
+	FieldID   int             `json:"fieldId"`
+	Name      string          `json:"name"`
+	FieldType string          `json:"fieldType"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// CustomFieldValues maps a field id to its raw JSON value. A null value clears the field.
+type CustomFieldValues map[int]json.RawMessage
@@ -0,0 +1,43 @@
+package entities
+
+import "time"
+
+// Targets that keep a change history, also used as the {target} route segment
+const (
+	HistoryTargetTask        = "tasks"
+	HistoryTargetProjectTask = "project-tasks"
+)
+
+type HistoryStore interface {
+	GetHistory(string, int) (*TaskHistory, error)
+}
+
+// FieldChange is one changed field of a task update. Values are rendered as
+// text, nil meaning the field was empty.
+type FieldChange struct {
+	Field    string  `json:"field"`
+	OldValue *string `json:"oldValue"`
+	NewValue *string `json:"newValue"`
+}
+
+type TaskHistoryEntry struct {
+	ID        int       `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"oldValue"`
+	NewValue  *string   `json:"newValue"`
+	ChangedBy *int      `json:"changedBy"`
+	User      *User     `json:"user"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// TaskHistory lists the changes of a task in chronological order along with
+// the timestamps needed for lead and cycle time.
+type TaskHistory struct {
+	TaskID         int                `json:"taskId"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	StartedAt      *time.Time         `json:"startedAt"`
+	CompletedAt    *time.Time         `json:"completedAt"`
+	LeadTimeHours  *float64           `json:"leadTimeHours"`
+	CycleTimeHours *float64           `json:"cycleTimeHours"`
+	Entries        []TaskHistoryEntry `json:"entries"`
+}
@@ -0,0 +1,43 @@
+package entities
+
+import "time"
+
+// Label attachment targets, also used as the {target} route segment
+const (
+	LabelTargetProject     = "projects"
+	LabelTargetTask        = "tasks"
+	LabelTargetProjectTask = "project-tasks"
+)
+
+type LabelStore interface {
+	GetLabels(orgId int, projectId *int) ([]Label, error)
+	GetLabel(orgId, id int) (*Label, error)
+	CreateLabel(LabelPayload) (*Label, error)
+	UpdateLabel(LabelPayload) error
+	DeleteLabel(orgId, id int) error
+	AttachLabels(string, int, []int) error
+	DetachLabel(string, int, int) error
+}
+
+type Label struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Color     string     `json:"color"`
+	ProjectID *int       `json:"projectId"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy *int       `json:"deletedBy,omitempty"`
+}
+
+type LabelPayload struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name" validate:"required,min=1,max=50"`
+	Color     string `json:"color" validate:"omitempty,hexcolor"`
+	ProjectID *int   `json:"projectId"`
+	OrgID     int    `json:"-"`
+}
+
+type LabelAttachPayload struct {
+	LabelIDs []int `json:"labelIds" validate:"required,min=1"`
+}
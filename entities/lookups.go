@@ -0,0 +1,14 @@
+package entities
+
+// LookupStore is the store shared by the small per-organization tables such as
+// roles, statuses and priorities. Payloads carry the whole row; handlers fill
+// in what a partial update leaves out.
+type LookupStore[T any, P any] interface {
+	List(orgId int) ([]T, error)
+	Get(orgId, id int) (*T, error)
+	Create(orgId int, payload P) (*T, error)
+	Update(orgId, id int, payload P) error
+	// Delete moves references to replaceWith, when given, before deleting
+	Delete(orgId, id int, replaceWith *int) error
+	Restore(orgId, id int) error
+}
@@ -0,0 +1,62 @@
+package entities
+
+// Project roles from most to least privileged
+const (
+	ProjectRoleOwner      = "owner"
+	ProjectRoleMaintainer = "maintainer"
+	ProjectRoleMember     = "member"
+	ProjectRoleViewer     = "viewer"
+)
+
+// AdminRoleName names the admin role created for a new organization. Admin
+// rights come from the role's isAdmin flag, which no endpoint can change;
+// admins bypass project membership checks within their own organization.
+const AdminRoleName = "Admin"
+
+// Resources whose project can be looked up by ProjectAccess.ProjectOf. They
+// match the {target} route segments used by labels, watchers and comments.
+const (
+	AccessTargetProject     = "projects"
+	AccessTargetWorkspace   = "workspaces"
+	AccessTargetTask        = "tasks"
+	AccessTargetProjectTask = "project-tasks"
+	AccessTargetCustomField = "custom-fields"
+	AccessTargetSprint      = "sprints"
+	AccessTargetMilestone   = "milestones"
+	AccessTargetLabel       = "labels"
+	AccessTargetComment     = "comments"
+)
+
+type MemberStore interface {
+	ProjectAccess
+	GetMembers(int) ([]ProjectMember, error)
+	AddMember(int, MemberPayload) error
+	UpdateMemberRole(int, MemberPayload) error
+	RemoveMember(projectId, userId int, removedBy *int) error
+}
+
+// ProjectAccess answers who may do what in a project. RequireRole returns an
+// error unless the user is an admin or a member with at least minRole.
+// ProjectOf returns 0 for resources shared by all projects, like global labels.
+type ProjectAccess interface {
+	GetMemberRole(projectId, userId int) (string, error)
+	RequireRole(userId *int, projectId int, minRole string) error
+	RequireAdmin(userId *int) error
+	RequireWritable(projectId int) error
+	IsAdmin(userId int) (bool, error)
+	SharesProject(userId, otherUserId int) (bool, error)
+	ProjectOf(target string, id int) (int, error)
+}
+
+type ProjectMember struct {
+	UserID    int    `json:"userId"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+}
+
+type MemberPayload struct {
+	UserID int    `json:"userId" validate:"required"`
+	Role   string `json:"role" validate:"required,oneof=owner maintainer member viewer"`
+}
@@ -0,0 +1,48 @@
+package entities
+
+import "time"
+
+type MilestoneStore interface {
+	GetMilestones(int) ([]Milestone, error)
+	GetMilestone(int) (*Milestone, error)
+	CreateMilestone(MilestonePayload) (*Milestone, error)
+	UpdateMilestone(MilestonePayload) error
+	DeleteMilestone(int) error
+	AssignTasks(int, []int) error
+	UnassignTask(int, int) error
+}
+
+type Milestone struct {
+	ID             int            `json:"id"`
+	ProjectID      int            `json:"projectId"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	DueDate        time.Time      `json:"dueDate"`
+	Completed      bool           `json:"completed"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+	TotalTasks     int            `json:"totalTasks"`
+	CompletedTasks int            `json:"completedTasks"`
+	OpenTasks      int            `json:"openTasks"`
+	Progress       float64        `json:"progress"`
+	Overdue        bool           `json:"overdue"`
+	AtRisk         bool           `json:"atRisk"`
+	Tasks          []TasksProject `json:"tasks,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      *time.Time     `json:"deletedAt,omitempty"`
+	DeletedBy      *int           `json:"deletedBy,omitempty"`
+}
+
+type MilestonePayload struct {
+	ID          int    `json:"id"`
+	ProjectID   int    `json:"projectId"`
+	Name        string `json:"name" validate:"required,min=3,max=100"`
+	Description string `json:"description"`
+	DueDate     string `json:"dueDate"`
+	// Completed marks the milestone done or reopens it; nil leaves it as is
+	Completed *bool `json:"completed"`
+}
+
+type MilestoneTasksPayload struct {
+	TaskIDs []int `json:"taskIds" validate:"required,min=1"`
+}
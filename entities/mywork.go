@@ -0,0 +1,76 @@
+package entities
+
+import "time"
+
+// Kinds of assignments listed in a user's work
+const (
+	WorkItemTask        = "task"
+	WorkItemProjectTask = "projectTask"
+)
+
+// Due date groups of a user's work, listed in this order
+const (
+	DueOverdue  = "overdue"
+	DueToday    = "today"
+	DueThisWeek = "thisWeek"
+	DueLater    = "later"
+	DueNone     = "noDueDate"
+)
+
+type MyWorkStore interface {
+	GetWorkItems(WorkFilter) ([]WorkItem, error)
+}
+
+// WorkFilter selects the tasks assigned to UserID in an organization.
+// MemberID limits them to the projects the user is a member of and is nil for
+// admins; completed tasks are left out unless IncludeCompleted is set.
+type WorkFilter struct {
+	OrgID            int
+	UserID           int
+	MemberID         *int
+	IncludeCompleted bool
+}
+
+// WorkItem is a board task or a project task assigned to the user
+type WorkItem struct {
+	Type         string     `json:"type"`
+	ID           int        `json:"id"`
+	Title        string     `json:"title"`
+	ProjectID    int        `json:"projectId"`
+	StatusID     *int       `json:"statusId"`
+	StatusName   string     `json:"statusName"`
+	PriorityID   int        `json:"priorityId"`
+	PriorityName string     `json:"priorityName"`
+	PriorityRank int        `json:"priorityRank"`
+	DueDate      *time.Time `json:"dueDate"`
+	CompletedAt  *time.Time `json:"completedAt"`
+	ProjectName  string     `json:"-"`
+}
+
+// MyWork groups a user's assignments by project, then by due date
+type MyWork struct {
+	Total      int           `json:"total"`
+	Projects   []WorkProject `json:"projects"`
+	ByStatus   []WorkCount   `json:"byStatus"`
+	ByPriority []WorkCount   `json:"byPriority"`
+}
+
+type WorkProject struct {
+	ProjectID   int            `json:"projectId"`
+	ProjectName string         `json:"projectName"`
+	Total       int            `json:"total"`
+	Due         []WorkDueGroup `json:"due"`
+}
+
+type WorkDueGroup struct {
+	Due   string     `json:"due"`
+	Items []WorkItem `json:"items"`
+}
+
+// WorkCount counts assignments per status or priority; tasks without a
+// status are counted under a nil ID
+type WorkCount struct {
+	ID    *int   `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
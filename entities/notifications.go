@@ -0,0 +1,56 @@
+package entities
+
+import "time"
+
+// Watch targets, also used as the {target} route segment
+const (
+	WatchTargetProject     = "projects"
+	WatchTargetTask        = "tasks"
+	WatchTargetProjectTask = "project-tasks"
+)
+
+// Notification types
+const (
+	NotificationAssigned      = "assigned"
+	NotificationMoved         = "moved"
+	NotificationStatusChanged = "status_changed"
+	NotificationCommented     = "commented"
+	NotificationMentioned     = "mentioned"
+)
+
+type NotificationStore interface {
+	GetWatchers(string, int) ([]User, error)
+	Watch(string, int, int) error
+	Unwatch(string, int, int) error
+	GetNotifications(int, bool) ([]Notification, error)
+	GetUnreadCount(int) (int, error)
+	MarkRead(int, int) error
+	MarkAllRead(int) (int, error)
+}
+
+type Notification struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"userId"`
+	Type      string     `json:"type"`
+	ActorID   *int       `json:"actorId"`
+	Actor     *User      `json:"actor"`
+	Target    string     `json:"target"`
+	TargetID  int        `json:"targetId"`
+	ProjectID *int       `json:"projectId"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NotificationEvent is something that happened to a task. It is delivered to
+// the explicit recipients and, with NotifyWatchers, to the watchers of the task
+// and of its project. The actor is never notified of their own changes.
+type NotificationEvent struct {
+	Type           string
+	ActorID        *int
+	Target         string
+	TargetID       int
+	Recipients     []int
+	NotifyWatchers bool
+	Message        string
+}
@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// DefaultOrgID is the organization that held all data before tenants existed.
+// Its admins run the deployment and are the only ones who may add tenants.
+const DefaultOrgID = 1
+
+// Tables whose rows belong to an organization
+const (
+	TenantUsers      = "users"
+	TenantProjects   = "projects"
+	TenantSegments   = "segments"
+	TenantStatuses   = "statuses"
+	TenantPriorities = "priorities"
+	TenantRoles      = "roles"
+)
+
+type OrganizationStore interface {
+	GetOrganizations() ([]Organization, error)
+	GetOrganization(int) (*Organization, error)
+	CreateOrganization(OrganizationCreatePayload) (*Organization, error)
+	UpdateOrganization(OrganizationPayload) error
+}
+
+type Organization struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type OrganizationPayload struct {
+	ID   int    `json:"-"`
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
+
+// OrganizationCreatePayload creates a tenant together with its first admin
+type OrganizationCreatePayload struct {
+	Name  string                 `json:"name" validate:"required,min=2,max=100"`
+	Admin OrganizationAdminInput `json:"admin" validate:"required"`
+}
+
+type OrganizationAdminInput struct {
+	FirstName string `json:"firstName" validate:"required"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=8"`
+}
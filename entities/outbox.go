@@ -0,0 +1,31 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Aggregates that record domain events in the outbox
+const (
+	AggregateProject = "project"
+	AggregateTask    = "task"
+	AggregateUser    = "user"
+)
+
+// OutboxEvent is a domain event; EventType is "<aggregate>.<action>", e.g.
+// "project.created".
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	OrgID         int             `json:"orgId"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   int             `json:"aggregateId"`
+	EventType     string          `json:"eventType"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+// EventSink receives outbox events from the relay. An event may be published
+// more than once, so sinks and their consumers must tolerate duplicates.
+type EventSink interface {
+	Publish(OutboxEvent) error
+}
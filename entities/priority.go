@@ -4,27 +4,48 @@ import (
 	"time"
 )
 
-type PriorityStore interface {
-	GetPriorities() ([]Priority, error)
-	GetPriority(int) (*Priority, error)
-	CreatePriority(PriorityPayload) (*Priority, error)
-	UpdatePriority(PriorityPayload) error
-	DeletePriority(int) error
-	RestorePriority(int) error
+type PriorityStore = LookupStore[Priority, PriorityPayload]
+
+// PriorityRankStore orders the priorities of an organization
+type PriorityRankStore interface {
+	ReorderPriorities(orgId int, ids []int) error
+	MovePriority(orgId, id, rank int) error
 }
 
+// Priority is ranked from 1, the most urgent. The SLA targets are in hours
+// and optional.
 type Priority struct {
-	ID          int        `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
-	DeletedBy   *time.Time `json:"deletedBy,omitempty"`
+	ID                 int        `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	Rank               int        `json:"rank"`
+	Color              string     `json:"color"`
+	Icon               string     `json:"icon"`
+	ResponseSLAHours   *int       `json:"responseSlaHours"`
+	ResolutionSLAHours *int       `json:"resolutionSlaHours"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	DeletedAt          *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy          *time.Time `json:"deletedBy,omitempty"`
 }
 
 type PriorityPayload struct {
 	ID          int    `json:"id"`
 	Name        string `validate:"required,min=3,max=50"`
 	Description string `json:"description"`
+	Color       string `json:"color" validate:"omitempty,hexcolor"`
+	Icon        string `json:"icon" validate:"max=50"`
+	// The SLA targets keep their value when nil and are cleared by 0
+	ResponseSLAHours   *int `json:"responseSlaHours" validate:"omitempty,min=0"`
+	ResolutionSLAHours *int `json:"resolutionSlaHours" validate:"omitempty,min=0"`
+}
+
+// PriorityReorderPayload lists every priority of the organization from the
+// most to the least urgent
+type PriorityReorderPayload struct {
+	PriorityIDs []int `json:"priorityIds" validate:"required,min=1"`
+}
+
+type PriorityRankPayload struct {
+	Rank int `json:"rank" validate:"required,min=1"`
 }
@@ -3,32 +3,39 @@ package entities
 import "time"
 
 type ProjectStore interface {
-	GetProjects(string) ([]*Project, error)
+	GetProjects(string, ProjectFilter) ([]*Project, error)
 	GetProject(int) (*Project, error)
 	ProjectCreate(ProjectCreatePayload) (map[string]interface{}, error)
 	ProjectUpdate(int, ProjectUpdatePayload, []int) error
 	ProjectDelete(int) (*Project, error)
 	ProjectRestore(int) (*Project, error)
+	ProjectArchive(int, *int) (*Project, error)
+	ProjectUnarchive(int) (*Project, error)
 }
 
 type Project struct {
-	ID           int            `json:"id"`
-	Name         string         `json:"name"`
-	Description  string         `json:"description"`
-	Progress     *float64       `json:"progress"`
-	Url          *string        `json:"url"`
-	StatusID     int            `json:"statusId"`
-	Status       Status         `json:"status"`
-	SegmentID    int            `json:"segmentId"`
-	Segment      Segment        `json:"segment"`
-	DateStarted  *time.Time     `json:"dateStarted"`
-	DateDeadline *time.Time     `json:"dateDeadline"`
-	CreatedAt    time.Time      `json:"createdAt"`
-	UpdatedAt    time.Time      `json:"updatedAt"`
-	Users        []User         `json:"users"`
-	DeletedBy    *int           `json:"deletedBy,omitempty"`
-	DeletedAt    *time.Time     `json:"deletedAt,omitempty"`
-	Tasks        []TasksProject `json:"tasks"`
+	ID            int            `json:"id"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Progress      *float64       `json:"progress"`
+	Url           *string        `json:"url"`
+	StatusID      int            `json:"statusId"`
+	Status        Status         `json:"status"`
+	SegmentID     int            `json:"segmentId"`
+	Segment       Segment        `json:"segment"`
+	DateStarted   *time.Time     `json:"dateStarted"`
+	DateDeadline  *time.Time     `json:"dateDeadline"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	Users         []User         `json:"users"`
+	DeletedBy     *int           `json:"deletedBy,omitempty"`
+	DeletedAt     *time.Time     `json:"deletedAt,omitempty"`
+	ArchivedAt    *time.Time     `json:"archivedAt"`
+	ArchivedBy    *int           `json:"archivedBy,omitempty"`
+	Tasks         []TasksProject `json:"tasks"`
+	StoryPoints   int            `json:"storyPoints"`
+	EstimateHours float64        `json:"estimateHours"`
+	Labels        []Label        `json:"labels"`
 }
 
 type ProjectCreatePayload struct {
@@ -41,6 +48,8 @@ type ProjectCreatePayload struct {
 	DateStarted  string   `json:"dateStarted"`
 	DateDeadline string   `json:"dateDeadline"`
 	UserIDs      *[]int   `json:"userIds"`
+	CreatedBy    *int     `json:"-"`
+	OrgID        int      `json:"-"`
 }
 
 type ProjectUpdatePayload struct {
@@ -54,4 +63,17 @@ type ProjectUpdatePayload struct {
 	DateStarted  string   `json:"dateStarted"`
 	DateDeadline string   `json:"dateDeadline"`
 	UserIDs      *[]int   `json:"userIds"`
+	OrgID        int      `json:"-"`
+}
+
+// ProjectFilter narrows project listings to an organization; MemberID limits
+// them to the projects of that user and is nil for admins. Archived selects
+// only archived or only active projects, nil lists both.
+type ProjectFilter struct {
+	OrgID    int
+	LabelIDs []int
+	MemberID *int
+	Archived *bool
+	// SegmentID keeps projects of the segment or of any segment below it
+	SegmentID *int
 }
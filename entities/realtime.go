@@ -0,0 +1,28 @@
+package entities
+
+// Board event types pushed to the subscribers of a project
+const (
+	EventTaskCreated       = "task.created"
+	EventTaskUpdated       = "task.updated"
+	EventTaskMoved         = "task.moved"
+	EventTaskDeleted       = "task.deleted"
+	EventTaskRestored      = "task.restored"
+	EventWorkspaceCreated  = "workspace.created"
+	EventWorkspaceUpdated  = "workspace.updated"
+	EventWorkspaceDeleted  = "workspace.deleted"
+	EventWorkspaceRestored = "workspace.restored"
+)
+
+// EventBroker fans board events out to the subscribers of a project. The
+// default broker is in-process; a Postgres LISTEN/NOTIFY backed one can take
+// its place when the service runs on several instances.
+type EventBroker interface {
+	Publish(BoardEvent)
+	Subscribe(projectId int) (<-chan BoardEvent, func())
+}
+
+type BoardEvent struct {
+	Type      string      `json:"type"`
+	ProjectID int         `json:"projectId"`
+	Data      interface{} `json:"data"`
+}
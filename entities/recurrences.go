@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+// Targets that can recur, also used as the {target} route segment
+const (
+	RecurrenceTargetTask        = "tasks"
+	RecurrenceTargetProjectTask = "project-tasks"
+)
+
+type RecurrenceStore interface {
+	GetRecurrence(string, int) (*Recurrence, error)
+	SetRecurrence(string, int, string) (*Recurrence, error)
+	DeleteRecurrence(string, int) error
+	MaterializeDue(time.Time) (int, error)
+}
+
+// Recurrence points at the current occurrence of a recurring task. Rule is an
+// RRULE subset such as "FREQ=WEEKLY;INTERVAL=2;COUNT=10".
+type Recurrence struct {
+	ID            int        `json:"id"`
+	TaskID        *int       `json:"taskId"`
+	ProjectTaskID *int       `json:"projectTaskId"`
+	Rule          string     `json:"rule"`
+	Occurrences   int        `json:"occurrences"`
+	EndedAt       *time.Time `json:"endedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+type RecurrencePayload struct {
+	Rule string `json:"rule" validate:"required,max=255"`
+}
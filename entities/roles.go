@@ -4,19 +4,13 @@ import (
 	"time"
 )
 
-type RoleStore interface {
-	GetRoles() ([]Role, error)
-	GetRole(int) (*Role, error)
-	CreateRole(RolePayload) (*Role, error)
-	UpdateRole(RolePayload) error
-	DeleteRole(int) error
-	RestoreRole(int) error
-}
+type RoleStore = LookupStore[Role, RolePayload]
 
 type Role struct {
 	ID          int        `json:"id"`
 	Name        string     `json:"name"`
 	Description string     `json:"description"`
+	IsAdmin     bool       `json:"isAdmin"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
 	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
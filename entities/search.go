@@ -0,0 +1,40 @@
+package entities
+
+// Kinds of records a search can return
+const (
+	SearchTypeProject     = "project"
+	SearchTypeTask        = "task"
+	SearchTypeProjectTask = "projectTask"
+	SearchTypeUser        = "user"
+)
+
+var SearchTypes = []string{SearchTypeProject, SearchTypeTask, SearchTypeProjectTask, SearchTypeUser}
+
+type SearchStore interface {
+	Search(SearchFilter) ([]SearchResult, error)
+}
+
+// SearchFilter bounds a search to an organization; MemberID limits it to what
+// that user can see through their projects and is nil for admins. Types picks
+// the kinds of records to search, all of them when empty.
+type SearchFilter struct {
+	Query    string
+	OrgID    int
+	MemberID *int
+	Types    []string
+	Limit    int
+}
+
+// SearchResult is one match, best first. TitleHighlight and Snippet are
+// escaped HTML that wraps the matched words in <mark> tags; Snippet comes from
+// the description, or the email for users. Archived projects and their tasks are found too.
+type SearchResult struct {
+	Type           string  `json:"type"`
+	ID             int     `json:"id"`
+	ProjectID      *int    `json:"projectId,omitempty"`
+	Title          string  `json:"title"`
+	TitleHighlight string  `json:"titleHighlight"`
+	Snippet        string  `json:"snippet"`
+	Archived       bool    `json:"archived"`
+	Rank           float64 `json:"rank"`
+}
@@ -5,16 +5,20 @@ import (
 )
 
 type SegmentsStore interface {
-	GetSegments() ([]Segment, error)
-	GetSegment(int) (*Segment, error)
-	CreateSegment(SegmentPayload) (*Segment, error)
-	UpdateSegment(SegmentPayload) error
-	DeleteSegment(int) error
-	RestoreSegment(int) error
+	GetSegments(orgId int) ([]Segment, error)
+	GetSegment(orgId, id int) (*Segment, error)
+	CreateSegment(orgId int, payload SegmentPayload) (*Segment, error)
+	UpdateSegment(orgId int, payload SegmentPayload) error
+	DeleteSegment(orgId, id int) error
+	RestoreSegment(orgId, id int) error
+	GetSegmentTree(orgId int) ([]Segment, error)
+	GetSegmentSubtree(orgId, id int) (*Segment, error)
+	MoveSegment(orgId, id int, parentId *int) error
 }
 
 type Segment struct {
 	ID          int        `json:"id"`
+	ParentID    *int       `json:"parentId"`
 	Name        string     `json:"name"`
 	Description string     `json:"description"`
 	CreatedAt   time.Time  `json:"createdAt"`
@@ -22,11 +26,19 @@ type Segment struct {
 	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
 	DeletedBy   *time.Time `json:"deletedBy,omitempty"`
 	Projects    []Project  `json:"projects"`
+	Children    []Segment  `json:"children,omitempty"`
 }
 
 type SegmentPayload struct {
 	ID          int    `json:"id"`
 	Name        string `validate:"required,min=3,max=50"`
 	Description string `json:"description"`
+	ParentID    *int   `json:"parentId"`
 	ProjectIDs  *[]int `json:"projectIds"`
 }
+
+// SegmentMovePayload puts a segment under another one, or at the top of the
+// tree when ParentID is nil
+type SegmentMovePayload struct {
+	ParentID *int `json:"parentId"`
+}
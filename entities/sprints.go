@@ -0,0 +1,84 @@
+package entities
+
+import "time"
+
+const (
+	SprintPlanned = "planned"
+	SprintActive  = "active"
+	SprintClosed  = "closed"
+)
+
+type SprintStore interface {
+	GetSprints(int) ([]Sprint, error)
+	GetSprint(int) (*Sprint, error)
+	CreateSprint(SprintPayload) (*Sprint, error)
+	UpdateSprint(SprintPayload) error
+	DeleteSprint(int) error
+	StartSprint(int) error
+	CloseSprint(int, SprintClosePayload) (*SprintCloseResult, error)
+	AssignTasks(int, []int) error
+	UnassignTask(int, int) error
+	GetBurndown(int) ([]BurndownPoint, error)
+}
+
+type Sprint struct {
+	ID              int            `json:"id"`
+	ProjectID       int            `json:"projectId"`
+	Name            string         `json:"name"`
+	Goal            string         `json:"goal"`
+	DateStart       time.Time      `json:"dateStart"`
+	DateEnd         time.Time      `json:"dateEnd"`
+	State           string         `json:"state"`
+	ClosedAt        *time.Time     `json:"closedAt,omitempty"`
+	Tasks           []TasksProject `json:"tasks"`
+	StoryPoints     int            `json:"storyPoints"`
+	CompletedPoints int            `json:"completedPoints"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       *time.Time     `json:"deletedAt,omitempty"`
+	DeletedBy       *int           `json:"deletedBy,omitempty"`
+}
+
+type SprintPayload struct {
+	ID        int    `json:"id"`
+	ProjectID int    `json:"projectId"`
+	Name      string `json:"name" validate:"required,min=3,max=100"`
+	Goal      string `json:"goal"`
+	DateStart string `json:"dateStart"`
+	DateEnd   string `json:"dateEnd"`
+}
+
+type SprintClosePayload struct {
+	// CarryOverSprintID receives unfinished tasks; nil moves them back to the backlog.
+	CarryOverSprintID *int `json:"carryOverSprintId"`
+}
+
+type SprintCloseResult struct {
+	SprintID          int   `json:"sprintId"`
+	CompletedTaskIDs  []int `json:"completedTaskIds"`
+	CarriedOverIDs    []int `json:"carriedOverTaskIds"`
+	CarryOverSprintID *int  `json:"carryOverSprintId"`
+}
+
+type SprintTaskEvent struct {
+	ID          int       `json:"id"`
+	SprintID    int       `json:"sprintId"`
+	TaskID      int       `json:"taskId"`
+	Event       string    `json:"event"`
+	StoryPoints *int      `json:"storyPoints"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+type SprintTasksPayload struct {
+	TaskIDs []int `json:"taskIds" validate:"required,min=1"`
+}
+
+type BurndownPoint struct {
+	Date            time.Time `json:"date"`
+	ScopePoints     int       `json:"scopePoints"`
+	CompletedPoints int       `json:"completedPoints"`
+	RemainingPoints int       `json:"remainingPoints"`
+	IdealRemaining  float64   `json:"idealRemaining"`
+	ScopeTasks      int       `json:"scopeTasks"`
+	CompletedTasks  int       `json:"completedTasks"`
+}
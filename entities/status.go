@@ -2,19 +2,20 @@ package entities
 
 import "time"
 
-type StatusStore interface {
-	GetStatuses() ([]Status, error)
-	GetStatus(int) (*Status, error)
-	CreateStatus(StatusPayload) (*Status, error)
-	UpdateStatus(StatusPayload) error
-	DeleteStatus(int) error
-	RestoreStatus(int) error
-}
+// Status categories group statuses by how far along the work is
+const (
+	StatusCategoryTodo       = "todo"
+	StatusCategoryInProgress = "in_progress"
+	StatusCategoryDone       = "done"
+)
+
+type StatusStore = LookupStore[Status, StatusPayload]
 
 type Status struct {
 	ID          int        `json:"id"`
 	Name        string     `json:"name"`
 	Description string     `json:"description"`
+	Category    string     `json:"category"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
 	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
@@ -25,4 +26,5 @@ type StatusPayload struct {
 	ID          int    `json:"id"`
 	Name        string `validate:"required,min=3,max=50"`
 	Description string `json:"description"`
+	Category    string `json:"category" validate:"omitempty,oneof=todo in_progress done"`
 }
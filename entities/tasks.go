@@ -5,7 +5,7 @@ import (
 )
 
 type TaskStore interface {
-	GetTasks() ([]*Task, error)
+	GetTasks(TaskFilter) ([]*Task, error)
 	GetTask(int) (*Task, error)
 	TaskCreate(TaskCreatePayload) (*Task, error)
 	TaskUpdate(TaskUpdatePayload) error
@@ -15,35 +15,75 @@ type TaskStore interface {
 }
 
 type Task struct {
-	ID          int        `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	UserID      *int       `json:"userId"`
-	User        User       `json:"user"`
-	PriorityID  int        `json:"priorityId"`
-	Priority    Priority   `json:"priority"`
-	WorkspaceID int        `json:"workspaceId"`
-	Workspace   Workspace  `json:"workspace"`
-	TaskOrder   int        `json:"taskOrder"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt"`
-	DeletedBy   *int       `json:"deletedBy"`
+	ID            int                `json:"id"`
+	Title         string             `json:"title"`
+	Description   string             `json:"description"`
+	UserID        *int               `json:"userId"`
+	User          User               `json:"user"`
+	PriorityID    int                `json:"priorityId"`
+	Priority      Priority           `json:"priority"`
+	WorkspaceID   int                `json:"workspaceId"`
+	Workspace     Workspace          `json:"workspace"`
+	StatusID      *int               `json:"statusId"`
+	TaskOrder     int                `json:"taskOrder"`
+	StoryPoints   *int               `json:"storyPoints"`
+	EstimateHours *float64           `json:"estimateHours"`
+	Labels        []Label            `json:"labels"`
+	CustomFields  []CustomFieldValue `json:"customFields"`
+	DueDate       *time.Time         `json:"dueDate"`
+	CompletedAt   *time.Time         `json:"completedAt"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+	DeletedAt     *time.Time         `json:"deletedAt"`
+	DeletedBy     *int               `json:"deletedBy"`
 }
 
 type TaskCreatePayload struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	PriorityID  int    `json:"priorityId"`
-	WorkspaceID int    `json:"workspaceId"`
-	UserID      int    `json:"userId,omitempty"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	PriorityID    int               `json:"priorityId"`
+	WorkspaceID   int               `json:"workspaceId"`
+	UserID        int               `json:"userId,omitempty"`
+	StatusID      *int              `json:"statusId"`
+	StoryPoints   *int              `json:"storyPoints"`
+	EstimateHours *float64          `json:"estimateHours"`
+	CustomFields  CustomFieldValues `json:"customFields"`
+	DueDate       string            `json:"dueDate"`
+	CreatedBy     *int              `json:"-"`
 }
 
 type TaskUpdatePayload struct {
-	ID          int    `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	PriorityID  int    `json:"priorityId"`
-	WorkspaceID int    `json:"workspaceId"`
-	UserID      int    `json:"userId,omitempty"`
+	ID            int               `json:"id"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	PriorityID    int               `json:"priorityId"`
+	WorkspaceID   int               `json:"workspaceId"`
+	UserID        int               `json:"userId,omitempty"`
+	StatusID      *int              `json:"statusId"` // nil keeps the current status
+	StoryPoints   *int              `json:"storyPoints"`
+	EstimateHours *float64          `json:"estimateHours"`
+	ClearEstimate bool              `json:"clearEstimate"` // removes storyPoints and estimateHours
+	CustomFields  CustomFieldValues `json:"customFields"`
+	DueDate       string            `json:"dueDate"`
+	Completed     *bool             `json:"completed"`
+	UpdatedBy     *int              `json:"-"`
+}
+
+// TaskSortPriority lists tasks from the most urgent priority rank
+const TaskSortPriority = "priority"
+
+// TaskFilter narrows board task listings. ProjectID, AssigneeID, PriorityIDs,
+// StatusIDs and Completed are left unset by the list endpoints and come from
+// saved views.
+type TaskFilter struct {
+	OrgID        int
+	LabelIDs     []int
+	CustomFields map[int]string
+	Sort         string
+	MemberID     *int
+	ProjectID    *int
+	AssigneeID   *int
+	PriorityIDs  []int
+	StatusIDs    []int
+	Completed    *bool
 }
@@ -5,7 +5,7 @@ import (
 )
 
 type TasksProjectStore interface {
-	GetTasksProject(int) ([]*TasksProject, error)
+	GetTasksProject(int, TasksProjectFilter) ([]*TasksProject, error)
 	GetTaskProject(int) (*TasksProject, error)
 	TasksProjectCreate(TasksProjectCreatePayload) (*TasksProject, error)
 	TasksProjectUpdate(TasksProjectUpdatePayload) error
@@ -14,34 +14,67 @@ type TasksProjectStore interface {
 }
 
 type TasksProject struct {
-	ID          int        `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	UserID      *int       `json:"userId"`
-	User        User       `json:"user"`
-	PriorityID  int        `json:"priorityId"`
-	ProjectID   int        `json:"projectId"`
-	Project     Project    `json:"project"`
-	Priority    Priority   `json:"priority"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt"`
-	DeletedBy   *int       `json:"deletedBy"`
+	ID            int                `json:"id"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description"`
+	UserID        *int               `json:"userId"`
+	User          User               `json:"user"`
+	PriorityID    int                `json:"priorityId"`
+	ProjectID     int                `json:"projectId"`
+	Project       Project            `json:"project"`
+	Priority      Priority           `json:"priority"`
+	StoryPoints   *int               `json:"storyPoints"`
+	EstimateHours *float64           `json:"estimateHours"`
+	StatusID      *int               `json:"statusId"`
+	SprintID      *int               `json:"sprintId"`
+	MilestoneID   *int               `json:"milestoneId"`
+	CompletedAt   *time.Time         `json:"completedAt"`
+	Labels        []Label            `json:"labels"`
+	CustomFields  []CustomFieldValue `json:"customFields"`
+	DueDate       *time.Time         `json:"dueDate"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+	DeletedAt     *time.Time         `json:"deletedAt"`
+	DeletedBy     *int               `json:"deletedBy"`
 }
 
 type TasksProjectCreatePayload struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	PriorityID  int    `json:"priorityId"`
-	UserID      int    `json:"userId,omitempty"`
-	ProjectID   int    `json:"projectId"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	PriorityID    int               `json:"priorityId"`
+	UserID        int               `json:"userId,omitempty"`
+	ProjectID     int               `json:"projectId"`
+	StatusID      *int              `json:"statusId"`
+	StoryPoints   *int              `json:"storyPoints"`
+	EstimateHours *float64          `json:"estimateHours"`
+	CustomFields  CustomFieldValues `json:"customFields"`
+	DueDate       string            `json:"dueDate"`
+	CreatedBy     *int              `json:"-"`
 }
 
 type TasksProjectUpdatePayload struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	PriorityID  int    `json:"priorityId"`
-	UserID      int    `json:"userId,omitempty"`
-	ProjectID   int    `json:"projectId"`
+	ID            int               `json:"id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	PriorityID    int               `json:"priorityId"`
+	UserID        int               `json:"userId,omitempty"`
+	ProjectID     int               `json:"projectId"`
+	StatusID      *int              `json:"statusId"` // nil keeps the current status
+	StoryPoints   *int              `json:"storyPoints"`
+	EstimateHours *float64          `json:"estimateHours"`
+	ClearEstimate bool              `json:"clearEstimate"` // removes storyPoints and estimateHours
+	Completed     *bool             `json:"completed"`
+	CustomFields  CustomFieldValues `json:"customFields"`
+	DueDate       string            `json:"dueDate"`
+	UpdatedBy     *int              `json:"-"`
+}
+
+type TasksProjectFilter struct {
+	LabelIDs     []int
+	CustomFields map[int]string
+	Sort         string
+	AssigneeID   *int
+	PriorityIDs  []int
+	StatusIDs    []int
+	Completed    *bool
 }
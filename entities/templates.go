@@ -0,0 +1,88 @@
+package entities
+
+import "time"
+
+type TemplateStore interface {
+	GetTemplates(orgId int) ([]ProjectTemplate, error)
+	GetTemplate(orgId, id int) (*ProjectTemplate, error)
+	SaveTemplate(TemplateSavePayload) (*ProjectTemplate, error)
+	DeleteTemplate(orgId, id int) error
+	CreateFromTemplate(templateId int, payload ProjectFromTemplatePayload) (*Project, error)
+	CloneProject(projectId int, payload ProjectFromTemplatePayload) (*Project, error)
+}
+
+// ProjectTemplate is a snapshot of a project's structure that new projects
+// can be created from
+type ProjectTemplate struct {
+	ID              int                `json:"id"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	SourceProjectID *int               `json:"sourceProjectId"`
+	IncludesMembers bool               `json:"includesMembers"`
+	Definition      TemplateDefinition `json:"definition"`
+	CreatedBy       *int               `json:"createdBy"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
+	DeletedAt       *time.Time         `json:"deletedAt,omitempty"`
+}
+
+// TemplateDefinition holds what is copied into a new project. Labels keep the
+// id they had in the source project so tasks can refer to them; global labels
+// are reused while project labels are recreated.
+type TemplateDefinition struct {
+	StatusID        int                 `json:"statusId"`
+	Workspaces      []TemplateWorkspace `json:"workspaces"`
+	Labels          []TemplateLabel     `json:"labels"`
+	ProjectLabelIDs []int               `json:"projectLabelIds"`
+	Tasks           []TemplateTask      `json:"tasks"`
+	Members         []TemplateMember    `json:"members,omitempty"`
+}
+
+type TemplateWorkspace struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ColOrder    *int   `json:"colOrder"`
+}
+
+type TemplateLabel struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Color  string `json:"color"`
+	Global bool   `json:"global"`
+}
+
+type TemplateTask struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	PriorityID    int      `json:"priorityId"`
+	StoryPoints   *int     `json:"storyPoints"`
+	EstimateHours *float64 `json:"estimateHours"`
+	UserID        *int     `json:"userId,omitempty"`
+	LabelIDs      []int    `json:"labelIds"`
+}
+
+type TemplateMember struct {
+	UserID int    `json:"userId"`
+	Role   string `json:"role"`
+}
+
+type TemplateSavePayload struct {
+	Name           string `json:"name" validate:"required,min=3,max=100"`
+	Description    string `json:"description"`
+	IncludeMembers bool   `json:"includeMembers"`
+	ProjectID      int    `json:"-"`
+	OrgID          int    `json:"-"`
+	CreatedBy      *int   `json:"-"`
+}
+
+// ProjectFromTemplatePayload creates a project from a template or a clone.
+// StatusID defaults to the status of the source project.
+type ProjectFromTemplatePayload struct {
+	Name           string `json:"name" validate:"required,min=3,max=100"`
+	Description    string `json:"description"`
+	StatusID       int    `json:"statusId"`
+	SegmentID      *int   `json:"segmentId"`
+	IncludeMembers bool   `json:"includeMembers"`
+	OrgID          int    `json:"-"`
+	CreatedBy      *int   `json:"-"`
+}
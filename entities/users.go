@@ -4,13 +4,13 @@ import "time"
 
 type UserStore interface {
 	Login(UserLoginPayload) (string, User, error)
-	GetUsers() ([]*User, error)
-	GetUserById(id int) (*User, error)
+	GetUsers(orgId int, memberId *int) ([]*User, error)
+	GetUserById(orgId, id int) (*User, error)
 	GetUserByEmail(email string) (*User, error)
 	CreateUser(UserCreatePayload) error
-	UpdateUser(int, UserUpdatePayload, []int) error
-	DeleteUser(int) error
-	RestoreUser(int) error
+	UpdateUser(orgId, userId int, user UserUpdatePayload, projectIDs []int) error
+	DeleteUser(orgId, userId int) error
+	RestoreUser(orgId, userId int) error
 	SetUserActive(int) error
 	UpdateLastActiveTime(int, time.Time) error
 }
@@ -21,6 +21,7 @@ type User struct {
 	LastName     string     `json:"lastName"`
 	Age          int        `json:"age"`
 	Email        string     `json:"email"`
+	OrgID        int        `json:"orgId"`
 	RoleId       *int       `json:"roleId"`
 	Role         Role       `json:"role"`
 	Password     string     `json:"-"`
@@ -45,6 +46,7 @@ type UserCreatePayload struct {
 	RoleId     *int    `json:"roleId"`
 	ProjectIDS *[]int  `json:"projectIds"`
 	Password   string  `json:"-"`
+	OrgID      int     `json:"-"`
 }
 
 type UserUpdatePayload struct {
@@ -0,0 +1,60 @@
+package entities
+
+import "time"
+
+// Listings a saved view can run against
+const (
+	ViewEntityTasks        = "tasks"
+	ViewEntityProjectTasks = "projectTasks"
+	ViewEntityProjects     = "projects"
+)
+
+type ViewStore interface {
+	GetViews(orgId, userId int, memberId *int) ([]SavedView, error)
+	GetView(orgId, id, userId int, memberId *int) (*SavedView, error)
+	CreateView(orgId, userId int, payload ViewPayload) (*SavedView, error)
+	UpdateView(orgId, id, userId int, payload ViewPayload) (*SavedView, error)
+	DeleteView(orgId, id, userId int) error
+}
+
+// SavedView is a named listing query. Views belong to the user who saved them;
+// shared views can also be read and run by the members of ProjectID.
+// Project task views always run against ProjectID.
+type SavedView struct {
+	ID         int         `json:"id"`
+	UserID     int         `json:"userId"`
+	Name       string      `json:"name"`
+	EntityType string      `json:"entityType"`
+	ProjectID  *int        `json:"projectId"`
+	Shared     bool        `json:"shared"`
+	Filters    ViewFilters `json:"filters"`
+	Sort       string      `json:"sort"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+	DeletedAt  *time.Time  `json:"deletedAt,omitempty"`
+}
+
+// ViewFilters is the stored filter definition. AssignedToMe matches the user
+// running the view rather than the one who saved it. Archived and SegmentID
+// only apply to project views, the others only to task views, except LabelIDs.
+// Project views show active projects when Archived is not set.
+type ViewFilters struct {
+	LabelIDs     []int          `json:"labelIds,omitempty"`
+	CustomFields map[int]string `json:"customFields,omitempty"`
+	AssigneeID   *int           `json:"assigneeId,omitempty"`
+	AssignedToMe bool           `json:"assignedToMe,omitempty"`
+	PriorityIDs  []int          `json:"priorityIds,omitempty"`
+	StatusIDs    []int          `json:"statusIds,omitempty"`
+	Completed    *bool          `json:"completed,omitempty"`
+	Archived     *bool          `json:"archived,omitempty"`
+	SegmentID    *int           `json:"segmentId,omitempty"`
+}
+
+type ViewPayload struct {
+	Name       string      `json:"name" validate:"required,min=1,max=100"`
+	EntityType string      `json:"entityType" validate:"required,oneof=tasks projectTasks projects"`
+	ProjectID  *int        `json:"projectId"`
+	Shared     bool        `json:"shared"`
+	Filters    ViewFilters `json:"filters"`
+	Sort       string      `json:"sort" validate:"omitempty,oneof=priority"`
+}
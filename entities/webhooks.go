@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook events; a subscription to WebhookEventAll receives every event
+const (
+	WebhookEventAll                 = "*"
+	WebhookEventProjectCreated      = "project.created"
+	WebhookEventProjectUpdated      = "project.updated"
+	WebhookEventProjectDeleted      = "project.deleted"
+	WebhookEventProjectRestored     = "project.restored"
+	WebhookEventProjectArchived     = "project.archived"
+	WebhookEventProjectUnarchived   = "project.unarchived"
+	WebhookEventTaskCreated         = "task.created"
+	WebhookEventTaskUpdated         = "task.updated"
+	WebhookEventTaskDeleted         = "task.deleted"
+	WebhookEventTaskRestored        = "task.restored"
+	WebhookEventProjectTaskCreated  = "project_task.created"
+	WebhookEventProjectTaskUpdated  = "project_task.updated"
+	WebhookEventProjectTaskDeleted  = "project_task.deleted"
+	WebhookEventProjectTaskRestored = "project_task.restored"
+)
+
+var WebhookEvents = []string{
+	WebhookEventProjectCreated, WebhookEventProjectUpdated, WebhookEventProjectDeleted, WebhookEventProjectRestored,
+	WebhookEventProjectArchived, WebhookEventProjectUnarchived,
+	WebhookEventTaskCreated, WebhookEventTaskUpdated, WebhookEventTaskDeleted, WebhookEventTaskRestored,
+	WebhookEventProjectTaskCreated, WebhookEventProjectTaskUpdated, WebhookEventProjectTaskDeleted, WebhookEventProjectTaskRestored,
+}
+
+// Delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+type WebhookStore interface {
+	GetWebhooks(orgId int) ([]Webhook, error)
+	GetWebhook(orgId, id int) (*Webhook, error)
+	CreateWebhook(orgId int, payload WebhookPayload) (*Webhook, error)
+	UpdateWebhook(orgId int, payload WebhookPayload) error
+	DeleteWebhook(orgId, id int) error
+	GetDeliveries(orgId, webhookId int, status string) ([]WebhookDelivery, error)
+	Redeliver(orgId, deliveryId int) (*WebhookDelivery, error)
+	DeliverDue(time.Time) (int, error)
+}
+
+// Webhook is a subscription of a URL to the events of its organization. The
+// secret signs every delivery and is only returned when the webhook is created.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookPayload struct {
+	ID     int      `json:"-"`
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Secret string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhookId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode"`
+	LastError      *string         `json:"lastError"`
+	DeliveredAt    *time.Time      `json:"deliveredAt"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
@@ -0,0 +1,52 @@
+package entities
+
+import "time"
+
+// Records whose status changes can be governed by a workflow
+const (
+	WorkflowTargetProject     = "projects"
+	WorkflowTargetTask        = "tasks"
+	WorkflowTargetProjectTask = "project-tasks"
+)
+
+type WorkflowStore interface {
+	GetWorkflows(orgId int) ([]Workflow, error)
+	GetWorkflow(orgId, id int) (*Workflow, error)
+	CreateWorkflow(orgId int, payload WorkflowPayload) (*Workflow, error)
+	UpdateWorkflow(orgId int, payload WorkflowPayload) error
+	DeleteWorkflow(orgId, id int) error
+}
+
+// Workflow lists the status changes allowed for one kind of record. Moving
+// between statuses with no matching transition is rejected.
+type Workflow struct {
+	ID          int                  `json:"id"`
+	Name        string               `json:"name"`
+	Target      string               `json:"target"`
+	Transitions []WorkflowTransition `json:"transitions"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+	DeletedAt   *time.Time           `json:"deletedAt,omitempty"`
+}
+
+// WorkflowTransition allows moving to ToStatusID from FromStatusID, or from any
+// status when it is nil. RequiredFields must be filled in once the move is done.
+type WorkflowTransition struct {
+	ID             int      `json:"id"`
+	FromStatusID   *int     `json:"fromStatusId"`
+	ToStatusID     int      `json:"toStatusId"`
+	RequiredFields []string `json:"requiredFields"`
+}
+
+type WorkflowPayload struct {
+	ID          int                         `json:"id"`
+	Name        string                      `json:"name" validate:"required,min=3,max=100"`
+	Target      string                      `json:"target" validate:"required,oneof=projects tasks project-tasks"`
+	Transitions []WorkflowTransitionPayload `json:"transitions" validate:"required,min=1,dive"`
+}
+
+type WorkflowTransitionPayload struct {
+	FromStatusID   *int     `json:"fromStatusId"`
+	ToStatusID     int      `json:"toStatusId" validate:"required"`
+	RequiredFields []string `json:"requiredFields"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+type WorkloadStore interface {
+	GetWorkload(WorkloadFilter) ([]UserWorkload, error)
+	SetCapacity(orgId, userId int, payload CapacityPayload) error
+}
+
+// WorkloadFilter reports on the users of an organization for the days From
+// through To. MemberID limits the report to the users and projects that
+// member shares and is nil for admins; UserIDs picks users, all when empty.
+type WorkloadFilter struct {
+	OrgID    int
+	MemberID *int
+	UserIDs  []int
+	From     time.Time
+	To       time.Time
+	Today    time.Time
+}
+
+// UserWorkload sums the open board and project tasks assigned to a user in
+// active projects. OpenTasks, EstimateHours, StoryPoints and ActiveProjects
+// cover the tasks due in the range; OverdueTasks counts every open task due
+// before today and UnscheduledTasks those without a due date. Projects counts
+// the projects the user is a member of. CapacityHours is the weekly capacity
+// prorated to the range, and Utilization the estimate as a percentage of it;
+// both are nil without a weekly capacity.
+type UserWorkload struct {
+	UserID              int      `json:"userId"`
+	FirstName           string   `json:"firstName"`
+	LastName            string   `json:"lastName"`
+	Email               string   `json:"email"`
+	WeeklyCapacityHours *float64 `json:"weeklyCapacityHours"`
+	OpenTasks           int      `json:"openTasks"`
+	EstimateHours       float64  `json:"estimateHours"`
+	StoryPoints         int      `json:"storyPoints"`
+	OverdueTasks        int      `json:"overdueTasks"`
+	UnscheduledTasks    int      `json:"unscheduledTasks"`
+	ActiveProjects      int      `json:"activeProjects"`
+	Projects            int      `json:"projects"`
+	CapacityHours       *float64 `json:"capacityHours"`
+	Utilization         *float64 `json:"utilization"`
+}
+
+// CapacityPayload sets a user's weekly capacity; null clears it
+type CapacityPayload struct {
+	WeeklyCapacityHours *float64 `json:"weeklyCapacityHours" validate:"omitempty,min=0,max=168"`
+}
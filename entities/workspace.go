@@ -5,8 +5,8 @@ import (
 )
 
 type WorkspaceStore interface {
-	GetWorkspaces() ([]Workspace, error)
-	GetWorkspace(int) ([]Workspace, error)
+	GetWorkspaces(orgId int, memberId *int) ([]Workspace, error)
+	GetWorkspace(int, TaskFilter) ([]Workspace, error)
 	CreateWorkspace(WorkspacePayload) (*Workspace, error)
 	UpdateWorkspace(WorkspacePayload) error
 	DeleteWorkspace(int) error
@@ -15,17 +15,19 @@ type WorkspaceStore interface {
 }
 
 type Workspace struct {
-	ID          int        `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	ProjectID   int        `json:"projectId"`
-	Project     Project    `json:"project"`
-	ColOrder    int        `json:"colOrder"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	Tasks       []Task     `json:"tasks"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
-	DeletedBy   *time.Time `json:"deletedBy,omitempty"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	ProjectID     int        `json:"projectId"`
+	Project       Project    `json:"project"`
+	ColOrder      int        `json:"colOrder"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	Tasks         []Task     `json:"tasks"`
+	StoryPoints   int        `json:"storyPoints"`
+	EstimateHours float64    `json:"estimateHours"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	DeletedAt     *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy     *time.Time `json:"deletedBy,omitempty"`
 }
 
 type WorkspacePayload struct {
@@ -0,0 +1,15 @@
+package comments
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const targetPattern = "{target:tasks|project-tasks}"
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/comments/"+targetPattern+"/{taskId}", h.handleGetComments, "GET")
+	utils.SecureRoute(router, "/comments/"+targetPattern+"/{taskId}", h.handleCreateComment, "POST")
+	utils.SecureRoute(router, "/comments/{commentId}", h.handleUpdateComment, "PUT")
+	utils.SecureRoute(router, "/comments/{commentId}", h.handleDeleteComment, "DELETE")
+}
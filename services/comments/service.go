@@ -0,0 +1,150 @@
+package comments
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.CommentStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.CommentStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	comments, err := h.store.GetComments(vars["target"], taskId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": comments})
+}
+
+func (h *Handler) handleCreateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	payload := entities.CommentPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+	payload.Target = vars["target"]
+	payload.TargetID = taskId
+	payload.UserID = *userId
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleMember) {
+		return
+	}
+
+	comment, err := h.store.CreateComment(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": comment})
+}
+
+func (h *Handler) handleUpdateComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := h.getOwnComment(w, r)
+	if !ok {
+		return
+	}
+
+	payload := entities.CommentPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+	payload.ID = comment.ID
+	payload.UserID = comment.UserID
+
+	if err := h.store.UpdateComment(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Comment Successfully!"})
+}
+
+func (h *Handler) handleDeleteComment(w http.ResponseWriter, r *http.Request) {
+	comment, ok := h.getOwnComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteComment(comment.ID); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Comment Successfully!"})
+}
+
+// getOwnComment loads the comment of the route and checks that the current
+// user wrote it, writing the error response otherwise.
+func (h *Handler) getOwnComment(w http.ResponseWriter, r *http.Request) (*entities.Comment, bool) {
+	commentId, err := strconv.Atoi(mux.Vars(r)["commentId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid comment ID"))
+		return nil, false
+	}
+
+	comment, err := h.store.GetComment(commentId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+
+	userId := utils.GetUserID(r)
+	if userId == nil || *userId != comment.UserID {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only the author can change a comment"))
+		return nil, false
+	}
+	return comment, true
+}
@@ -0,0 +1,296 @@
+package comments
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/notifications"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// commentTarget describes the task table of a target and its comment column.
+// projectColumn is the project of a row of table aliased t.
+type commentTarget struct {
+	table         string
+	column        string
+	titleColumn   string
+	projectColumn string
+	watchTarget   string
+}
+
+var commentTargets = map[string]commentTarget{
+	entities.CommentTargetTask: {
+		table:         "tasks",
+		column:        "taskId",
+		titleColumn:   "title",
+		projectColumn: "(SELECT projectId FROM workspaces WHERE id = t.workspaceId)",
+		watchTarget:   entities.WatchTargetTask,
+	},
+	entities.CommentTargetProjectTask: {
+		table:         "project_tasks",
+		column:        "projectTaskId",
+		titleColumn:   "name",
+		projectColumn: "t.projectId",
+		watchTarget:   entities.WatchTargetProjectTask,
+	},
+}
+
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+const commentColumns = `
+	c.id, c.taskId, c.projectTaskId, c.userId, c.body, c.createdAt, c.updatedAt,
+	u.firstName, u.lastName, u.email
+`
+
+func scanComment(scanner interface{ Scan(...interface{}) error }, comment *entities.Comment) error {
+	err := scanner.Scan(
+		&comment.ID, &comment.TaskID, &comment.ProjectTaskID, &comment.UserID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt,
+		&comment.User.FirstName, &comment.User.LastName, &comment.User.Email,
+	)
+	comment.User.ID = comment.UserID
+	return err
+}
+
+func (s *Store) GetComments(target string, taskId int) ([]entities.Comment, error) {
+	t, ok := commentTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid comment target %s", target)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM comments c
+		JOIN users u ON u.id = c.userId
+		WHERE c.%s = $1 AND c.deletedAt IS NULL
+		ORDER BY c.createdAt
+	`, commentColumns, t.column), taskId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %v", err)
+	}
+	defer rows.Close()
+
+	comments := []entities.Comment{}
+	for rows.Next() {
+		comment := entities.Comment{}
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %v", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over comment rows: %v", err)
+	}
+	return comments, nil
+}
+
+func (s *Store) GetComment(id int) (*entities.Comment, error) {
+	comment := entities.Comment{}
+	row := s.db.QueryRow(fmt.Sprintf(`
+		SELECT %s
+		FROM comments c
+		JOIN users u ON u.id = c.userId
+		WHERE c.id = $1 AND c.deletedAt IS NULL
+	`, commentColumns), id)
+	if err := scanComment(row, &comment); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("comment with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func (s *Store) CreateComment(payload entities.CommentPayload) (*entities.Comment, error) {
+	t, ok := commentTargets[payload.Target]
+	if !ok {
+		return nil, fmt.Errorf("invalid comment target %s", payload.Target)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var title string
+	err = tx.QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND deletedAt IS NULL", t.titleColumn, t.table), payload.TargetID).Scan(&title)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("task with ID %d not found", payload.TargetID)
+		}
+		return nil, err
+	}
+
+	var id int
+	err = tx.QueryRow(fmt.Sprintf(`
+		INSERT INTO comments (%s, userId, body) VALUES ($1, $2, $3)
+		RETURNING id
+	`, t.column), payload.TargetID, payload.UserID, payload.Body).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert comment: %v", err)
+	}
+
+	// Commenters follow the conversation they joined
+	if err = notifications.Watch(tx, t.watchTarget, payload.TargetID, payload.UserID); err != nil {
+		return nil, err
+	}
+
+	err = notifications.Notify(tx, entities.NotificationEvent{
+		Type:           entities.NotificationCommented,
+		ActorID:        &payload.UserID,
+		Target:         t.watchTarget,
+		TargetID:       payload.TargetID,
+		NotifyWatchers: true,
+		Message:        fmt.Sprintf("New comment on %q", title),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = notifyMentions(tx, t, payload, title, nil); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetComment(id)
+}
+
+func (s *Store) UpdateComment(payload entities.CommentPayload) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var oldBody string
+	var taskId, projectTaskId *int
+	err = tx.QueryRow(`
+		SELECT body, taskId, projectTaskId FROM comments WHERE id = $1 AND deletedAt IS NULL FOR UPDATE
+	`, payload.ID).Scan(&oldBody, &taskId, &projectTaskId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("comment with ID %d not found", payload.ID)
+		}
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE comments SET body = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", payload.Body, payload.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %v", err)
+	}
+
+	// Only people mentioned by the edit are notified
+	payload.Target, payload.TargetID = entities.CommentTargetTask, 0
+	if taskId != nil {
+		payload.TargetID = *taskId
+	} else if projectTaskId != nil {
+		payload.Target, payload.TargetID = entities.CommentTargetProjectTask, *projectTaskId
+	}
+	t := commentTargets[payload.Target]
+
+	var title string
+	err = tx.QueryRow(fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", t.titleColumn, t.table), payload.TargetID).Scan(&title)
+	if err != nil {
+		return err
+	}
+	if err = notifyMentions(tx, t, payload, title, parseMentions(oldBody)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeleteComment(id int) error {
+	_, err := s.db.Exec("UPDATE comments SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %v", err)
+	}
+	return nil
+}
+
+// notifyMentions notifies users mentioned in the comment body, skipping the
+// emails in alreadyMentioned.
+func notifyMentions(tx *sql.Tx, t commentTarget, payload entities.CommentPayload, title string, alreadyMentioned []string) error {
+	skip := map[string]bool{}
+	for _, email := range alreadyMentioned {
+		skip[email] = true
+	}
+	emails := []string{}
+	for _, email := range parseMentions(payload.Body) {
+		if !skip[email] {
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	// Only members of the task's project, in its organization, can be mentioned
+	rows, err := tx.Query(`
+		SELECT u.id FROM users u
+		JOIN projects p ON p.orgId = u.orgId AND p.id = (SELECT `+t.projectColumn+` FROM `+t.table+` t WHERE t.id = $2)
+		WHERE LOWER(u.email) = ANY($1) AND u.deletedAt IS NULL AND `+members.MemberCondition("p.id", "u.id")+`
+	`, pq.Array(emails), payload.TargetID)
+	if err != nil {
+		return fmt.Errorf("failed to find mentioned users: %v", err)
+	}
+	userIds := []int{}
+	for rows.Next() {
+		var userId int
+		if err := rows.Scan(&userId); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan mentioned user: %v", err)
+		}
+		userIds = append(userIds, userId)
+	}
+	rows.Close()
+	if len(userIds) == 0 {
+		return nil
+	}
+
+	return notifications.Notify(tx, entities.NotificationEvent{
+		Type:       entities.NotificationMentioned,
+		ActorID:    &payload.UserID,
+		Target:     t.watchTarget,
+		TargetID:   payload.TargetID,
+		Recipients: userIds,
+		Message:    fmt.Sprintf("You were mentioned in a comment on %q", title),
+	})
+}
+
+// parseMentions returns the lower-cased emails mentioned as @email
+func parseMentions(body string) []string {
+	emails := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,36 @@
+package customfields
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const filterPrefix = "cf."
+
+// ParseFilter reads custom field filters given as cf.<fieldId>=<value> query
+// parameters.
+func ParseFilter(query url.Values) (map[int]string, error) {
+	filter := map[int]string{}
+	for key, values := range query {
+		if !strings.HasPrefix(key, filterPrefix) {
+			continue
+		}
+		fieldId, err := strconv.Atoi(strings.TrimPrefix(key, filterPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom field filter %s", key)
+		}
+		filter[fieldId] = values[0]
+	}
+	return filter, nil
+}
+
+// FilterCondition returns an SQL condition matching rows of the target whose
+// field equals the value, or contains it for multi_select fields. column is the
+// qualified id column of the target in the caller's query.
+func FilterCondition(target, column, fieldPlaceholder, valuePlaceholder string) string {
+	t := valueTargets[target]
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM %s cfv WHERE cfv.%s = %s AND cfv.fieldId = %s AND (cfv.value #>> '{}' = %s OR (jsonb_typeof(cfv.value) = 'array' AND cfv.value ? %s)))`,
+		t.table, t.column, column, fieldPlaceholder, valuePlaceholder, valuePlaceholder)
+}
@@ -0,0 +1,14 @@
+package customfields
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/projects/{projectId}/custom-fields", h.handleGetCustomFields, "GET")
+	utils.SecureRoute(router, "/projects/{projectId}/custom-fields", h.handleCreateCustomField, "POST")
+	utils.SecureRoute(router, "/custom-fields/{fieldId}", h.handleGetCustomField, "GET")
+	utils.SecureRoute(router, "/custom-fields/{fieldId}", h.handleUpdateCustomField, "PUT")
+	utils.SecureRoute(router, "/custom-fields/{fieldId}", h.handleDeleteCustomField, "DELETE")
+}
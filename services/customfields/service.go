@@ -0,0 +1,178 @@
+package customfields
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.CustomFieldStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.CustomFieldStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetCustomFields(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	fields, err := h.store.GetCustomFields(projectId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": fields})
+}
+
+func (h *Handler) handleGetCustomField(w http.ResponseWriter, r *http.Request) {
+	fieldId, err := strconv.Atoi(mux.Vars(r)["fieldId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid custom field ID"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetCustomField, fieldId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	field, err := h.store.GetCustomField(fieldId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": field})
+}
+
+func (h *Handler) handleCreateCustomField(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload := entities.CustomFieldPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if err := validateDefinition(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload.ProjectID = projectId
+
+	if !members.AuthorizeWrite(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	field, err := h.store.CreateCustomField(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": field})
+}
+
+func (h *Handler) handleUpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	fieldId, err := strconv.Atoi(mux.Vars(r)["fieldId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid custom field ID"))
+		return
+	}
+
+	payload := entities.CustomFieldPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetCustomField, fieldId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	field, err := h.store.GetCustomField(fieldId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if payload.FieldType != "" && payload.FieldType != field.FieldType {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the type of a custom field cannot be changed"))
+		return
+	}
+	if payload.Name == "" {
+		payload.Name = field.Name
+	}
+	if payload.Options == nil {
+		payload.Options = field.Options
+	}
+	if payload.Required == nil {
+		payload.Required = &field.Required
+	}
+	payload.ID = field.ID
+	payload.ProjectID = field.ProjectID
+	payload.FieldType = field.FieldType
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if err := validateDefinition(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.store.UpdateCustomField(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Custom Field Successfully!"})
+}
+
+func (h *Handler) handleDeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	fieldId, err := strconv.Atoi(mux.Vars(r)["fieldId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid custom field ID"))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetCustomField, fieldId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	if err := h.store.DeleteCustomField(fieldId); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Custom Field Successfully!"})
+}
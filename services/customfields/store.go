@@ -0,0 +1,248 @@
+package customfields
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// valueTarget describes the value table of a target that carries custom fields
+type valueTarget struct {
+	table  string
+	column string
+}
+
+var valueTargets = map[string]valueTarget{
+	entities.CustomFieldTargetTask: {
+		table:  "custom_field_values_tasks",
+		column: "taskId",
+	},
+	entities.CustomFieldTargetProjectTask: {
+		table:  "custom_field_values_project_tasks",
+		column: "projectTaskId",
+	},
+}
+
+const fieldColumns = "id, projectId, name, fieldType, options, required, createdAt, updatedAt"
+
+func scanField(scanner interface{ Scan(...interface{}) error }, field *entities.CustomField) error {
+	return scanner.Scan(
+		&field.ID, &field.ProjectID, &field.Name, &field.FieldType, pq.Array(&field.Options), &field.Required, &field.CreatedAt, &field.UpdatedAt,
+	)
+}
+
+func (s *Store) GetCustomFields(projectId int) ([]entities.CustomField, error) {
+	return s.queryFields(`
+		SELECT `+fieldColumns+`
+		FROM custom_fields
+		WHERE projectId = $1 AND deletedAt IS NULL
+		ORDER BY id
+	`, projectId)
+}
+
+// GetWorkspaceCustomFields returns the fields of the project owning the workspace,
+// which are the ones that apply to its board tasks.
+func (s *Store) GetWorkspaceCustomFields(workspaceId int) ([]entities.CustomField, error) {
+	return s.queryFields(`
+		SELECT cf.id, cf.projectId, cf.name, cf.fieldType, cf.options, cf.required, cf.createdAt, cf.updatedAt
+		FROM custom_fields cf
+		JOIN workspaces w ON w.projectId = cf.projectId
+		WHERE w.id = $1 AND cf.deletedAt IS NULL
+		ORDER BY cf.id
+	`, workspaceId)
+}
+
+func (s *Store) queryFields(query string, args ...interface{}) ([]entities.CustomField, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom fields: %v", err)
+	}
+	defer rows.Close()
+
+	fields := []entities.CustomField{}
+	for rows.Next() {
+		field := entities.CustomField{}
+		if err := scanField(rows, &field); err != nil {
+			return nil, fmt.Errorf("failed to scan custom field: %v", err)
+		}
+		fields = append(fields, field)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over custom field rows: %v", err)
+	}
+	return fields, nil
+}
+
+func (s *Store) GetCustomField(id int) (*entities.CustomField, error) {
+	field := entities.CustomField{}
+	row := s.db.QueryRow(`
+		SELECT `+fieldColumns+`
+		FROM custom_fields
+		WHERE id = $1 AND deletedAt IS NULL
+	`, id)
+	if err := scanField(row, &field); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("custom field with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve custom field: %v", err)
+	}
+	return &field, nil
+}
+
+func (s *Store) CreateCustomField(payload entities.CustomFieldPayload) (*entities.CustomField, error) {
+	required := payload.Required != nil && *payload.Required
+
+	field := entities.CustomField{}
+	row := s.db.QueryRow(`
+		INSERT INTO custom_fields (projectId, name, fieldType, options, required)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+fieldColumns,
+		payload.ProjectID, payload.Name, payload.FieldType, pq.Array(payload.Options), required,
+	)
+	if err := scanField(row, &field); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("custom field %s already exists", payload.Name)
+		}
+		return nil, fmt.Errorf("failed to insert custom field: %v", err)
+	}
+	return &field, nil
+}
+
+func (s *Store) UpdateCustomField(payload entities.CustomFieldPayload) error {
+	_, err := s.db.Exec(`
+		UPDATE custom_fields SET name = $1, options = $2, required = $3, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $4 AND deletedAt IS NULL
+	`, payload.Name, pq.Array(payload.Options), payload.Required != nil && *payload.Required, payload.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("custom field %s already exists", payload.Name)
+		}
+		return fmt.Errorf("failed to update custom field: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteCustomField(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE custom_fields SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	for _, target := range valueTargets {
+		_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE fieldId = $1", target.table), id)
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveValues writes custom field values of a task inside the caller's
+// transaction. Null values remove the stored value.
+func SaveValues(tx *sql.Tx, target string, targetId int, values entities.CustomFieldValues) error {
+	t, ok := valueTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid custom field target %s", target)
+	}
+
+	for fieldId, value := range values {
+		if isNull(value) {
+			_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE fieldId = $1 AND %s = $2", t.table, t.column), fieldId, targetId)
+			if err != nil {
+				return fmt.Errorf("failed to clear custom field %d: %v", fieldId, err)
+			}
+			continue
+		}
+
+		_, err := tx.Exec(fmt.Sprintf(`
+			INSERT INTO %s (fieldId, %s, value) VALUES ($1, $2, $3)
+			ON CONFLICT (fieldId, %s) DO UPDATE SET value = EXCLUDED.value
+		`, t.table, t.column, t.column), fieldId, targetId, string(value))
+		if err != nil {
+			return fmt.Errorf("failed to save custom field %d: %v", fieldId, err)
+		}
+	}
+	return nil
+}
+
+// GetValuesFor loads the custom field values of each of the given target ids.
+func GetValuesFor(db *sql.DB, target string, ids []int) (map[int][]entities.CustomFieldValue, error) {
+	valuesMap := make(map[int][]entities.CustomFieldValue)
+	t, ok := valueTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid custom field target %s", target)
+	}
+	if len(ids) == 0 {
+		return valuesMap, nil
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT v.%s, cf.id, cf.name, cf.fieldType, v.value
+		FROM %s v
+		JOIN custom_fields cf ON cf.id = v.fieldId AND cf.deletedAt IS NULL
+		WHERE v.%s = ANY($1)
+		ORDER BY cf.id
+	`, t.column, t.table, t.column), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom field values: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetId int
+		var raw []byte
+		value := entities.CustomFieldValue{}
+		if err := rows.Scan(&targetId, &value.FieldID, &value.Name, &value.FieldType, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan custom field value: %v", err)
+		}
+		value.Value = raw
+		valuesMap[targetId] = append(valuesMap[targetId], value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over custom field value rows: %v", err)
+	}
+	return valuesMap, nil
+}
+
+// CopyValues copies the custom field values of one task to another inside the
+// caller's transaction.
+func CopyValues(tx *sql.Tx, target string, fromId, toId int) error {
+	t, ok := valueTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid custom field target %s", target)
+	}
+
+	_, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO %s (fieldId, %s, value)
+		SELECT fieldId, $2, value FROM %s WHERE %s = $1
+		ON CONFLICT DO NOTHING
+	`, t.table, t.column, t.table, t.column), fromId, toId)
+	if err != nil {
+		return fmt.Errorf("failed to copy custom field values: %v", err)
+	}
+	return nil
+}
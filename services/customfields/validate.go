@@ -0,0 +1,166 @@
+package customfields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+)
+
+const dateLayout = "2006-01-02"
+
+// ValidateValues checks task values against the field definitions of the
+// project. On create every required field must be set, on update a required
+// field cannot be cleared.
+func (s *Store) ValidateValues(fields []entities.CustomField, values entities.CustomFieldValues, create bool) error {
+	fieldsMap := make(map[int]entities.CustomField, len(fields))
+	for _, field := range fields {
+		fieldsMap[field.ID] = field
+	}
+
+	for fieldId, value := range values {
+		field, ok := fieldsMap[fieldId]
+		if !ok {
+			return fmt.Errorf("custom field %d does not belong to this project", fieldId)
+		}
+		if isNull(value) {
+			if field.Required {
+				return fmt.Errorf("custom field %s is required", field.Name)
+			}
+			continue
+		}
+		if err := validateValue(field, value); err != nil {
+			return err
+		}
+		if field.FieldType == entities.CustomFieldUser {
+			if err := s.checkUser(field, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if create {
+		for _, field := range fields {
+			if _, ok := values[field.ID]; field.Required && !ok {
+				return fmt.Errorf("custom field %s is required", field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUser makes sure a user value is an active user of the organization
+// that owns the field's project
+func (s *Store) checkUser(field entities.CustomField, value json.RawMessage) error {
+	var userId int
+	json.Unmarshal(value, &userId)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := organizations.CheckForProject(tx, field.ProjectID, entities.TenantUsers, []int{userId}); err != nil {
+		return fmt.Errorf("custom field %s: %v", field.Name, err)
+	}
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deletedAt IS NULL)", userId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check user: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("custom field %s: user with ID %d not found", field.Name, userId)
+	}
+	return nil
+}
+
+func validateValue(field entities.CustomField, value json.RawMessage) error {
+	switch field.FieldType {
+	case entities.CustomFieldText:
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return fmt.Errorf("custom field %s must be a string", field.Name)
+		}
+	case entities.CustomFieldNumber:
+		var number float64
+		if err := json.Unmarshal(value, &number); err != nil {
+			return fmt.Errorf("custom field %s must be a number", field.Name)
+		}
+	case entities.CustomFieldDate:
+		var date string
+		if err := json.Unmarshal(value, &date); err != nil {
+			return fmt.Errorf("custom field %s must be a date string", field.Name)
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return fmt.Errorf("custom field %s must be a date in YYYY-MM-DD format", field.Name)
+		}
+	case entities.CustomFieldSelect:
+		var option string
+		if err := json.Unmarshal(value, &option); err != nil {
+			return fmt.Errorf("custom field %s must be a string", field.Name)
+		}
+		if !hasOption(field.Options, option) {
+			return fmt.Errorf("custom field %s does not allow %q", field.Name, option)
+		}
+	case entities.CustomFieldMultiSelect:
+		var options []string
+		if err := json.Unmarshal(value, &options); err != nil {
+			return fmt.Errorf("custom field %s must be a list of strings", field.Name)
+		}
+		for _, option := range options {
+			if !hasOption(field.Options, option) {
+				return fmt.Errorf("custom field %s does not allow %q", field.Name, option)
+			}
+		}
+	case entities.CustomFieldUser:
+		var userId int
+		if err := json.Unmarshal(value, &userId); err != nil || userId <= 0 {
+			return fmt.Errorf("custom field %s must be a user ID", field.Name)
+		}
+	}
+	return nil
+}
+
+// validateDefinition checks the options of a field against its type
+func validateDefinition(payload entities.CustomFieldPayload) error {
+	switch payload.FieldType {
+	case entities.CustomFieldSelect, entities.CustomFieldMultiSelect:
+		if len(payload.Options) == 0 {
+			return fmt.Errorf("%s fields need at least one option", payload.FieldType)
+		}
+		seen := map[string]bool{}
+		for _, option := range payload.Options {
+			if option == "" {
+				return fmt.Errorf("options cannot be empty")
+			}
+			if seen[option] {
+				return fmt.Errorf("duplicate option %q", option)
+			}
+			seen[option] = true
+		}
+	default:
+		if len(payload.Options) > 0 {
+			return fmt.Errorf("%s fields do not take options", payload.FieldType)
+		}
+	}
+	return nil
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isNull(value json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(value)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
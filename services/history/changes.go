@@ -0,0 +1,56 @@
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// Changes collects the fields that differ between the stored and updated task
+type Changes []entities.FieldChange
+
+// Add records field when the rendered old and new values differ
+func (c *Changes) Add(field string, oldValue, newValue interface{}) {
+	o, n := render(oldValue), render(newValue)
+	if o == nil && n == nil {
+		return
+	}
+	if o != nil && n != nil && *o == *n {
+		return
+	}
+	*c = append(*c, entities.FieldChange{Field: field, OldValue: o, NewValue: n})
+}
+
+func render(value interface{}) *string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case int:
+		s = strconv.Itoa(v)
+	case bool:
+		s = strconv.FormatBool(v)
+	case *int:
+		if v == nil {
+			return nil
+		}
+		s = strconv.Itoa(*v)
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		s = strconv.FormatFloat(*v, 'f', -1, 64)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		s = v.Format("2006-01-02")
+	default:
+		s = fmt.Sprint(v)
+	}
+	return &s
+}
@@ -0,0 +1,12 @@
+package history
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const targetPattern = "{target:tasks|project-tasks}"
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/history/"+targetPattern+"/{taskId}", h.handleGetHistory, "GET")
+}
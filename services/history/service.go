@@ -0,0 +1,42 @@
+package history
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.HistoryStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.HistoryStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	history, err := h.store.GetHistory(vars["target"], taskId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": history})
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// historyTarget describes the task table of a target and the field whose first
+// change marks the start of work on the task
+type historyTarget struct {
+	table      string
+	column     string
+	startField string
+}
+
+var historyTargets = map[string]historyTarget{
+	// Board tasks start when they first leave their initial column
+	entities.HistoryTargetTask: {
+		table:      "tasks",
+		column:     "taskId",
+		startField: "workspaceId",
+	},
+	// Project tasks start when they are first pulled into a sprint
+	entities.HistoryTargetProjectTask: {
+		table:      "project_tasks",
+		column:     "projectTaskId",
+		startField: "sprintId",
+	},
+}
+
+// Record stores the changes of a task update inside the caller's transaction.
+func Record(tx *sql.Tx, target string, taskId int, changedBy *int, changes Changes) error {
+	t, ok := historyTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid history target %s", target)
+	}
+
+	for _, change := range changes {
+		_, err := tx.Exec(fmt.Sprintf(`
+			INSERT INTO task_history (%s, field, oldValue, newValue, changedBy)
+			VALUES ($1, $2, $3, $4, $5)
+		`, t.column), taskId, change.Field, change.OldValue, change.NewValue, changedBy)
+		if err != nil {
+			return fmt.Errorf("failed to record %s change: %v", change.Field, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetHistory(target string, taskId int) (*entities.TaskHistory, error) {
+	t, ok := historyTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid history target %s", target)
+	}
+
+	history := entities.TaskHistory{TaskID: taskId, Entries: []entities.TaskHistoryEntry{}}
+	err := s.db.QueryRow(fmt.Sprintf("SELECT createdAt, completedAt FROM %s WHERE id = $1", t.table), taskId).Scan(&history.CreatedAt, &history.CompletedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with ID %d not found", taskId)
+		}
+		return nil, fmt.Errorf("failed to retrieve task: %v", err)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT h.id, h.field, h.oldValue, h.newValue, h.changedBy, h.changedAt,
+			u.firstName, u.lastName, u.email
+		FROM task_history h
+		LEFT JOIN users u ON u.id = h.changedBy
+		WHERE h.%s = $1
+		ORDER BY h.changedAt, h.id
+	`, t.column), taskId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := entities.TaskHistoryEntry{}
+		var firstName, lastName, email *string
+		err := rows.Scan(&entry.ID, &entry.Field, &entry.OldValue, &entry.NewValue, &entry.ChangedBy, &entry.ChangedAt, &firstName, &lastName, &email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task history: %v", err)
+		}
+		if entry.ChangedBy != nil && firstName != nil {
+			entry.User = &entities.User{ID: *entry.ChangedBy, FirstName: *firstName, LastName: *lastName, Email: *email}
+		}
+		if history.StartedAt == nil && entry.Field == t.startField {
+			startedAt := entry.ChangedAt
+			history.StartedAt = &startedAt
+		}
+		history.Entries = append(history.Entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over task history rows: %v", err)
+	}
+
+	if history.CompletedAt != nil {
+		history.LeadTimeHours = hoursBetween(history.CreatedAt, *history.CompletedAt)
+		if history.StartedAt != nil {
+			history.CycleTimeHours = hoursBetween(*history.StartedAt, *history.CompletedAt)
+		}
+	}
+	return &history, nil
+}
+
+func hoursBetween(from, to time.Time) *float64 {
+	hours := to.Sub(from).Hours()
+	return &hours
+}
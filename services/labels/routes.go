@@ -0,0 +1,18 @@
+package labels
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const targetPattern = "{target:projects|tasks|project-tasks}"
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/labels", h.handleGetLabels, "GET")
+	utils.SecureRoute(router, "/labels", h.handleCreateLabel, "POST")
+	utils.SecureRoute(router, "/labels/{labelId}", h.handleGetLabel, "GET")
+	utils.SecureRoute(router, "/labels/{labelId}", h.handleUpdateLabel, "PUT")
+	utils.SecureRoute(router, "/labels/{labelId}", h.handleDeleteLabel, "DELETE")
+	utils.SecureRoute(router, "/labels/"+targetPattern+"/{targetId}", h.handleAttachLabels, "POST")
+	utils.SecureRoute(router, "/labels/"+targetPattern+"/{targetId}/{labelId}", h.handleDetachLabel, "DELETE")
+}
@@ -0,0 +1,252 @@
+package labels
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.LabelStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.LabelStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetLabels(w http.ResponseWriter, r *http.Request) {
+	var projectId *int
+	if str := r.URL.Query().Get("projectId"); str != "" {
+		id, err := strconv.Atoi(str)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+			return
+		}
+		projectId = &id
+	}
+
+	if !h.authorizeScope(w, r, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	labels, err := h.store.GetLabels(utils.GetOrgID(r), projectId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": labels})
+}
+
+func (h *Handler) handleGetLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.Atoi(mux.Vars(r)["labelId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	if !h.authorizeLabel(w, r, labelId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	label, err := h.store.GetLabel(utils.GetOrgID(r), labelId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": label})
+}
+
+func (h *Handler) handleCreateLabel(w http.ResponseWriter, r *http.Request) {
+	payload := entities.LabelPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if payload.Color == "" {
+		payload.Color = "#6B7280"
+	}
+
+	if !h.authorizeScope(w, r, payload.ProjectID, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	payload.OrgID = utils.GetOrgID(r)
+	label, err := h.store.CreateLabel(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": label})
+}
+
+func (h *Handler) handleUpdateLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.Atoi(mux.Vars(r)["labelId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	payload := entities.LabelPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !h.authorizeLabel(w, r, labelId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	label, err := h.store.GetLabel(utils.GetOrgID(r), labelId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if payload.Name == "" {
+		payload.Name = label.Name
+	}
+	if payload.Color == "" {
+		payload.Color = label.Color
+	}
+	// The scope of a label is fixed once created
+	payload.ID = label.ID
+	payload.ProjectID = label.ProjectID
+	payload.OrgID = utils.GetOrgID(r)
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if err := h.store.UpdateLabel(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Label Successfully!"})
+}
+
+func (h *Handler) handleDeleteLabel(w http.ResponseWriter, r *http.Request) {
+	labelId, err := strconv.Atoi(mux.Vars(r)["labelId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	if !h.authorizeLabel(w, r, labelId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	if err := h.store.DeleteLabel(utils.GetOrgID(r), labelId); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Label Successfully!"})
+}
+
+func (h *Handler) handleAttachLabels(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetId, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s ID", vars["target"]))
+		return
+	}
+
+	payload := entities.LabelAttachPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, vars["target"], targetId, attachRole(vars["target"])) {
+		return
+	}
+
+	if err := h.store.AttachLabels(vars["target"], targetId, payload.LabelIDs); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Attach Labels Successfully!"})
+}
+
+func (h *Handler) handleDetachLabel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetId, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s ID", vars["target"]))
+		return
+	}
+
+	labelId, err := strconv.Atoi(vars["labelId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid label ID"))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, vars["target"], targetId, attachRole(vars["target"])) {
+		return
+	}
+
+	if err := h.store.DetachLabel(vars["target"], targetId, labelId); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Detach Label Successfully!"})
+}
+
+// authorizeScope checks minRole in the project of a label. Global labels are
+// readable by everyone but only admins may manage them.
+func (h *Handler) authorizeScope(w http.ResponseWriter, r *http.Request, projectId *int, minRole string) bool {
+	if projectId == nil || *projectId == 0 {
+		if minRole == entities.ProjectRoleViewer {
+			return true
+		}
+		return members.AuthorizeAdmin(w, r, h.access)
+	}
+	return members.Authorize(w, r, h.access, *projectId, minRole)
+}
+
+func (h *Handler) authorizeLabel(w http.ResponseWriter, r *http.Request, labelId int, minRole string) bool {
+	label, err := h.store.GetLabel(utils.GetOrgID(r), labelId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return false
+	}
+	return h.authorizeScope(w, r, label.ProjectID, minRole)
+}
+
+// attachRole is the role needed to label a target: labelling a project is a
+// project setting, labelling a task is everyday work.
+func attachRole(target string) string {
+	if target == entities.AccessTargetProject {
+		return entities.ProjectRoleMaintainer
+	}
+	return entities.ProjectRoleMember
+}
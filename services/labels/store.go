@@ -0,0 +1,292 @@
+package labels
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// labelTarget describes the join table and project lookup of an attachment target
+type labelTarget struct {
+	table        string
+	column       string
+	projectQuery string
+}
+
+var labelTargets = map[string]labelTarget{
+	entities.LabelTargetProject: {
+		table:        "labels_projects",
+		column:       "projectId",
+		projectQuery: "SELECT id FROM projects WHERE id = $1 AND deletedAt IS NULL",
+	},
+	entities.LabelTargetTask: {
+		table:        "labels_tasks",
+		column:       "taskId",
+		projectQuery: "SELECT w.projectId FROM tasks t JOIN workspaces w ON w.id = t.workspaceId WHERE t.id = $1 AND t.deletedAt IS NULL",
+	},
+	entities.LabelTargetProjectTask: {
+		table:        "labels_project_tasks",
+		column:       "projectTaskId",
+		projectQuery: "SELECT projectId FROM project_tasks WHERE id = $1 AND deletedAt IS NULL",
+	},
+}
+
+// GetLabels lists the labels of the organization, or the global labels and
+// those of one project when projectId is set
+func (s *Store) GetLabels(orgId int, projectId *int) ([]entities.Label, error) {
+	query := `
+		SELECT id, name, color, projectId, createdAt, updatedAt
+		FROM labels
+		WHERE deletedAt IS NULL AND orgId = $1
+	`
+	args := []interface{}{orgId}
+	if projectId != nil {
+		query += " AND (projectId IS NULL OR projectId = $2)"
+		args = append(args, *projectId)
+	}
+	query += " ORDER BY LOWER(name)"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %v", err)
+	}
+	defer rows.Close()
+
+	labels := []entities.Label{}
+	for rows.Next() {
+		label := entities.Label{}
+		if err := rows.Scan(&label.ID, &label.Name, &label.Color, &label.ProjectID, &label.CreatedAt, &label.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %v", err)
+		}
+		labels = append(labels, label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over label rows: %v", err)
+	}
+	return labels, nil
+}
+
+func (s *Store) GetLabel(orgId, id int) (*entities.Label, error) {
+	label := entities.Label{}
+	err := s.db.QueryRow(`
+		SELECT id, name, color, projectId, createdAt, updatedAt
+		FROM labels
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, id, orgId).Scan(&label.ID, &label.Name, &label.Color, &label.ProjectID, &label.CreatedAt, &label.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("label with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve label: %v", err)
+	}
+	return &label, nil
+}
+
+func (s *Store) CreateLabel(payload entities.LabelPayload) (*entities.Label, error) {
+	label := entities.Label{}
+	err := s.db.QueryRow(`
+		INSERT INTO labels (name, color, projectId, orgId)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, name, color, projectId, createdAt, updatedAt
+	`, payload.Name, payload.Color, payload.ProjectID, payload.OrgID).Scan(
+		&label.ID, &label.Name, &label.Color, &label.ProjectID, &label.CreatedAt, &label.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("label %s already exists", payload.Name)
+		}
+		return nil, fmt.Errorf("failed to insert label: %v", err)
+	}
+	return &label, nil
+}
+
+func (s *Store) UpdateLabel(payload entities.LabelPayload) error {
+	_, err := s.db.Exec(`
+		UPDATE labels SET name = $1, color = $2, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $3 AND orgId = $4 AND deletedAt IS NULL
+	`, payload.Name, payload.Color, payload.ID, payload.OrgID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return fmt.Errorf("label %s already exists", payload.Name)
+		}
+		return fmt.Errorf("failed to update label: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteLabel(orgId, id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("UPDATE labels SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 AND orgId = $2", id, orgId)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("label with ID %d not found", id)
+	}
+
+	// A deleted label no longer shows up on anything it was attached to
+	for _, target := range labelTargets {
+		_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE labelId = $1", target.table), id)
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) AttachLabels(target string, targetId int, labelIds []int) error {
+	t, ok := labelTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid label target %s", target)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var projectId int
+	err = tx.QueryRow(t.projectQuery, targetId).Scan(&projectId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("%s with ID %d not found", target, targetId)
+		}
+		return err
+	}
+
+	for _, labelId := range labelIds {
+		// Global labels are shared by the projects of their organization only
+		var labelProjectId *int
+		err = tx.QueryRow(`
+			SELECT l.projectId FROM labels l JOIN projects p ON p.orgId = l.orgId
+			WHERE l.id = $1 AND p.id = $2 AND l.deletedAt IS NULL
+		`, labelId, projectId).Scan(&labelProjectId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("label with ID %d not found", labelId)
+			}
+			return err
+		}
+		if labelProjectId != nil && *labelProjectId != projectId {
+			err = fmt.Errorf("label %d belongs to another project", labelId)
+			return err
+		}
+
+		_, err = tx.Exec(fmt.Sprintf(`
+			INSERT INTO %s (labelId, %s) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, t.table, t.column), labelId, targetId)
+		if err != nil {
+			return fmt.Errorf("failed to attach label %d: %v", labelId, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DetachLabel(target string, targetId, labelId int) error {
+	t, ok := labelTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid label target %s", target)
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE labelId = $1 AND %s = $2", t.table, t.column), labelId, targetId)
+	if err != nil {
+		return fmt.Errorf("failed to detach label: %v", err)
+	}
+	return nil
+}
+
+// GetLabelsFor loads the live labels attached to each of the given target ids.
+func GetLabelsFor(db *sql.DB, target string, ids []int) (map[int][]entities.Label, error) {
+	labelsMap := make(map[int][]entities.Label)
+	t, ok := labelTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid label target %s", target)
+	}
+	if len(ids) == 0 {
+		return labelsMap, nil
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT j.%s, l.id, l.name, l.color, l.projectId, l.createdAt, l.updatedAt
+		FROM %s j
+		JOIN labels l ON l.id = j.labelId AND l.deletedAt IS NULL
+		WHERE j.%s = ANY($1)
+		ORDER BY LOWER(l.name)
+	`, t.column, t.table, t.column), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetId int
+		label := entities.Label{}
+		if err := rows.Scan(&targetId, &label.ID, &label.Name, &label.Color, &label.ProjectID, &label.CreatedAt, &label.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %v", err)
+		}
+		labelsMap[targetId] = append(labelsMap[targetId], label)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over label rows: %v", err)
+	}
+	return labelsMap, nil
+}
+
+// LabelFilterCondition returns an SQL condition matching rows of the target
+// that carry any of the given labels. column is the qualified id column of the
+// target in the caller's query and placeholder the positional argument holding
+// the label ids.
+func LabelFilterCondition(target, column, placeholder string) string {
+	t := labelTargets[target]
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s lf WHERE lf.%s = %s AND lf.labelId = ANY(%s))", t.table, t.column, column, placeholder)
+}
+
+// CopyLabels attaches the labels of one target to another inside the caller's
+// transaction, e.g. when a recurring task is materialized.
+func CopyLabels(tx *sql.Tx, target string, fromId, toId int) error {
+	t, ok := labelTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid label target %s", target)
+	}
+
+	_, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO %s (labelId, %s)
+		SELECT labelId, $2 FROM %s WHERE %s = $1
+		ON CONFLICT DO NOTHING
+	`, t.table, t.column, t.table, t.column), fromId, toId)
+	if err != nil {
+		return fmt.Errorf("failed to copy labels: %v", err)
+	}
+	return nil
+}
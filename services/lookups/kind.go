@@ -0,0 +1,77 @@
+package lookups
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+)
+
+// Kind describes one lookup table, such as roles or statuses. T is a row and
+// P the payload that creates or updates it. Every lookup has an id, a name
+// unique within the organization, soft deletes and timestamps; Kind lists what
+// comes on top of that.
+type Kind[T any, P any] struct {
+	// Table is also the path the routes are registered under
+	Table string
+	// Label names a single row in messages and in the route variable, so
+	// "status" is read from {statusId}
+	Label string
+	// Select lists the columns read between id and createdAt, updatedAt
+	Select []string
+	// Columns lists the columns written from a payload, name first
+	Columns []string
+	// OnInsert sets columns from SQL rather than from the payload whenever a
+	// row joins the active ones, on create and restore. $1 is the organization.
+	OnInsert map[string]string
+	OrderBy  string
+	// References lists the columns pointing at rows of the table, which keep
+	// a row from being deleted while in use
+	References []Reference
+	// CheckDelete, when set, refuses to delete row id of the organization
+	// with an error wrapping ErrRefused. replaceWith is the row taking over
+	// its references, if any.
+	CheckDelete func(tx *sql.Tx, orgId, id int, replaceWith *int) error
+
+	// Fields returns where to scan id, Select, createdAt and updatedAt
+	Fields func(row *T) []interface{}
+	// Values returns the payload values for Columns
+	Values func(payload P) []interface{}
+	Name   func(payload P) string
+	// Merge completes a partial update payload with the current row
+	Merge func(row T, payload P) P
+}
+
+// Reference is a column holding ids of a lookup table
+type Reference struct {
+	// Name keys the usage counts reported when a row in use is deleted;
+	// references sharing a name are added up
+	Name   string
+	Table  string
+	Column string
+	// Live filters out referencing rows that no longer count, such as soft
+	// deleted ones. They are still moved to the replacement.
+	Live string
+}
+
+func (k Kind[T, P]) selectList() string {
+	return "id, " + strings.Join(k.Select, ", ") + ", createdAt, updatedAt"
+}
+
+func (k Kind[T, P]) title() string {
+	return strings.ToUpper(k.Label[:1]) + k.Label[1:]
+}
+
+// onInsert returns the OnInsert columns and expressions in a stable order
+func (k Kind[T, P]) onInsert() ([]string, []string) {
+	columns := make([]string, 0, len(k.OnInsert))
+	for column := range k.OnInsert {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	exprs := make([]string, 0, len(columns))
+	for _, column := range columns {
+		exprs = append(exprs, k.OnInsert[column])
+	}
+	return columns, exprs
+}
@@ -0,0 +1,20 @@
+package lookups
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes[T any, P any](router *mux.Router, h *Handler[T, P]) {
+	list := "/" + h.kind.Table
+	item := fmt.Sprintf("%s/{%sId:[0-9]+}", list, h.kind.Label)
+
+	utils.SecureRoute(router, list, h.handleList, "GET")
+	utils.SecureRoute(router, list, h.handleCreate, "POST")
+	utils.SecureRoute(router, item, h.handleGet, "GET")
+	utils.SecureRoute(router, item, h.handleUpdate, "PUT")
+	utils.SecureRoute(router, item+"/restore", h.handleRestore, "PUT")
+	utils.SecureRoute(router, item, h.handleDelete, "DELETE")
+}
@@ -0,0 +1,193 @@
+package lookups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// Handler lets every user read the lookups of their organization; only admins
+// change them.
+type Handler[T any, P any] struct {
+	store  entities.LookupStore[T, P]
+	kind   Kind[T, P]
+	access entities.ProjectAccess
+}
+
+func NewHandler[T any, P any](store entities.LookupStore[T, P], kind Kind[T, P], access entities.ProjectAccess) *Handler[T, P] {
+	return &Handler[T, P]{store: store, kind: kind, access: access}
+}
+
+func (h *Handler[T, P]) handleList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.List(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": list})
+}
+
+func (h *Handler[T, P]) handleGet(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	row, err := h.store.Get(utils.GetOrgID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": row})
+}
+
+func (h *Handler[T, P]) handleCreate(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	var payload P
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	row, err := h.store.Create(utils.GetOrgID(r), payload)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": row})
+}
+
+func (h *Handler[T, P]) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	var payload P
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	row, err := h.store.Get(utils.GetOrgID(r), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	payload = h.kind.Merge(*row, payload)
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if err := h.store.Update(utils.GetOrgID(r), id, payload); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": fmt.Sprintf("Update %s Successfully!", h.kind.title())})
+}
+
+func (h *Handler[T, P]) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	var replaceWith *int
+	if str := r.URL.Query().Get("replaceWith"); str != "" {
+		replacement, err := strconv.Atoi(str)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid replaceWith %s ID", h.kind.Label))
+			return
+		}
+		replaceWith = &replacement
+	}
+
+	err = h.store.Delete(utils.GetOrgID(r), id, replaceWith)
+	var usage *UsageError
+	if errors.As(err, &usage) {
+		utils.WriteJSON(w, http.StatusConflict, map[string]interface{}{"error": err.Error(), "usage": usage.Usage})
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": fmt.Sprintf("Delete %s Successfully!", h.kind.title())})
+}
+
+func (h *Handler[T, P]) handleRestore(w http.ResponseWriter, r *http.Request) {
+	id, err := h.getID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	if err := h.store.Restore(utils.GetOrgID(r), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": fmt.Sprintf("Restore %s Successfully!", h.kind.title())})
+}
+
+func (h *Handler[T, P]) getID(r *http.Request) (int, error) {
+	str, ok := mux.Vars(r)[h.kind.Label+"Id"]
+	if !ok {
+		return 0, fmt.Errorf("missing %s ID", h.kind.Label)
+	}
+
+	id, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s ID", h.kind.Label)
+	}
+	return id, nil
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.WriteError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrNameTaken), errors.Is(err, ErrReplaceConflict), errors.Is(err, ErrRefused):
+		utils.WriteError(w, http.StatusConflict, err)
+	case errors.Is(err, ErrInvalidReplacement):
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,344 @@
+package lookups
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrNameTaken          = errors.New("name is already taken")
+	ErrInUse              = errors.New("is still in use")
+	ErrInvalidReplacement = errors.New("invalid replacement")
+	ErrReplaceConflict    = errors.New("replacing would duplicate an existing reference")
+	ErrRefused            = errors.New("cannot be deleted")
+)
+
+// UsageError tells how often a row is still referenced, by reference name
+type UsageError struct {
+	Label string
+	ID    int
+	Usage map[string]int
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%s with ID %d %v, delete it with replaceWith to move its references", e.Label, e.ID, ErrInUse)
+}
+
+func (e *UsageError) Unwrap() error {
+	return ErrInUse
+}
+
+type Store[T any, P any] struct {
+	db   *sql.DB
+	kind Kind[T, P]
+}
+
+func NewStore[T any, P any](db *sql.DB, kind Kind[T, P]) *Store[T, P] {
+	return &Store[T, P]{db: db, kind: kind}
+}
+
+func (s *Store[T, P]) List(orgId int) ([]T, error) {
+	rows, err := s.db.Query(`
+		SELECT `+s.kind.selectList()+`
+		FROM `+s.kind.Table+`
+		WHERE deletedAt IS NULL AND orgId = $1
+		ORDER BY `+s.kind.OrderBy, orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %v", s.kind.Table, err)
+	}
+	defer rows.Close()
+
+	list := []T{}
+	for rows.Next() {
+		var row T
+		if err := rows.Scan(s.kind.Fields(&row)...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %v", s.kind.Label, err)
+		}
+		list = append(list, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over %s rows: %v", s.kind.Label, err)
+	}
+	return list, nil
+}
+
+func (s *Store[T, P]) Get(orgId, id int) (*T, error) {
+	var row T
+	err := s.db.QueryRow(`
+		SELECT `+s.kind.selectList()+`
+		FROM `+s.kind.Table+`
+		WHERE deletedAt IS NULL AND id = $1 AND orgId = $2
+	`, id, orgId).Scan(s.kind.Fields(&row)...)
+	if err == sql.ErrNoRows {
+		return nil, s.notFound(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s: %v", s.kind.Label, err)
+	}
+	return &row, nil
+}
+
+func (s *Store[T, P]) Create(orgId int, payload P) (*T, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.checkName(tx, orgId, 0, s.kind.Name(payload)); err != nil {
+		return nil, err
+	}
+
+	columns := append([]string{"orgId"}, s.kind.Columns...)
+	args := append([]interface{}{orgId}, s.kind.Values(payload)...)
+	values := make([]string, len(args))
+	for i := range args {
+		values[i] = fmt.Sprintf("$%d", i+1)
+	}
+	extraColumns, exprs := s.kind.onInsert()
+	columns = append(columns, extraColumns...)
+	values = append(values, exprs...)
+
+	var row T
+	err = tx.QueryRow(`
+		INSERT INTO `+s.kind.Table+` (`+strings.Join(columns, ", ")+`)
+		VALUES (`+strings.Join(values, ", ")+`)
+		RETURNING `+s.kind.selectList(), args...).Scan(s.kind.Fields(&row)...)
+	if isUniqueViolation(err) {
+		err = fmt.Errorf("%s %q: %w", s.kind.Label, s.kind.Name(payload), ErrNameTaken)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert %s: %v", s.kind.Label, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (s *Store[T, P]) Update(orgId, id int, payload P) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.checkName(tx, orgId, id, s.kind.Name(payload)); err != nil {
+		return err
+	}
+
+	args := append([]interface{}{id, orgId}, s.kind.Values(payload)...)
+	sets := make([]string, 0, len(s.kind.Columns)+1)
+	for i, column := range s.kind.Columns {
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, i+3))
+	}
+	sets = append(sets, "updatedAt = CURRENT_TIMESTAMP")
+
+	result, err := tx.Exec(`
+		UPDATE `+s.kind.Table+` SET `+strings.Join(sets, ", ")+`
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, args...)
+	if isUniqueViolation(err) {
+		err = fmt.Errorf("%s %q: %w", s.kind.Label, s.kind.Name(payload), ErrNameTaken)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %v", s.kind.Label, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		err = s.notFound(id)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete soft deletes a row that nothing references. With replaceWith, the
+// references are first moved to that row in the same transaction.
+func (s *Store[T, P]) Delete(orgId, id int, replaceWith *int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.QueryRow(`
+		SELECT id FROM `+s.kind.Table+` WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL FOR UPDATE
+	`, id, orgId).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = s.notFound(id)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s: %v", s.kind.Label, err)
+	}
+
+	if s.kind.CheckDelete != nil {
+		if err = s.kind.CheckDelete(tx, orgId, id, replaceWith); err != nil {
+			return err
+		}
+	}
+
+	if replaceWith != nil {
+		err = s.replace(tx, orgId, id, *replaceWith)
+	} else {
+		err = s.checkUnused(tx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE `+s.kind.Table+` SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", s.kind.Label, err)
+	}
+
+	return tx.Commit()
+}
+
+// Restore brings back a deleted row unless an active one took its name since
+func (s *Store[T, P]) Restore(orgId, id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var name string
+	err = tx.QueryRow(`
+		SELECT name FROM `+s.kind.Table+`
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NOT NULL
+		FOR UPDATE
+	`, id, orgId).Scan(&name)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("deleted %w", s.notFound(id))
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s: %v", s.kind.Label, err)
+	}
+
+	if err = s.checkName(tx, orgId, id, name); err != nil {
+		return err
+	}
+
+	sets := []string{"deletedAt = NULL", "updatedAt = CURRENT_TIMESTAMP"}
+	columns, exprs := s.kind.onInsert()
+	for i, column := range columns {
+		sets = append(sets, column+" = "+exprs[i])
+	}
+	_, err = tx.Exec(`
+		UPDATE `+s.kind.Table+` SET `+strings.Join(sets, ", ")+`
+		WHERE orgId = $1 AND id = $2
+	`, orgId, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %v", s.kind.Label, err)
+	}
+
+	return tx.Commit()
+}
+
+// checkName makes sure no other active row of the organization has the name,
+// ignoring case. The unique index backs it up against concurrent writes.
+func (s *Store[T, P]) checkName(tx *sql.Tx, orgId, id int, name string) error {
+	var taken bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM `+s.kind.Table+`
+			WHERE orgId = $1 AND LOWER(name) = LOWER($2) AND id <> $3 AND deletedAt IS NULL
+		)
+	`, orgId, name, id).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("failed to check %s name: %v", s.kind.Label, err)
+	}
+	if taken {
+		return fmt.Errorf("%s %q: %w", s.kind.Label, name, ErrNameTaken)
+	}
+	return nil
+}
+
+// checkUnused returns a UsageError while live rows still reference id
+func (s *Store[T, P]) checkUnused(tx *sql.Tx, id int) error {
+	usage := map[string]int{}
+	total := 0
+	for _, ref := range s.kind.References {
+		query := `SELECT COUNT(*) FROM ` + ref.Table + ` WHERE ` + ref.Column + ` = $1`
+		if ref.Live != "" {
+			query += ` AND ` + ref.Live
+		}
+
+		var count int
+		if err := tx.QueryRow(query, id).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count %s: %v", ref.Name, err)
+		}
+		usage[ref.Name] += count
+		total += count
+	}
+
+	if total > 0 {
+		return &UsageError{Label: s.kind.Label, ID: id, Usage: usage}
+	}
+	return nil
+}
+
+// replace points every reference to id at replaceWith instead
+func (s *Store[T, P]) replace(tx *sql.Tx, orgId, id, replaceWith int) error {
+	if replaceWith == id {
+		return fmt.Errorf("%w: a %s cannot replace itself", ErrInvalidReplacement, s.kind.Label)
+	}
+
+	var exists bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM `+s.kind.Table+` WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL)
+	`, replaceWith, orgId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s: %v", s.kind.Label, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s with ID %d not found", ErrInvalidReplacement, s.kind.Label, replaceWith)
+	}
+
+	for _, ref := range s.kind.References {
+		_, err := tx.Exec(`UPDATE `+ref.Table+` SET `+ref.Column+` = $1 WHERE `+ref.Column+` = $2`, replaceWith, id)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w in %s", ErrReplaceConflict, ref.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to replace %s in %s: %v", s.kind.Label, ref.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store[T, P]) notFound(id int) error {
+	return fmt.Errorf("%s with ID %d %w", s.kind.Label, id, ErrNotFound)
+}
+
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
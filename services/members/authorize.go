@@ -0,0 +1,97 @@
+package members
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// Authorize checks that the current user has at least minRole in the project.
+// Otherwise it writes a 403 and returns false, so handlers can simply return.
+func Authorize(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess, projectId int, minRole string) bool {
+	if err := access.RequireRole(utils.GetUserID(r), projectId, minRole); err != nil {
+		utils.WriteError(w, http.StatusForbidden, err)
+		return false
+	}
+	return true
+}
+
+// AuthorizeTarget is Authorize for a resource that belongs to a project, such
+// as a workspace or a task.
+func AuthorizeTarget(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess, target string, id int, minRole string) bool {
+	projectId, err := access.ProjectOf(target, id)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return false
+	}
+	return Authorize(w, r, access, projectId, minRole)
+}
+
+// AuthorizeWrite is Authorize for changes to the content of a project, which
+// archived projects refuse with a 409.
+func AuthorizeWrite(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess, projectId int, minRole string) bool {
+	if !Authorize(w, r, access, projectId, minRole) {
+		return false
+	}
+	if err := access.RequireWritable(projectId); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrProjectArchived) {
+			status = http.StatusConflict
+		}
+		utils.WriteError(w, status, err)
+		return false
+	}
+	return true
+}
+
+// AuthorizeWriteTarget is AuthorizeWrite for a resource that belongs to a project.
+func AuthorizeWriteTarget(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess, target string, id int, minRole string) bool {
+	projectId, err := access.ProjectOf(target, id)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return false
+	}
+	return AuthorizeWrite(w, r, access, projectId, minRole)
+}
+
+// AuthorizeAdmin lets only admins through and writes a 403 otherwise.
+func AuthorizeAdmin(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess) bool {
+	if err := access.RequireAdmin(utils.GetUserID(r)); err != nil {
+		utils.WriteError(w, http.StatusForbidden, err)
+		return false
+	}
+	return true
+}
+
+// Scope returns the user whose projects bound a listing, or nil for admins who
+// see everything. It writes a 401 and returns false without a current user.
+func Scope(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess) (*int, bool) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthenticated"))
+		return nil, false
+	}
+
+	admin, err := access.IsAdmin(*userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if admin {
+		return nil, true
+	}
+	return userId, true
+}
+
+// AuthorizeOperator lets through the admins of the default organization, who
+// run the deployment and manage what is shared by all tenants.
+func AuthorizeOperator(w http.ResponseWriter, r *http.Request, access entities.ProjectAccess) bool {
+	if utils.GetOrgID(r) != entities.DefaultOrgID {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only the operators of this deployment can do this"))
+		return false
+	}
+	return AuthorizeAdmin(w, r, access)
+}
@@ -0,0 +1,88 @@
+package members_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/comments"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/milestones"
+	"github.com/norrico31/it210-core-service-backend/services/sprints"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// archivedAccess makes every caller an owner of project 1, which is archived
+type archivedAccess struct{}
+
+func (archivedAccess) GetMemberRole(projectId, userId int) (string, error) {
+	return entities.ProjectRoleOwner, nil
+}
+
+func (archivedAccess) RequireRole(userId *int, projectId int, minRole string) error {
+	return nil
+}
+
+func (archivedAccess) RequireAdmin(userId *int) error {
+	return nil
+}
+
+func (archivedAccess) RequireWritable(projectId int) error {
+	return members.ErrProjectArchived
+}
+
+func (archivedAccess) IsAdmin(userId int) (bool, error) {
+	return false, nil
+}
+
+func (archivedAccess) SharesProject(userId, otherUserId int) (bool, error) {
+	return true, nil
+}
+
+func (archivedAccess) ProjectOf(target string, id int) (int, error) {
+	return 1, nil
+}
+
+// The handlers must refuse before they reach their stores, which are nil here
+func TestArchivedProjectRefusesWrites(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	token, err := utils.GenerateJWT(entities.User{ID: 7, OrgID: entities.DefaultOrgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	access := archivedAccess{}
+	router := mux.NewRouter()
+	sprints.RegisterRoutes(router, sprints.NewHandler(nil, access))
+	milestones.RegisterRoutes(router, milestones.NewHandler(nil, access))
+	customfields.RegisterRoutes(router, customfields.NewHandler(nil, access))
+	comments.RegisterRoutes(router, comments.NewHandler(nil, access))
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"POST", "/projects/1/sprints", `{"name":"Sprint 1"}`},
+		{"POST", "/projects/1/milestones", `{"name":"Launch"}`},
+		{"POST", "/projects/1/custom-fields", `{"name":"Team","fieldType":"text"}`},
+		{"PUT", "/custom-fields/1", `{"name":"Squad"}`},
+		{"DELETE", "/custom-fields/1", ``},
+		{"POST", "/comments/tasks/1", `{"body":"Looks good"}`},
+		{"POST", "/comments/project-tasks/1", `{"body":"Looks good"}`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		req.Header.Set("Authorization", utils.BEARER+token)
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusConflict {
+			t.Errorf("%s %s: got %d, want %d: %s", c.method, c.path, res.Code, http.StatusConflict, res.Body.String())
+		}
+	}
+}
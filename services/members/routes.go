@@ -0,0 +1,13 @@
+package members
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/projects/{projectId}/members", h.handleGetMembers, "GET")
+	utils.SecureRoute(router, "/projects/{projectId}/members", h.handleAddMember, "POST")
+	utils.SecureRoute(router, "/projects/{projectId}/members/{userId}", h.handleUpdateMember, "PUT")
+	utils.SecureRoute(router, "/projects/{projectId}/members/{userId}", h.handleRemoveMember, "DELETE")
+}
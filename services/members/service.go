@@ -0,0 +1,157 @@
+package members
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store entities.MemberStore
+}
+
+func NewHandler(store entities.MemberStore) *Handler {
+	return &Handler{store: store}
+}
+
+func (h *Handler) handleGetMembers(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	if !Authorize(w, r, h.store, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	members, err := h.store.GetMembers(projectId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": members})
+}
+
+func (h *Handler) handleAddMember(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload := entities.MemberPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !h.authorizeChange(w, r, projectId, payload.UserID, payload.Role) {
+		return
+	}
+
+	if err := h.store.AddMember(projectId, payload); err != nil {
+		if err == ErrAlreadyMember {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"msg": "Member Added Successfully!"})
+}
+
+func (h *Handler) handleUpdateMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userId, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	payload := entities.MemberPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload.UserID = userId
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !h.authorizeChange(w, r, projectId, payload.UserID, payload.Role) {
+		return
+	}
+
+	if err := h.store.UpdateMemberRole(projectId, payload); err != nil {
+		if err == ErrLastOwner {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Member Updated Successfully!"})
+}
+
+func (h *Handler) handleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+	userId, err := strconv.Atoi(vars["userId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	// Anyone may leave a project; removing others takes the rights to change them
+	currentUserId := utils.GetUserID(r)
+	if currentUserId == nil || *currentUserId != userId {
+		if !h.authorizeChange(w, r, projectId, userId, "") {
+			return
+		}
+	}
+
+	if err := h.store.RemoveMember(projectId, userId, currentUserId); err != nil {
+		if err == ErrLastOwner {
+			utils.WriteError(w, http.StatusConflict, err)
+			return
+		}
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Member Removed Successfully!"})
+}
+
+// authorizeChange lets maintainers manage members, while granting or taking
+// away the owner role is reserved to owners.
+func (h *Handler) authorizeChange(w http.ResponseWriter, r *http.Request, projectId, userId int, newRole string) bool {
+	minRole := entities.ProjectRoleMaintainer
+	if newRole == entities.ProjectRoleOwner {
+		minRole = entities.ProjectRoleOwner
+	} else if role, err := h.store.GetMemberRole(projectId, userId); err == nil && role == entities.ProjectRoleOwner {
+		minRole = entities.ProjectRoleOwner
+	}
+	return Authorize(w, r, h.store, projectId, minRole)
+}
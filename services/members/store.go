@@ -0,0 +1,367 @@
+package members
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// roleRank orders the roles; a higher rank includes every right of the lower ones
+var roleRank = map[string]int{
+	entities.ProjectRoleViewer:     1,
+	entities.ProjectRoleMember:     2,
+	entities.ProjectRoleMaintainer: 3,
+	entities.ProjectRoleOwner:      4,
+}
+
+// AtLeast reports whether role grants the rights of minRole
+func AtLeast(role, minRole string) bool {
+	return roleRank[role] >= roleRank[minRole]
+}
+
+var projectQueries = map[string]string{
+	entities.AccessTargetProject:     "SELECT id FROM projects WHERE id = $1",
+	entities.AccessTargetWorkspace:   "SELECT projectId FROM workspaces WHERE id = $1",
+	entities.AccessTargetTask:        "SELECT w.projectId FROM tasks t JOIN workspaces w ON w.id = t.workspaceId WHERE t.id = $1",
+	entities.AccessTargetProjectTask: "SELECT projectId FROM project_tasks WHERE id = $1",
+	entities.AccessTargetCustomField: "SELECT projectId FROM custom_fields WHERE id = $1",
+	entities.AccessTargetSprint:      "SELECT projectId FROM sprints WHERE id = $1",
+	entities.AccessTargetMilestone:   "SELECT projectId FROM milestones WHERE id = $1",
+	entities.AccessTargetLabel:       "SELECT COALESCE(projectId, 0) FROM labels WHERE id = $1",
+	entities.AccessTargetComment: `
+		SELECT COALESCE(pt.projectId, w.projectId)
+		FROM comments c
+		LEFT JOIN project_tasks pt ON pt.id = c.projectTaskId
+		LEFT JOIN tasks t ON t.id = c.taskId
+		LEFT JOIN workspaces w ON w.id = t.workspaceId
+		WHERE c.id = $1
+	`,
+}
+
+func (s *Store) GetMembers(projectId int) ([]entities.ProjectMember, error) {
+	rows, err := s.db.Query(`
+		SELECT u.id, u.firstName, u.lastName, u.email, up.role
+		FROM users_projects up
+		JOIN users u ON u.id = up.user_id AND u.deletedAt IS NULL
+		WHERE up.project_id = $1 AND up.deletedAt IS NULL
+		ORDER BY CASE up.role WHEN 'owner' THEN 1 WHEN 'maintainer' THEN 2 WHEN 'member' THEN 3 ELSE 4 END, u.firstName, u.id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members: %v", err)
+	}
+	defer rows.Close()
+
+	members := []entities.ProjectMember{}
+	for rows.Next() {
+		member := entities.ProjectMember{}
+		if err := rows.Scan(&member.UserID, &member.FirstName, &member.LastName, &member.Email, &member.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %v", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over member rows: %v", err)
+	}
+	return members, nil
+}
+
+func (s *Store) GetMemberRole(projectId, userId int) (string, error) {
+	var role string
+	err := s.db.QueryRow(`
+		SELECT role FROM users_projects WHERE project_id = $1 AND user_id = $2 AND deletedAt IS NULL
+	`, projectId, userId).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("user %d is not a member of project %d", userId, projectId)
+		}
+		return "", fmt.Errorf("failed to retrieve member role: %v", err)
+	}
+	return role, nil
+}
+
+func (s *Store) RequireRole(userId *int, projectId int, minRole string) error {
+	if userId == nil {
+		return fmt.Errorf("unauthenticated")
+	}
+	if admin, err := s.isAdminOf(*userId, projectId); err != nil || admin {
+		return err
+	}
+	role, err := s.GetMemberRole(projectId, *userId)
+	if err != nil {
+		return err
+	}
+	if !AtLeast(role, minRole) {
+		return fmt.Errorf("requires the %s role in project %d, you are %s", minRole, projectId, role)
+	}
+	return nil
+}
+
+func (s *Store) RequireAdmin(userId *int) error {
+	if userId == nil {
+		return fmt.Errorf("unauthenticated")
+	}
+	admin, err := s.IsAdmin(*userId)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return fmt.Errorf("requires an admin role")
+	}
+	return nil
+}
+
+func (s *Store) IsAdmin(userId int) (bool, error) {
+	var admin bool
+	err := s.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM users u JOIN roles r ON r.id = u.roleId AND r.orgId = u.orgId
+			WHERE u.id = $1 AND u.deletedAt IS NULL AND r.isAdmin AND r.deletedAt IS NULL
+		)
+	`, userId).Scan(&admin)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin role: %v", err)
+	}
+	return admin, nil
+}
+
+// isAdminOf reports whether the user is an admin of the organization that
+// owns the project; admins have no rights in other tenants.
+func (s *Store) isAdminOf(userId, projectId int) (bool, error) {
+	var admin bool
+	err := s.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM users u
+			JOIN roles r ON r.id = u.roleId AND r.orgId = u.orgId
+			JOIN projects p ON p.orgId = u.orgId
+			WHERE u.id = $1 AND p.id = $2 AND u.deletedAt IS NULL AND r.isAdmin AND r.deletedAt IS NULL
+		)
+	`, userId, projectId).Scan(&admin)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin role: %v", err)
+	}
+	return admin, nil
+}
+
+// ErrProjectArchived is returned for changes to the content of an archived project
+var ErrProjectArchived = errors.New("project is archived and read-only")
+
+// RequireWritable fails with ErrProjectArchived when the project is archived
+func (s *Store) RequireWritable(projectId int) error {
+	var archived bool
+	err := s.db.QueryRow("SELECT archivedAt IS NOT NULL FROM projects WHERE id = $1", projectId).Scan(&archived)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("project with ID %d not found", projectId)
+		}
+		return fmt.Errorf("failed to check project state: %v", err)
+	}
+	if archived {
+		return ErrProjectArchived
+	}
+	return nil
+}
+
+// SharesProject reports whether two users are members of a common project
+func (s *Store) SharesProject(userId, otherUserId int) (bool, error) {
+	var shares bool
+	err := s.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM users_projects a
+			JOIN users_projects b ON b.project_id = a.project_id AND b.deletedAt IS NULL
+			WHERE a.user_id = $1 AND b.user_id = $2 AND a.deletedAt IS NULL
+		)
+	`, userId, otherUserId).Scan(&shares)
+	if err != nil {
+		return false, fmt.Errorf("failed to check shared projects: %v", err)
+	}
+	return shares, nil
+}
+
+func (s *Store) ProjectOf(target string, id int) (int, error) {
+	query, ok := projectQueries[target]
+	if !ok {
+		return 0, fmt.Errorf("invalid access target %s", target)
+	}
+
+	var projectId int
+	if err := s.db.QueryRow(query, id).Scan(&projectId); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("%s with ID %d not found", target, id)
+		}
+		return 0, err
+	}
+	return projectId, nil
+}
+
+// ErrAlreadyMember is returned when adding a user who is already a member;
+// their role is changed with UpdateMemberRole, which protects the last owner
+var ErrAlreadyMember = errors.New("the user is already a member of the project, update their role instead")
+
+// AddMember adds a user to the project, or brings back a removed member
+func (s *Store) AddMember(projectId int, payload entities.MemberPayload) error {
+	// Only users of the organization that owns the project can join it
+	result, err := s.db.Exec(`
+		INSERT INTO users_projects (user_id, project_id, role)
+		SELECT u.id, p.id, $3 FROM users u JOIN projects p ON p.orgId = u.orgId
+		WHERE u.id = $1 AND p.id = $2 AND u.deletedAt IS NULL
+		ON CONFLICT (user_id, project_id) DO UPDATE SET role = EXCLUDED.role, deletedAt = NULL, deletedBy = NULL
+		WHERE users_projects.deletedAt IS NOT NULL
+	`, payload.UserID, projectId, payload.Role)
+	if err != nil {
+		return fmt.Errorf("failed to add member: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	var member bool
+	err = s.db.QueryRow(`SELECT `+MemberCondition("$1", "$2"), projectId, payload.UserID).Scan(&member)
+	if err != nil {
+		return fmt.Errorf("failed to check member: %v", err)
+	}
+	if member {
+		return ErrAlreadyMember
+	}
+	return fmt.Errorf("user %d not found in the organization of project %d", payload.UserID, projectId)
+}
+
+func (s *Store) UpdateMemberRole(projectId int, payload entities.MemberPayload) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if payload.Role != entities.ProjectRoleOwner {
+		if err = ensureAnotherOwner(tx, projectId, payload.UserID); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE users_projects SET role = $1 WHERE project_id = $2 AND user_id = $3 AND deletedAt IS NULL
+	`, payload.Role, projectId, payload.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update member: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		err = fmt.Errorf("user %d is not a member of project %d", payload.UserID, projectId)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveMember soft deletes the membership, so AddMember can bring it back
+func (s *Store) RemoveMember(projectId, userId int, removedBy *int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = ensureAnotherOwner(tx, projectId, userId); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		UPDATE users_projects SET deletedAt = CURRENT_TIMESTAMP, deletedBy = $3
+		WHERE project_id = $1 AND user_id = $2 AND deletedAt IS NULL
+	`, projectId, userId, removedBy)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		err = fmt.Errorf("user %d is not a member of project %d", userId, projectId)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ErrLastOwner is returned when a change would leave a project without an owner
+var ErrLastOwner = errors.New("a project needs at least one owner")
+
+// ensureAnotherOwner fails when userId is the only owner left in the project.
+// The owners are locked so two concurrent demotions cannot both pass.
+func ensureAnotherOwner(tx *sql.Tx, projectId, userId int) error {
+	rows, err := tx.Query(`
+		SELECT user_id FROM users_projects
+		WHERE project_id = $1 AND role = $2 AND deletedAt IS NULL
+		FOR UPDATE
+	`, projectId, entities.ProjectRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to query owners: %v", err)
+	}
+	defer rows.Close()
+
+	others, isOwner := 0, false
+	for rows.Next() {
+		var ownerId int
+		if err := rows.Scan(&ownerId); err != nil {
+			return fmt.Errorf("failed to scan owner: %v", err)
+		}
+		if ownerId == userId {
+			isOwner = true
+		} else {
+			others++
+		}
+	}
+	if isOwner && others == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// AddOwner makes userId an owner of the project inside the caller's
+// transaction, e.g. for the user who created it.
+func AddOwner(tx *sql.Tx, projectId, userId int) error {
+	_, err := tx.Exec(`
+		INSERT INTO users_projects (user_id, project_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, project_id) DO UPDATE SET role = EXCLUDED.role, deletedAt = NULL, deletedBy = NULL
+	`, userId, projectId, entities.ProjectRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to add project owner: %v", err)
+	}
+	return nil
+}
+
+// MemberCondition returns a SQL condition that holds when the user in the
+// userPh placeholder is a member of the project in projectColumn.
+func MemberCondition(projectColumn, userPh string) string {
+	return fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM users_projects mp WHERE mp.project_id = %s AND mp.user_id = %s AND mp.deletedAt IS NULL)",
+		projectColumn, userPh,
+	)
+}
+
+// OrgCondition is a SQL condition that the project in projectColumn belongs to
+// the organization bound to orgPh.
+func OrgCondition(projectColumn, orgPh string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM projects op WHERE op.id = %s AND op.orgId = %s)", projectColumn, orgPh)
+}
+
+// ActiveCondition is a SQL condition that the project in projectColumn is not
+// archived; organization-wide boards leave archived projects out.
+func ActiveCondition(projectColumn string) string {
+	return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM projects ap WHERE ap.id = %s AND ap.archivedAt IS NOT NULL)", projectColumn)
+}
@@ -0,0 +1,30 @@
+package milestones
+
+import (
+	"math"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// assess fills in the figures derived from a milestone's task counts. Progress
+// is the share of completed tasks. An open milestone is at risk when tasks are
+// still open and its due date is within the window, or already past.
+func assess(milestone *entities.Milestone, now time.Time, window time.Duration) {
+	milestone.Completed = milestone.CompletedAt != nil
+	milestone.OpenTasks = milestone.TotalTasks - milestone.CompletedTasks
+	milestone.Progress = 0
+	if milestone.TotalTasks > 0 {
+		milestone.Progress = math.Round(float64(milestone.CompletedTasks)/float64(milestone.TotalTasks)*10000) / 100
+	}
+
+	milestone.Overdue = false
+	milestone.AtRisk = false
+	if milestone.Completed {
+		return
+	}
+	// Due dates have no time of day, so a milestone is due by the end of that day
+	end := milestone.DueDate.AddDate(0, 0, 1)
+	milestone.Overdue = !now.Before(end)
+	milestone.AtRisk = milestone.OpenTasks > 0 && end.Sub(now) <= window
+}
@@ -0,0 +1,16 @@
+package milestones
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/projects/{projectId}/milestones", h.handleGetMilestones, "GET")
+	utils.SecureRoute(router, "/projects/{projectId}/milestones", h.handleCreateMilestone, "POST")
+	utils.SecureRoute(router, "/milestones/{milestoneId}", h.handleGetMilestone, "GET")
+	utils.SecureRoute(router, "/milestones/{milestoneId}", h.handleUpdateMilestone, "PUT")
+	utils.SecureRoute(router, "/milestones/{milestoneId}", h.handleDeleteMilestone, "DELETE")
+	utils.SecureRoute(router, "/milestones/{milestoneId}/tasks", h.handleAssignTasks, "POST")
+	utils.SecureRoute(router, "/milestones/{milestoneId}/tasks/{taskId}", h.handleUnassignTask, "DELETE")
+}
@@ -0,0 +1,226 @@
+package milestones
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.MilestoneStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.MilestoneStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetMilestones(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	milestones, err := h.store.GetMilestones(projectId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": milestones})
+}
+
+func (h *Handler) handleGetMilestone(w http.ResponseWriter, r *http.Request) {
+	milestoneId, err := getMilestoneID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetMilestone, milestoneId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	milestone, err := h.store.GetMilestone(milestoneId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": milestone})
+}
+
+func (h *Handler) handleCreateMilestone(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload := entities.MilestonePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+	payload.ProjectID = projectId
+
+	if !members.AuthorizeWrite(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	milestone, err := h.store.CreateMilestone(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": milestone})
+}
+
+func (h *Handler) handleUpdateMilestone(w http.ResponseWriter, r *http.Request) {
+	milestoneId, err := getMilestoneID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := entities.MilestonePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetMilestone, milestoneId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	milestone, err := h.store.GetMilestone(milestoneId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if payload.Name == "" {
+		payload.Name = milestone.Name
+	}
+	if payload.Description == "" {
+		payload.Description = milestone.Description
+	}
+	if payload.DueDate == "" {
+		payload.DueDate = milestone.DueDate.Format(utils.DateLayout)
+	}
+	payload.ID = milestone.ID
+	payload.ProjectID = milestone.ProjectID
+
+	if err := h.store.UpdateMilestone(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Milestone Successfully!"})
+}
+
+func (h *Handler) handleDeleteMilestone(w http.ResponseWriter, r *http.Request) {
+	milestoneId, err := getMilestoneID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetMilestone, milestoneId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	if err := h.store.DeleteMilestone(milestoneId); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Milestone Successfully!"})
+}
+
+func (h *Handler) handleAssignTasks(w http.ResponseWriter, r *http.Request) {
+	milestoneId, err := getMilestoneID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := entities.MilestoneTasksPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetMilestone, milestoneId, entities.ProjectRoleMember) {
+		return
+	}
+
+	if err := h.store.AssignTasks(milestoneId, payload.TaskIDs); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Assign Tasks Successfully!"})
+}
+
+func (h *Handler) handleUnassignTask(w http.ResponseWriter, r *http.Request) {
+	milestoneId, err := getMilestoneID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	taskId, err := strconv.Atoi(mux.Vars(r)["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetMilestone, milestoneId, entities.ProjectRoleMember) {
+		return
+	}
+
+	if err := h.store.UnassignTask(milestoneId, taskId); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Remove Task Successfully!"})
+}
+
+func getMilestoneID(r *http.Request) (int, error) {
+	str, ok := mux.Vars(r)["milestoneId"]
+	if !ok {
+		return 0, fmt.Errorf("missing milestone ID")
+	}
+
+	milestoneId, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid milestone ID")
+	}
+	return milestoneId, nil
+}
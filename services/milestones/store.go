@@ -0,0 +1,283 @@
+package milestones
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/history"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Store struct {
+	db         *sql.DB
+	riskWindow time.Duration
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		db:         db,
+		riskWindow: time.Duration(config.Envs.MilestoneRiskDays) * 24 * time.Hour,
+	}
+}
+
+func (s *Store) GetMilestones(projectId int) ([]entities.Milestone, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			m.id, m.projectId, m.name, COALESCE(m.description, ''), m.dueDate, m.completedAt, m.createdAt, m.updatedAt,
+			COUNT(pt.id) AS total_tasks,
+			COUNT(pt.id) FILTER (WHERE pt.completedAt IS NOT NULL) AS completed_tasks
+		FROM milestones m
+		LEFT JOIN project_tasks pt ON pt.milestoneId = m.id AND pt.deletedAt IS NULL
+		WHERE m.projectId = $1 AND m.deletedAt IS NULL
+		GROUP BY m.id
+		ORDER BY m.dueDate, m.id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query milestones: %v", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	milestones := []entities.Milestone{}
+	for rows.Next() {
+		milestone := entities.Milestone{}
+		err := rows.Scan(
+			&milestone.ID, &milestone.ProjectID, &milestone.Name, &milestone.Description, &milestone.DueDate, &milestone.CompletedAt,
+			&milestone.CreatedAt, &milestone.UpdatedAt, &milestone.TotalTasks, &milestone.CompletedTasks,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %v", err)
+		}
+		assess(&milestone, now, s.riskWindow)
+		milestones = append(milestones, milestone)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over milestone rows: %v", err)
+	}
+	return milestones, nil
+}
+
+func (s *Store) GetMilestone(id int) (*entities.Milestone, error) {
+	milestone := entities.Milestone{}
+	err := s.db.QueryRow(`
+		SELECT id, projectId, name, COALESCE(description, ''), dueDate, completedAt, createdAt, updatedAt
+		FROM milestones
+		WHERE id = $1 AND deletedAt IS NULL
+	`, id).Scan(
+		&milestone.ID, &milestone.ProjectID, &milestone.Name, &milestone.Description, &milestone.DueDate, &milestone.CompletedAt,
+		&milestone.CreatedAt, &milestone.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("milestone with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve milestone: %v", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, name, description, userId, priorityId, projectId, storyPoints, estimateHours, sprintId, milestoneId, completedAt, dueDate, createdAt, updatedAt
+		FROM project_tasks
+		WHERE milestoneId = $1 AND deletedAt IS NULL
+		ORDER BY createdAt
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query milestone tasks: %v", err)
+	}
+	defer rows.Close()
+
+	milestone.Tasks = []entities.TasksProject{}
+	for rows.Next() {
+		task := entities.TasksProject{}
+		err := rows.Scan(
+			&task.ID, &task.Name, &task.Description, &task.UserID, &task.PriorityID, &task.ProjectID, &task.StoryPoints,
+			&task.EstimateHours, &task.SprintID, &task.MilestoneID, &task.CompletedAt, &task.DueDate, &task.CreatedAt, &task.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone task: %v", err)
+		}
+		milestone.TotalTasks++
+		if task.CompletedAt != nil {
+			milestone.CompletedTasks++
+		}
+		milestone.Tasks = append(milestone.Tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over milestone task rows: %v", err)
+	}
+
+	assess(&milestone, time.Now(), s.riskWindow)
+	return &milestone, nil
+}
+
+func (s *Store) CreateMilestone(payload entities.MilestonePayload) (*entities.Milestone, error) {
+	dueDate, err := parseDueDate(payload.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	milestone := entities.Milestone{}
+	err = s.db.QueryRow(`
+		INSERT INTO milestones (projectId, name, description, dueDate, completedAt)
+		VALUES ($1, $2, $3, $4, CASE WHEN $5 THEN CURRENT_TIMESTAMP END)
+		RETURNING id, projectId, name, COALESCE(description, ''), dueDate, completedAt, createdAt, updatedAt
+	`, payload.ProjectID, payload.Name, payload.Description, dueDate, payload.Completed != nil && *payload.Completed).Scan(
+		&milestone.ID, &milestone.ProjectID, &milestone.Name, &milestone.Description, &milestone.DueDate, &milestone.CompletedAt,
+		&milestone.CreatedAt, &milestone.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert milestone: %v", err)
+	}
+
+	milestone.Tasks = []entities.TasksProject{}
+	assess(&milestone, time.Now(), s.riskWindow)
+	return &milestone, nil
+}
+
+func (s *Store) UpdateMilestone(payload entities.MilestonePayload) error {
+	dueDate, err := parseDueDate(payload.DueDate)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE milestones
+		SET name = $1, description = $2, dueDate = $3,
+			completedAt = CASE WHEN $4::BOOLEAN IS NULL THEN completedAt WHEN $4 THEN COALESCE(completedAt, CURRENT_TIMESTAMP) ELSE NULL END,
+			updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $5 AND deletedAt IS NULL
+	`, payload.Name, payload.Description, dueDate, payload.Completed, payload.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update milestone: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteMilestone(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE milestones SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	// Tasks of a deleted milestone are simply no longer part of one
+	_, err = tx.Exec("UPDATE project_tasks SET milestoneId = NULL WHERE milestoneId = $1", id)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) AssignTasks(milestoneId int, taskIds []int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var projectId int
+	err = tx.QueryRow("SELECT projectId FROM milestones WHERE id = $1 AND deletedAt IS NULL", milestoneId).Scan(&projectId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("milestone with ID %d not found", milestoneId)
+		}
+		return err
+	}
+
+	for _, taskId := range taskIds {
+		var taskProjectId int
+		var currentMilestoneId *int
+		err = tx.QueryRow(`
+			SELECT projectId, milestoneId FROM project_tasks WHERE id = $1 AND deletedAt IS NULL FOR UPDATE
+		`, taskId).Scan(&taskProjectId, &currentMilestoneId)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("task with ID %d not found", taskId)
+			}
+			return err
+		}
+		if taskProjectId != projectId {
+			err = fmt.Errorf("task %d does not belong to the milestone's project", taskId)
+			return err
+		}
+		if currentMilestoneId != nil && *currentMilestoneId == milestoneId {
+			continue
+		}
+
+		_, err = tx.Exec("UPDATE project_tasks SET milestoneId = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", milestoneId, taskId)
+		if err != nil {
+			return fmt.Errorf("failed to assign task %d: %v", taskId, err)
+		}
+		if err = recordMilestoneChange(tx, taskId, currentMilestoneId, &milestoneId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) UnassignTask(milestoneId, taskId int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(`
+		UPDATE project_tasks SET milestoneId = NULL, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND milestoneId = $2
+	`, taskId, milestoneId)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		err = fmt.Errorf("task %d is not part of milestone %d", taskId, milestoneId)
+		return err
+	}
+
+	if err = recordMilestoneChange(tx, taskId, &milestoneId, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordMilestoneChange adds the milestone move of a task to its field history
+func recordMilestoneChange(tx *sql.Tx, taskId int, from, to *int) error {
+	changes := history.Changes{}
+	changes.Add("milestoneId", from, to)
+	return history.Record(tx, entities.HistoryTargetProjectTask, taskId, nil, changes)
+}
+
+func parseDueDate(value string) (time.Time, error) {
+	dueDate, err := utils.ParseOptionalDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dueDate == nil {
+		return time.Time{}, fmt.Errorf("dueDate is required")
+	}
+	return *dueDate, nil
+}
@@ -0,0 +1,101 @@
+package mywork
+
+import (
+	"sort"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// group arranges items, already sorted by project and due date, into projects
+// and due date groups. Counts list priorities by rank and statuses by name,
+// with tasks without a status last.
+func group(items []entities.WorkItem, now time.Time) entities.MyWork {
+	work := entities.MyWork{
+		Total:      len(items),
+		Projects:   []entities.WorkProject{},
+		ByStatus:   []entities.WorkCount{},
+		ByPriority: []entities.WorkCount{},
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	statuses := map[int]*entities.WorkCount{}
+	noStatus := entities.WorkCount{Name: "No status"}
+	priorities := map[int]*entities.WorkCount{}
+	ranks := map[int]int{}
+
+	for _, item := range items {
+		if n := len(work.Projects); n == 0 || work.Projects[n-1].ProjectID != item.ProjectID {
+			work.Projects = append(work.Projects, entities.WorkProject{ProjectID: item.ProjectID, ProjectName: item.ProjectName})
+		}
+		project := &work.Projects[len(work.Projects)-1]
+		project.Total++
+
+		due := dueGroup(item.DueDate, today)
+		if n := len(project.Due); n == 0 || project.Due[n-1].Due != due {
+			project.Due = append(project.Due, entities.WorkDueGroup{Due: due})
+		}
+		last := &project.Due[len(project.Due)-1]
+		last.Items = append(last.Items, item)
+
+		if item.StatusID == nil {
+			noStatus.Count++
+		} else {
+			if statuses[*item.StatusID] == nil {
+				statuses[*item.StatusID] = &entities.WorkCount{ID: item.StatusID, Name: item.StatusName}
+			}
+			statuses[*item.StatusID].Count++
+		}
+
+		if priorities[item.PriorityID] == nil {
+			id := item.PriorityID
+			priorities[id] = &entities.WorkCount{ID: &id, Name: item.PriorityName}
+			ranks[id] = item.PriorityRank
+		}
+		priorities[item.PriorityID].Count++
+	}
+
+	for _, count := range statuses {
+		work.ByStatus = append(work.ByStatus, *count)
+	}
+	sort.Slice(work.ByStatus, func(i, j int) bool {
+		if work.ByStatus[i].Name != work.ByStatus[j].Name {
+			return work.ByStatus[i].Name < work.ByStatus[j].Name
+		}
+		return *work.ByStatus[i].ID < *work.ByStatus[j].ID
+	})
+	if noStatus.Count > 0 {
+		work.ByStatus = append(work.ByStatus, noStatus)
+	}
+
+	for _, count := range priorities {
+		work.ByPriority = append(work.ByPriority, *count)
+	}
+	sort.Slice(work.ByPriority, func(i, j int) bool {
+		a, b := *work.ByPriority[i].ID, *work.ByPriority[j].ID
+		if ranks[a] != ranks[b] {
+			return ranks[a] < ranks[b]
+		}
+		return a < b
+	})
+	return work
+}
+
+// dueGroup places a due date relative to today. Due dates have no time of
+// day, and this week is the next seven days.
+func dueGroup(dueDate *time.Time, today time.Time) string {
+	if dueDate == nil {
+		return entities.DueNone
+	}
+	day := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, today.Location())
+	switch {
+	case day.Before(today):
+		return entities.DueOverdue
+	case day.Equal(today):
+		return entities.DueToday
+	case day.Before(today.AddDate(0, 0, 7)):
+		return entities.DueThisWeek
+	default:
+		return entities.DueLater
+	}
+}
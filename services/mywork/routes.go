@@ -0,0 +1,10 @@
+package mywork
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/me/work", h.handleGetMyWork, "GET")
+}
@@ -0,0 +1,50 @@
+package mywork
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.MyWorkStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.MyWorkStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetMyWork(w http.ResponseWriter, r *http.Request) {
+	includeCompleted := false
+	switch value := r.URL.Query().Get("completed"); value {
+	case "", "false":
+	case "true":
+		includeCompleted = true
+	default:
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid completed value %q, expected true or false", value))
+		return
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	items, err := h.store.GetWorkItems(entities.WorkFilter{
+		OrgID:            utils.GetOrgID(r),
+		UserID:           *utils.GetUserID(r),
+		MemberID:         memberId,
+		IncludeCompleted: includeCompleted,
+	})
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": group(items, time.Now())})
+}
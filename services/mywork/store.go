@@ -0,0 +1,67 @@
+package mywork
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetWorkItems lists the board tasks and project tasks assigned to the user in
+// active projects, by project, then due date, then priority rank
+func (s *Store) GetWorkItems(filter entities.WorkFilter) ([]entities.WorkItem, error) {
+	rows, err := s.db.Query(`
+		SELECT 'task', t.id, t.title, p.id, p.name, t.statusId, COALESCE(st.name, ''),
+			pr.id, pr.name, pr.rank, t.dueDate, t.completedAt
+		FROM tasks t
+		JOIN workspaces w ON w.id = t.workspaceId AND w.deletedAt IS NULL
+		JOIN projects p ON p.id = w.projectId AND p.deletedAt IS NULL AND p.archivedAt IS NULL
+		JOIN priorities pr ON pr.id = t.priorityId
+		LEFT JOIN statuses st ON st.id = t.statusId
+		WHERE t.userId = $1 AND t.deletedAt IS NULL AND p.orgId = $2
+			AND ($3 OR t.completedAt IS NULL)
+			AND ($4::INT IS NULL OR `+members.MemberCondition("p.id", "$4")+`)
+		UNION ALL
+		SELECT 'projectTask', pt.id, pt.name, p.id, p.name, pt.statusId, COALESCE(st.name, ''),
+			pr.id, pr.name, pr.rank, pt.dueDate, pt.completedAt
+		FROM project_tasks pt
+		JOIN projects p ON p.id = pt.projectId AND p.deletedAt IS NULL AND p.archivedAt IS NULL
+		JOIN priorities pr ON pr.id = pt.priorityId
+		LEFT JOIN statuses st ON st.id = pt.statusId
+		WHERE pt.userId = $1 AND pt.deletedAt IS NULL AND p.orgId = $2
+			AND ($3 OR pt.completedAt IS NULL)
+			AND ($4::INT IS NULL OR `+members.MemberCondition("p.id", "$4")+`)
+		ORDER BY 5, 4, 11 NULLS LAST, 10, 1, 2
+	`, filter.UserID, filter.OrgID, filter.IncludeCompleted, filter.MemberID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query work items: %v", err)
+	}
+	defer rows.Close()
+
+	items := []entities.WorkItem{}
+	for rows.Next() {
+		item := entities.WorkItem{}
+		err := rows.Scan(
+			&item.Type, &item.ID, &item.Title, &item.ProjectID, &item.ProjectName, &item.StatusID, &item.StatusName,
+			&item.PriorityID, &item.PriorityName, &item.PriorityRank, &item.DueDate, &item.CompletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan work item: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over work item rows: %v", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// NotifyTaskChanges turns the field changes of a task update into
+// notifications: new assignees are subscribed and told about it, watchers hear
+// about column moves and completion.
+func NotifyTaskChanges(tx *sql.Tx, target string, taskId int, title string, actorId *int, changes []entities.FieldChange) error {
+	for _, change := range changes {
+		var event *entities.NotificationEvent
+
+		switch change.Field {
+		case "userId":
+			if change.NewValue == nil {
+				continue
+			}
+			assigneeId, err := strconv.Atoi(*change.NewValue)
+			if err != nil || assigneeId == 0 {
+				continue
+			}
+			if err := Watch(tx, target, taskId, assigneeId); err != nil {
+				return err
+			}
+			event = &entities.NotificationEvent{
+				Type:       entities.NotificationAssigned,
+				Recipients: []int{assigneeId},
+				Message:    fmt.Sprintf("You were assigned to %q", title),
+			}
+		case "workspaceId":
+			var column string
+			if err := tx.QueryRow("SELECT name FROM workspaces WHERE id = $1", change.NewValue).Scan(&column); err != nil {
+				return fmt.Errorf("failed to find workspace: %v", err)
+			}
+			event = &entities.NotificationEvent{
+				Type:           entities.NotificationMoved,
+				NotifyWatchers: true,
+				Message:        fmt.Sprintf("%q moved to %s", title, column),
+			}
+		case "completed":
+			message := fmt.Sprintf("%q was reopened", title)
+			if change.NewValue != nil && *change.NewValue == "true" {
+				message = fmt.Sprintf("%q was completed", title)
+			}
+			event = &entities.NotificationEvent{
+				Type:           entities.NotificationStatusChanged,
+				NotifyWatchers: true,
+				Message:        message,
+			}
+		default:
+			continue
+		}
+
+		event.ActorID = actorId
+		event.Target = target
+		event.TargetID = taskId
+		if err := Notify(tx, *event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
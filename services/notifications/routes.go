@@ -0,0 +1,18 @@
+package notifications
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const targetPattern = "{target:projects|tasks|project-tasks}"
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/watchers/"+targetPattern+"/{targetId}", h.handleGetWatchers, "GET")
+	utils.SecureRoute(router, "/watchers/"+targetPattern+"/{targetId}", h.handleWatch, "POST")
+	utils.SecureRoute(router, "/watchers/"+targetPattern+"/{targetId}", h.handleUnwatch, "DELETE")
+	utils.SecureRoute(router, "/notifications", h.handleGetNotifications, "GET")
+	utils.SecureRoute(router, "/notifications/unread-count", h.handleGetUnreadCount, "GET")
+	utils.SecureRoute(router, "/notifications/read-all", h.handleMarkAllRead, "PUT")
+	utils.SecureRoute(router, "/notifications/{notificationId}/read", h.handleMarkRead, "PUT")
+}
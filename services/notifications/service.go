@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.NotificationStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.NotificationStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetWatchers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetId, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s ID", vars["target"]))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], targetId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	watchers, err := h.store.GetWatchers(vars["target"], targetId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": watchers})
+}
+
+func (h *Handler) handleWatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetId, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s ID", vars["target"]))
+		return
+	}
+
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], targetId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	if err := h.store.Watch(vars["target"], targetId, *userId); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Watch Successfully!"})
+}
+
+func (h *Handler) handleUnwatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	targetId, err := strconv.Atoi(vars["targetId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid %s ID", vars["target"]))
+		return
+	}
+
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], targetId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	if err := h.store.Unwatch(vars["target"], targetId, *userId); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Unwatch Successfully!"})
+}
+
+func (h *Handler) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	notifications, err := h.store.GetNotifications(*userId, unreadOnly)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": notifications})
+}
+
+func (h *Handler) handleGetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	count, err := h.store.GetUnreadCount(*userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]int{"unread": count}})
+}
+
+func (h *Handler) handleMarkRead(w http.ResponseWriter, r *http.Request) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	notificationId, err := strconv.Atoi(mux.Vars(r)["notificationId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid notification ID"))
+		return
+	}
+
+	if err := h.store.MarkRead(*userId, notificationId); err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Mark Notification Read Successfully!"})
+}
+
+func (h *Handler) handleMarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("missing user"))
+		return
+	}
+
+	count, err := h.store.MarkAllRead(*userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Mark All Notifications Read Successfully!", "data": map[string]int{"updated": count}})
+}
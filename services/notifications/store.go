@@ -0,0 +1,252 @@
+package notifications
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// watchTarget describes the watcher table of a target and how to find its project
+type watchTarget struct {
+	table        string
+	column       string
+	projectQuery string
+}
+
+var watchTargets = map[string]watchTarget{
+	entities.WatchTargetProject: {
+		table:        "watchers_projects",
+		column:       "projectId",
+		projectQuery: "SELECT id FROM projects WHERE id = $1",
+	},
+	entities.WatchTargetTask: {
+		table:        "watchers_tasks",
+		column:       "taskId",
+		projectQuery: "SELECT w.projectId FROM tasks t JOIN workspaces w ON w.id = t.workspaceId WHERE t.id = $1",
+	},
+	entities.WatchTargetProjectTask: {
+		table:        "watchers_project_tasks",
+		column:       "projectTaskId",
+		projectQuery: "SELECT projectId FROM project_tasks WHERE id = $1",
+	},
+}
+
+func (s *Store) GetWatchers(target string, targetId int) ([]entities.User, error) {
+	t, ok := watchTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid watch target %s", target)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT u.id, u.firstName, u.lastName, u.email
+		FROM %s wt
+		JOIN users u ON u.id = wt.userId AND u.deletedAt IS NULL
+		WHERE wt.%s = $1
+		ORDER BY wt.createdAt
+	`, t.table, t.column), targetId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query watchers: %v", err)
+	}
+	defer rows.Close()
+
+	users := []entities.User{}
+	for rows.Next() {
+		user := entities.User{}
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan watcher: %v", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over watcher rows: %v", err)
+	}
+	return users, nil
+}
+
+func (s *Store) Watch(target string, targetId, userId int) error {
+	t, ok := watchTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid watch target %s", target)
+	}
+
+	var projectId int
+	err := s.db.QueryRow(t.projectQuery, targetId).Scan(&projectId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%s with ID %d not found", target, targetId)
+		}
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = Watch(tx, target, targetId, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) Unwatch(target string, targetId, userId int) error {
+	t, ok := watchTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid watch target %s", target)
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND userId = $2", t.table, t.column), targetId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to unwatch: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) GetNotifications(userId int, unreadOnly bool) ([]entities.Notification, error) {
+	query := `
+		SELECT n.id, n.userId, n.type, n.actorId, n.target, n.targetId, n.projectId, n.message, n.readAt, n.createdAt,
+			a.firstName, a.lastName, a.email
+		FROM notifications n
+		LEFT JOIN users a ON a.id = n.actorId
+		WHERE n.userId = $1
+	`
+	if unreadOnly {
+		query += " AND n.readAt IS NULL"
+	}
+	query += " ORDER BY n.createdAt DESC, n.id DESC LIMIT 100"
+
+	rows, err := s.db.Query(query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %v", err)
+	}
+	defer rows.Close()
+
+	notifications := []entities.Notification{}
+	for rows.Next() {
+		n := entities.Notification{}
+		var firstName, lastName, email *string
+		err := rows.Scan(
+			&n.ID, &n.UserID, &n.Type, &n.ActorID, &n.Target, &n.TargetID, &n.ProjectID, &n.Message, &n.ReadAt, &n.CreatedAt,
+			&firstName, &lastName, &email,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %v", err)
+		}
+		if n.ActorID != nil && firstName != nil {
+			n.Actor = &entities.User{ID: *n.ActorID, FirstName: *firstName, LastName: *lastName, Email: *email}
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over notification rows: %v", err)
+	}
+	return notifications, nil
+}
+
+func (s *Store) GetUnreadCount(userId int) (int, error) {
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM notifications WHERE userId = $1 AND readAt IS NULL", userId).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %v", err)
+	}
+	return count, nil
+}
+
+func (s *Store) MarkRead(userId, notificationId int) error {
+	result, err := s.db.Exec(`
+		UPDATE notifications SET readAt = COALESCE(readAt, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND userId = $2
+	`, notificationId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as read: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("notification with ID %d not found", notificationId)
+	}
+	return nil
+}
+
+func (s *Store) MarkAllRead(userId int) (int, error) {
+	result, err := s.db.Exec("UPDATE notifications SET readAt = CURRENT_TIMESTAMP WHERE userId = $1 AND readAt IS NULL", userId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %v", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// Watch subscribes a user to a target inside the caller's transaction, e.g.
+// when they are assigned a task or comment on it.
+func Watch(tx *sql.Tx, target string, targetId, userId int) error {
+	t, ok := watchTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid watch target %s", target)
+	}
+
+	_, err := tx.Exec(fmt.Sprintf(`
+		INSERT INTO %s (userId, %s) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, t.table, t.column), userId, targetId)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s %d: %v", target, targetId, err)
+	}
+	return nil
+}
+
+// Notify delivers an event to its recipients inside the caller's transaction.
+func Notify(tx *sql.Tx, event entities.NotificationEvent) error {
+	t, ok := watchTargets[event.Target]
+	if !ok {
+		return fmt.Errorf("invalid watch target %s", event.Target)
+	}
+
+	var projectId *int
+	if err := tx.QueryRow(t.projectQuery, event.TargetID).Scan(&projectId); err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to find project of %s %d: %v", event.Target, event.TargetID, err)
+	}
+
+	recipients := append([]int{}, event.Recipients...)
+	if event.NotifyWatchers {
+		rows, err := tx.Query(fmt.Sprintf(`
+			SELECT userId FROM %s WHERE %s = $1
+			UNION
+			SELECT userId FROM watchers_projects WHERE projectId = $2
+		`, t.table, t.column), event.TargetID, projectId)
+		if err != nil {
+			return fmt.Errorf("failed to query watchers: %v", err)
+		}
+		for rows.Next() {
+			var userId int
+			if err := rows.Scan(&userId); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan watcher: %v", err)
+			}
+			recipients = append(recipients, userId)
+		}
+		rows.Close()
+	}
+
+	// One row per recipient; the actor and deleted users are skipped
+	_, err := tx.Exec(`
+		INSERT INTO notifications (userId, type, actorId, target, targetId, projectId, message)
+		SELECT u.id, $2, $3, $4, $5, $6, $7
+		FROM users u
+		WHERE u.id = ANY($1) AND u.deletedAt IS NULL AND u.id IS DISTINCT FROM $3
+	`, pq.Array(recipients), event.Type, event.ActorID, event.Target, event.TargetID, projectId, event.Message)
+	if err != nil {
+		return fmt.Errorf("failed to create notifications: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package organizations
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/organizations", h.handleGetOrganizations, "GET")
+	utils.SecureRoute(router, "/organizations", h.handleCreateOrganization, "POST")
+	utils.SecureRoute(router, "/organizations/current", h.handleGetCurrentOrganization, "GET")
+	utils.SecureRoute(router, "/organizations/current", h.handleUpdateCurrentOrganization, "PUT")
+}
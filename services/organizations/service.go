@@ -0,0 +1,108 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.OrganizationStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.OrganizationStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetOrganizations(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeOperator(w, r, h.access) {
+		return
+	}
+
+	orgs, err := h.store.GetOrganizations()
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": orgs})
+}
+
+func (h *Handler) handleCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeOperator(w, r, h.access) {
+		return
+	}
+
+	payload := entities.OrganizationCreatePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	password, err := utils.HashPassword(payload.Admin.Password)
+	if err != nil || password == "" {
+		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("problem hashing password"))
+		return
+	}
+	payload.Admin.Password = password
+
+	org, err := h.store.CreateOrganization(payload)
+	if errors.Is(err, ErrEmailTaken) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": org})
+}
+
+func (h *Handler) handleGetCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	org, err := h.store.GetOrganization(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": org})
+}
+
+func (h *Handler) handleUpdateCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	payload := entities.OrganizationPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+	payload.ID = utils.GetOrgID(r)
+
+	if err := h.store.UpdateOrganization(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Organization Successfully!"})
+}
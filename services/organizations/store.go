@@ -0,0 +1,196 @@
+package organizations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// ErrOtherOrganization is returned when a payload references a row owned by
+// another tenant
+var ErrOtherOrganization = errors.New("does not belong to your organization")
+
+// ErrEmailTaken is returned when the admin of a new organization already has an account
+var ErrEmailTaken = errors.New("email is already in use")
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// seededTables are copied from the default organization so a new tenant
+// starts with the usual roles, statuses and priorities
+var seededTables = []struct {
+	table   string
+	columns string
+}{
+	{entities.TenantRoles, "name, description, isAdmin"},
+	{entities.TenantStatuses, "name, description, category"},
+	{entities.TenantPriorities, "name, description, rank, color, icon, responseSlaHours, resolutionSlaHours"},
+}
+
+func (s *Store) GetOrganizations() ([]entities.Organization, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, createdAt, updatedAt, deletedAt
+		FROM organizations
+		WHERE deletedAt IS NULL
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %v", err)
+	}
+	defer rows.Close()
+
+	orgs := []entities.Organization{}
+	for rows.Next() {
+		org := entities.Organization{}
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt, &org.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %v", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over organization rows: %v", err)
+	}
+	return orgs, nil
+}
+
+func (s *Store) GetOrganization(id int) (*entities.Organization, error) {
+	org := entities.Organization{}
+	err := s.db.QueryRow(`
+		SELECT id, name, createdAt, updatedAt, deletedAt
+		FROM organizations
+		WHERE id = $1 AND deletedAt IS NULL
+	`, id).Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt, &org.DeletedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("organization with ID %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organization: %v", err)
+	}
+	return &org, nil
+}
+
+// CreateOrganization adds a tenant with the lookups of the default
+// organization and an admin user. The admin password must already be hashed.
+func (s *Store) CreateOrganization(payload entities.OrganizationCreatePayload) (*entities.Organization, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var taken bool
+	if err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))`, payload.Admin.Email).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("failed to check email: %v", err)
+	}
+	if taken {
+		err = fmt.Errorf("%s %w", payload.Admin.Email, ErrEmailTaken)
+		return nil, err
+	}
+
+	org := entities.Organization{}
+	err = tx.QueryRow(`
+		INSERT INTO organizations (name) VALUES ($1)
+		RETURNING id, name, createdAt, updatedAt
+	`, payload.Name).Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %v", err)
+	}
+
+	for _, seeded := range seededTables {
+		_, err = tx.Exec(`
+			INSERT INTO `+seeded.table+` (`+seeded.columns+`, orgId)
+			SELECT `+seeded.columns+`, $1 FROM `+seeded.table+`
+			WHERE orgId = $2 AND deletedAt IS NULL
+		`, org.ID, entities.DefaultOrgID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %v", seeded.table, err)
+		}
+	}
+
+	var adminRoleId int
+	err = tx.QueryRow(`
+		SELECT id FROM roles WHERE orgId = $1 AND isAdmin AND deletedAt IS NULL ORDER BY id LIMIT 1
+	`, org.ID).Scan(&adminRoleId)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRow(`
+			INSERT INTO roles (name, description, orgId, isAdmin) VALUES ($1, 'administrator', $2, TRUE) RETURNING id
+		`, entities.AdminRoleName, org.ID).Scan(&adminRoleId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin role: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users (firstName, lastName, email, password, roleId, orgId)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, payload.Admin.FirstName, payload.Admin.LastName, payload.Admin.Email, payload.Admin.Password, adminRoleId, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization admin: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (s *Store) UpdateOrganization(payload entities.OrganizationPayload) error {
+	result, err := s.db.Exec(`
+		UPDATE organizations SET name = $1, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deletedAt IS NULL
+	`, payload.Name, payload.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("organization with ID %d not found", payload.ID)
+	}
+	return nil
+}
+
+// CheckOwned fails with ErrOtherOrganization unless every id of table belongs
+// to orgId. It runs inside the caller's transaction so the check and the
+// write see the same rows.
+func CheckOwned(tx *sql.Tx, orgId int, table string, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var missing []int64
+	err := tx.QueryRow(`
+		SELECT COALESCE(array_agg(id), '{}') FROM unnest($1::INT[]) AS ids(id)
+		WHERE NOT EXISTS (SELECT 1 FROM `+table+` t WHERE t.id = ids.id AND t.orgId = $2)
+	`, pq.Array(ids), orgId).Scan(pq.Array(&missing))
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %v", table, err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s %v %w", table, missing, ErrOtherOrganization)
+	}
+	return nil
+}
+
+// CheckForProject is CheckOwned for the organization that owns the project
+func CheckForProject(tx *sql.Tx, projectId int, table string, ids []int) error {
+	var orgId int
+	if err := tx.QueryRow(`SELECT orgId FROM projects WHERE id = $1`, projectId).Scan(&orgId); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("project with ID %d not found", projectId)
+		}
+		return fmt.Errorf("failed to retrieve project organization: %v", err)
+	}
+	return CheckOwned(tx, orgId, table, ids)
+}
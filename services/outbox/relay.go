@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// relayLockKey is the advisory lock that keeps a single relay publishing at a
+// time. Together with the aggregate lock taken by Record it preserves the order
+// of the events of each aggregate; events of different aggregates may be
+// published in any order.
+const relayLockKey = 7_302_035
+
+type Relay struct {
+	db        *sql.DB
+	sink      entities.EventSink
+	batchSize int
+}
+
+func NewRelay(db *sql.DB, sink entities.EventSink, batchSize int) *Relay {
+	// With no positive batch size the relay would publish nothing or fail to query
+	if batchSize <= 0 {
+		log.Printf("Invalid outbox batch size %d, using %d", batchSize, config.DefaultOutboxBatchSize)
+		batchSize = config.DefaultOutboxBatchSize
+	}
+	return &Relay{db: db, sink: sink, batchSize: batchSize}
+}
+
+// RelayOnce publishes the oldest unpublished events, in order within each
+// aggregate. Events are only marked published once the sink accepted them, so
+// delivery is at least once. When an event fails the later events of the same
+// aggregate wait until it succeeds, without holding back other aggregates.
+func (r *Relay) RelayOnce() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRow("SELECT pg_try_advisory_xact_lock($1)", relayLockKey).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to lock outbox: %v", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	rows, err := tx.Query(`
+		SELECT e.id, e.orgId, e.aggregateType, e.aggregateId, e.eventType, e.payload, e.createdAt
+		FROM outbox_events e
+		WHERE e.publishedAt IS NULL AND NOT EXISTS (
+			SELECT 1 FROM outbox_events failed
+			WHERE failed.publishedAt IS NULL AND failed.attempts > 0 AND failed.id < e.id
+				AND failed.aggregateType = e.aggregateType AND failed.aggregateId = e.aggregateId
+		)
+		ORDER BY e.id
+		LIMIT $1
+	`, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query outbox: %v", err)
+	}
+
+	events := []entities.OutboxEvent{}
+	for rows.Next() {
+		event := entities.OutboxEvent{}
+		if err := rows.Scan(&event.ID, &event.OrgID, &event.AggregateType, &event.AggregateID, &event.EventType, &event.Payload, &event.CreatedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan outbox event: %v", err)
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, event := range events {
+		aggregate := fmt.Sprintf("%s:%d", event.AggregateType, event.AggregateID)
+		if blocked[aggregate] {
+			continue
+		}
+
+		if err := r.sink.Publish(event); err != nil {
+			blocked[aggregate] = true
+			_, err = tx.Exec("UPDATE outbox_events SET attempts = attempts + 1, lastError = $1 WHERE id = $2", err.Error(), event.ID)
+			if err != nil {
+				return 0, fmt.Errorf("failed to record outbox failure: %v", err)
+			}
+			continue
+		}
+
+		_, err = tx.Exec("UPDATE outbox_events SET attempts = attempts + 1, lastError = NULL, publishedAt = CURRENT_TIMESTAMP WHERE id = $1", event.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark outbox event as published: %v", err)
+		}
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// StartRelay publishes outbox events every interval in the background.
+func StartRelay(relay *Relay, interval time.Duration) {
+	interval = utils.PositiveDuration("outbox interval", interval, config.DefaultOutboxIntervalSeconds*time.Second)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			published, err := relay.RelayOnce()
+			if err != nil {
+				log.Printf("Outbox relay: %v", err)
+			} else if published > 0 {
+				log.Printf("Outbox relay: published %d event(s)", published)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// NewSink builds the sink named by OUTBOX_SINK: "log" or "http".
+func NewSink(kind, url string) (entities.EventSink, error) {
+	switch kind {
+	case "", "log":
+		return LogSink{}, nil
+	case "http":
+		if url == "" {
+			return nil, fmt.Errorf("the http outbox sink needs OUTBOX_HTTP_URL")
+		}
+		return NewHTTPSink(url), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %s", kind)
+	}
+}
+
+// LogSink writes events to the service log.
+type LogSink struct{}
+
+func (LogSink) Publish(event entities.OutboxEvent) error {
+	log.Printf("Outbox event %d %s %s:%d %s", event.ID, event.EventType, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// HTTPSink POSTs every event as JSON and expects a 2xx answer. Receivers can
+// use the event id to drop duplicates.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSink) Publish(event entities.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("outbox sink responded with %s", res.Status)
+	}
+	return nil
+}
+
+// MemoryBus keeps published events in memory and hands them to subscribers,
+// which makes it the sink of choice for tests.
+type MemoryBus struct {
+	mu          sync.Mutex
+	events      []entities.OutboxEvent
+	subscribers []func(entities.OutboxEvent)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+func (b *MemoryBus) Publish(event entities.OutboxEvent) error {
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	subscribers := append([]func(entities.OutboxEvent){}, b.subscribers...)
+	b.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(subscriber func(entities.OutboxEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Events returns the events published so far
+func (b *MemoryBus) Events() []entities.OutboxEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]entities.OutboxEvent{}, b.events...)
+}
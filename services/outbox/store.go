@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Record writes a domain event of the organization that owns the aggregate
+// inside the caller's transaction, so it is published if and only if the change
+// commits.
+//
+// Event ids are taken at insert but become visible at commit, so a later event
+// could otherwise be relayed before an earlier one commits. Record holds a lock
+// on the aggregate until the transaction ends, which makes the ids of one
+// aggregate follow the order its changes commit in.
+func Record(tx *sql.Tx, orgId int, aggregateType string, aggregateId int, action string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %v", aggregateType, err)
+	}
+
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1), $2)`, aggregateType, aggregateId); err != nil {
+		return fmt.Errorf("failed to lock %s %d: %v", aggregateType, aggregateId, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO outbox_events (orgId, aggregateType, aggregateId, eventType, payload) VALUES ($1, $2, $3, $4, $5)
+	`, orgId, aggregateType, aggregateId, aggregateType+"."+action, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to record %s.%s event: %v", aggregateType, action, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package priorities
+
+import (
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/lookups"
+)
+
+const defaultColor = "#6B7280"
+
+var Kind = lookups.Kind[entities.Priority, entities.PriorityPayload]{
+	Table:   "priorities",
+	Label:   "priority",
+	Select:  []string{"name", "description", "rank", "color", "icon", "responseSlaHours", "resolutionSlaHours"},
+	Columns: []string{"name", "description", "color", "icon", "responseSlaHours", "resolutionSlaHours"},
+	// New and restored priorities are the least urgent until they are moved
+	OnInsert: map[string]string{
+		"rank": "(SELECT COALESCE(MAX(rank), 0) + 1 FROM priorities WHERE orgId = $1 AND deletedAt IS NULL)",
+	},
+	OrderBy: "rank, id",
+	References: []lookups.Reference{
+		{Name: "tasks", Table: "tasks", Column: "priorityId", Live: "deletedAt IS NULL"},
+		{Name: "projectTasks", Table: "project_tasks", Column: "priorityId", Live: "deletedAt IS NULL"},
+	},
+	Fields: func(priority *entities.Priority) []interface{} {
+		return []interface{}{
+			&priority.ID, &priority.Name, &priority.Description, &priority.Rank, &priority.Color, &priority.Icon,
+			&priority.ResponseSLAHours, &priority.ResolutionSLAHours, &priority.CreatedAt, &priority.UpdatedAt,
+		}
+	},
+	Values: func(payload entities.PriorityPayload) []interface{} {
+		color := payload.Color
+		if color == "" {
+			color = defaultColor
+		}
+		return []interface{}{
+			payload.Name, payload.Description, color, payload.Icon,
+			hoursOrNull(payload.ResponseSLAHours), hoursOrNull(payload.ResolutionSLAHours),
+		}
+	},
+	Name: func(payload entities.PriorityPayload) string { return payload.Name },
+	Merge: func(priority entities.Priority, payload entities.PriorityPayload) entities.PriorityPayload {
+		if payload.Name == "" {
+			payload.Name = priority.Name
+		}
+		if payload.Description == "" {
+			payload.Description = priority.Description
+		}
+		if payload.Color == "" {
+			payload.Color = priority.Color
+		}
+		if payload.Icon == "" {
+			payload.Icon = priority.Icon
+		}
+		if payload.ResponseSLAHours == nil {
+			payload.ResponseSLAHours = priority.ResponseSLAHours
+		}
+		if payload.ResolutionSLAHours == nil {
+			payload.ResolutionSLAHours = priority.ResolutionSLAHours
+		}
+		return payload
+	},
+}
+
+// hoursOrNull clears an SLA target set to 0
+func hoursOrNull(hours *int) *int {
+	if hours == nil || *hours == 0 {
+		return nil
+	}
+	return hours
+}
@@ -6,11 +6,6 @@ import (
 )
 
 func RegisterRoutes(router *mux.Router, h *Handler) {
-	utils.SecureRoute(router, "/priorities", h.handleGetPriorities, "GET")
-	utils.SecureRoute(router, "/priorities", h.handleCreatePriority, "POST")
-	utils.SecureRoute(router, "/priorities/{priorityId}", h.handleGetPriority, "GET")
-	utils.SecureRoute(router, "/priorities/{priorityId}", h.handleUpdatePriority, "PUT")
-	utils.SecureRoute(router, "/priorities/{priorityId}/restore", h.handleRestorePriority, "PUT")
-	utils.SecureRoute(router, "/priorities/{priorityId}", h.handleDeletePriority, "DELETE")
-
+	utils.SecureRoute(router, "/priorities/reorder", h.handleReorderPriorities, "PUT")
+	utils.SecureRoute(router, "/priorities/{priorityId}/rank", h.handleMovePriority, "PUT")
 }
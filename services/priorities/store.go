@@ -3,11 +3,11 @@ package priorities
 import (
 	"database/sql"
 	"fmt"
-	"log"
 
-	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/lib/pq"
 )
 
+// Store orders priorities; the rest of their CRUD is the shared lookup store
 type Store struct {
 	db *sql.DB
 }
@@ -16,153 +16,116 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetPriorities() ([]entities.Priority, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name, description, createdAt, updatedAt
-			FROM priorities
-		WHERE deletedAt IS NULL
-		ORDER BY createdAt DESC
-	`)
+// ReorderPriorities ranks the priorities in the given order, which must list
+// every priority of the organization
+func (s *Store) ReorderPriorities(orgId int, ids []int) error {
+	tx, err := s.db.Begin()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query priorities: %v", err)
-	}
-	defer rows.Close()
-
-	priorities := []entities.Priority{}
-
-	for rows.Next() {
-		priority := entities.Priority{}
-
-		err := scanRowIntoPriority(rows, &priority)
-		if err != nil {
-			log.Printf("Failed to scan priority: %v", err)
-			continue
-		}
-		priorities = append(priorities, priority)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to iterate over priority rows: %v", err)
+		return err
 	}
-	return priorities, nil
-}
 
-func (s *Store) GetPriority(id int) (*entities.Priority, error) {
-	priority := entities.Priority{}
-	err := s.db.QueryRow("SELECT id, name, description, createdAt, updatedAt FROM priorities WHERE deletedAt IS NULL AND id = $1", id).Scan(
-		&priority.ID,
-		&priority.Name,
-		&priority.Description,
-		&priority.CreatedAt,
-		&priority.UpdatedAt,
-	)
+	current, err := lockPriorities(tx, orgId)
 	if err != nil {
-		return nil, fmt.Errorf("priority not found")
+		tx.Rollback()
+		return err
 	}
 
-	if priority.ID == 0 {
-		return nil, fmt.Errorf("priority not found")
+	listed := map[int]bool{}
+	for _, id := range ids {
+		if listed[id] {
+			tx.Rollback()
+			return fmt.Errorf("priority %d is listed more than once", id)
+		}
+		listed[id] = true
 	}
-	return &priority, nil
-}
-
-func (s *Store) CreatePriority(payload entities.PriorityPayload) (*entities.Priority, error) {
-	tx, err := s.db.Begin()
-
-	if err != nil {
-		return nil, err
+	if len(ids) != len(current) {
+		tx.Rollback()
+		return fmt.Errorf("expected all %d priorities of the organization, got %d", len(current), len(ids))
 	}
-
-	_, err = tx.Exec("INSERT INTO priorities (name, description) VALUES ($1, $2)", payload.Name, payload.Description)
-	if err != nil {
-		// If there's an error, rollback the transaction
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return nil, fmt.Errorf("insert error: %v, rollback error: %v", err, rbErr)
+	for _, id := range current {
+		if !listed[id] {
+			tx.Rollback()
+			return fmt.Errorf("priority %d is missing from the new order", id)
 		}
-		return nil, err
 	}
 
-	// Commit the transaction if all went well
-	if err = tx.Commit(); err != nil {
-		return &entities.Priority{Name: payload.Name, Description: payload.Description}, err
+	if err = rank(tx, ids); err != nil {
+		tx.Rollback()
+		return err
 	}
-
-	return &entities.Priority{Name: payload.Name, Description: payload.Description}, err
+	return tx.Commit()
 }
 
-func (s *Store) UpdatePriority(payload entities.PriorityPayload) error {
+// MovePriority puts a priority at the given rank and shifts the ones in
+// between. Ranks past the end move it last.
+func (s *Store) MovePriority(orgId, id, position int) error {
 	tx, err := s.db.Begin()
-
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE priorities SET name = $1, description = $2, updatedAt = CURRENT_TIMESTAMP WHERE id = $3", payload.Name, payload.Description, payload.ID)
+	current, err := lockPriorities(tx, orgId)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("insert error: %v, rollback error: %v", err, rbErr)
-		}
+		tx.Rollback()
 		return err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return err
+	ids := make([]int, 0, len(current))
+	for _, currentId := range current {
+		if currentId != id {
+			ids = append(ids, currentId)
+		}
 	}
-
-	return err
-}
-
-func (s *Store) DeletePriority(id int) error {
-	tx, err := s.db.Begin()
-	if err != nil {
-		return err
+	if len(ids) == len(current) {
+		tx.Rollback()
+		return fmt.Errorf("priority not found")
 	}
 
-	_, err = tx.Exec("UPDATE priorities SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
-
-	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
-		}
-		return err
+	index := position - 1
+	if index > len(ids) {
+		index = len(ids)
 	}
+	ids = append(ids[:index], append([]int{id}, ids[index:]...)...)
 
-	if err = tx.Commit(); err != nil {
+	if err = rank(tx, ids); err != nil {
+		tx.Rollback()
 		return err
 	}
-
-	return err
+	return tx.Commit()
 }
 
-func (s *Store) RestorePriority(id int) error {
-	tx, err := s.db.Begin()
-
+// lockPriorities returns the ids of the active priorities of the
+// organization in rank order, locking them until the transaction ends
+func lockPriorities(tx *sql.Tx, orgId int) ([]int, error) {
+	rows, err := tx.Query(`
+		SELECT id FROM priorities WHERE orgId = $1 AND deletedAt IS NULL
+		ORDER BY rank, id
+		FOR UPDATE
+	`, orgId)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to query priorities: %v", err)
 	}
+	defer rows.Close()
 
-	_, err = tx.Exec("UPDATE priorities SET deletedAt = NULL WHERE id = $1", id)
-	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan priority: %v", err)
 		}
-		return err
+		ids = append(ids, id)
 	}
-
-	// Commit the transaction if all went well
-	if err = tx.Commit(); err != nil {
-		return err
-	}
-
-	return err
+	return ids, rows.Err()
 }
 
-func scanRowIntoPriority(rows *sql.Rows, priority *entities.Priority) error {
-	return rows.Scan(
-		&priority.ID,
-		&priority.Name,
-		&priority.Description,
-		&priority.CreatedAt,
-		&priority.UpdatedAt,
-	)
+func rank(tx *sql.Tx, ids []int) error {
+	_, err := tx.Exec(`
+		UPDATE priorities p SET rank = ordered.rank, updatedAt = CURRENT_TIMESTAMP
+		FROM unnest($1::INT[]) WITH ORDINALITY AS ordered(id, rank)
+		WHERE p.id = ordered.id AND p.rank <> ordered.rank
+	`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to rank priorities: %v", err)
+	}
+	return nil
 }
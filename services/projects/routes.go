@@ -13,4 +13,6 @@ func RegisterRoutes(router *mux.Router, h *Handler) {
 	utils.SecureRoute(router, "/projects/{projectId}", h.handleProjectUpdate, "PUT")
 	utils.SecureRoute(router, "/projects/{projectId}", h.handleProjectDelete, "DELETE")
 	utils.SecureRoute(router, "/projects/{projectId}/restore", h.handleProjectRestore, "PUT")
+	utils.SecureRoute(router, "/projects/{projectId}/archive", h.handleProjectArchive, "PUT")
+	utils.SecureRoute(router, "/projects/{projectId}/unarchive", h.handleProjectUnarchive, "PUT")
 }
@@ -1,6 +1,7 @@
 package projects
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,19 +10,49 @@ import (
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Handler struct {
-	store entities.ProjectStore
+	store  entities.ProjectStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.ProjectStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.ProjectStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
 }
 
 func (h *Handler) handleGetProjects(w http.ResponseWriter, r *http.Request) {
-	projects, err := h.store.GetProjects("IS NULL")
+	labelIDs, err := utils.ParseIntList(r.URL.Query().Get("labelIds"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	archived, err := parseArchived(r.URL.Query().Get("archived"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var segmentId *int
+	if str := r.URL.Query().Get("segmentId"); str != "" {
+		id, err := strconv.Atoi(str)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid segment ID"))
+			return
+		}
+		segmentId = &id
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	projects, err := h.store.GetProjects("IS NULL", entities.ProjectFilter{OrgID: utils.GetOrgID(r), LabelIDs: labelIDs, MemberID: memberId, Archived: archived, SegmentID: segmentId})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -31,7 +62,12 @@ func (h *Handler) handleGetProjects(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleGetProjectDeleted(w http.ResponseWriter, r *http.Request) {
-	projects, err := h.store.GetProjects("IS NOT NULL")
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	projects, err := h.store.GetProjects("IS NOT NULL", entities.ProjectFilter{OrgID: utils.GetOrgID(r), MemberID: memberId})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -59,6 +95,10 @@ func (h *Handler) handleGetProject(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project Id"))
 		return
 	}
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
 	proj, err := h.store.GetProject(projectId)
 	if err != nil {
 		utils.WriteError(w, http.StatusNotFound, err)
@@ -99,6 +139,8 @@ func (h *Handler) handleProjectCreate(w http.ResponseWriter, r *http.Request) {
 		payload.DateDeadline = DateDeadline.Format("Jan-02-2006") // Convert time.Time back to string
 	}
 
+	payload.CreatedBy = utils.GetUserID(r)
+	payload.OrgID = utils.GetOrgID(r)
 	proj, err := h.store.ProjectCreate(payload)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -122,6 +164,10 @@ func (h *Handler) handleProjectUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
 	payload := entities.ProjectUpdatePayload{}
 
 	if err := utils.ParseJSON(r, &payload); err != nil {
@@ -180,7 +226,12 @@ func (h *Handler) handleProjectUpdate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	payload.OrgID = utils.GetOrgID(r)
 	err = h.store.ProjectUpdate(projectId, payload, userIDs)
+	if workflows.IsViolation(err) {
+		utils.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -203,6 +254,10 @@ func (h *Handler) handleProjectDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleOwner) {
+		return
+	}
+
 	project, err := h.store.ProjectDelete(projectId)
 
 	if err != nil {
@@ -227,6 +282,10 @@ func (h *Handler) handleProjectRestore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleOwner) {
+		return
+	}
+
 	project, err := h.store.ProjectRestore(projectId)
 
 	if err != nil {
@@ -236,3 +295,67 @@ func (h *Handler) handleProjectRestore(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Restore Project Successfully!", "data": project})
 }
+
+func (h *Handler) handleProjectArchive(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	project, err := h.store.ProjectArchive(projectId, utils.GetUserID(r))
+	if errors.Is(err, ErrAlreadyArchived) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Archive Project Successfully!", "data": project})
+}
+
+func (h *Handler) handleProjectUnarchive(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	project, err := h.store.ProjectUnarchive(projectId)
+	if errors.Is(err, ErrNotArchived) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Unarchive Project Successfully!", "data": project})
+}
+
+// parseArchived reads the archived query parameter: active projects by
+// default, "true" for archived ones only and "all" for both.
+func parseArchived(value string) (*bool, error) {
+	switch value {
+	case "", "false":
+		archived := false
+		return &archived, nil
+	case "true":
+		archived := true
+		return &archived, nil
+	case "all":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("invalid archived value %q, expected true, false or all", value)
+}
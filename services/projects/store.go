@@ -2,11 +2,26 @@ package projects
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/outbox"
+	"github.com/norrico31/it210-core-service-backend/services/segments"
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
+)
+
+var (
+	ErrAlreadyArchived = errors.New("project is already archived")
+	ErrNotArchived     = errors.New("project is not archived")
 )
 
 type Store struct {
@@ -17,7 +32,7 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetProjects(condition string) ([]*entities.Project, error) {
+func (s *Store) GetProjects(condition string, filter entities.ProjectFilter) ([]*entities.Project, error) {
 	query := `
 		SELECT
 			p.id AS project_id,
@@ -32,6 +47,8 @@ func (s *Store) GetProjects(condition string) ([]*entities.Project, error) {
 			p.updatedAt AS project_updated_at,
 			p.deletedAt AS project_deleted_at,
 			p.deletedBy AS project_deleted_by,
+			p.archivedAt AS project_archived_at,
+			p.archivedBy AS project_archived_by,
 
 			stat.id AS status_id,
 			stat.name AS status_name,
@@ -79,7 +96,30 @@ func (s *Store) GetProjects(condition string) ([]*entities.Project, error) {
 			project_tasks t ON t.deletedAt IS NULL AND t.projectId = p.id
 	`
 
-	rows, err := s.db.Query(query)
+	conditions := []string{"p.orgId = $1"}
+	args := []interface{}{filter.OrgID}
+	if len(filter.LabelIDs) > 0 {
+		args = append(args, pq.Array(filter.LabelIDs))
+		conditions = append(conditions, labels.LabelFilterCondition(entities.LabelTargetProject, "p.id", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.MemberID != nil {
+		args = append(args, *filter.MemberID)
+		conditions = append(conditions, members.MemberCondition("p.id", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.SegmentID != nil {
+		args = append(args, *filter.SegmentID)
+		conditions = append(conditions, segments.SubtreeCondition("p.id", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.Archived != nil {
+		if *filter.Archived {
+			conditions = append(conditions, "p.archivedAt IS NOT NULL")
+		} else {
+			conditions = append(conditions, "p.archivedAt IS NULL")
+		}
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ")
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +148,7 @@ func (s *Store) GetProjects(condition string) ([]*entities.Project, error) {
 		var taskDeletedBy *int
 
 		err := rows.Scan(
-			&projectId, &project.Name, &project.Description, &project.Url, &project.Progress, &projectStatusId, &dateStarted, &dateDeadline, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt, &project.DeletedBy,
+			&projectId, &project.Name, &project.Description, &project.Url, &project.Progress, &projectStatusId, &dateStarted, &dateDeadline, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt, &project.DeletedBy, &project.ArchivedAt, &project.ArchivedBy,
 			&statusID, &statusName, &statusDescription,
 			&segmentId, &segmentName, &segmentDescription,
 			&userID, &userFirstName, &userLastName, &userEmail, &userAge, &userRoleId, &userLastActiveAt, &userCreatedAt, &userUpdatedAt, &userDeletedAt, &userDeletedBy,
@@ -212,6 +252,21 @@ func (s *Store) GetProjects(condition string) ([]*entities.Project, error) {
 		return projects[i].CreatedAt.After(projects[j].CreatedAt)
 	})
 
+	projectIDs := make([]int, 0, len(projects))
+	for _, project := range projects {
+		projectIDs = append(projectIDs, project.ID)
+	}
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetProject, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range projects {
+		project.Labels = labelsMap[project.ID]
+		if project.Labels == nil {
+			project.Labels = []entities.Label{}
+		}
+	}
+
 	return projects, nil
 }
 
@@ -231,6 +286,8 @@ func (s *Store) GetProject(id int) (*entities.Project, error) {
 			p.updatedAt AS project_updated_at,
 			p.deletedAt AS project_deleted_at,
 			p.deletedBy AS project_deleted_by,
+			p.archivedAt AS project_archived_at,
+			p.archivedBy AS project_archived_by,
 
 			stat.id AS status_id,
 			stat.name AS status_name,
@@ -277,6 +334,8 @@ func (s *Store) GetProject(id int) (*entities.Project, error) {
 			t.userId, 
 			t.priorityId, 
 			t.projectId, 
+			t.storyPoints, 
+			t.estimateHours, 
 			t.createdAt, 
 			t.updatedAt, 
 			t.deletedAt, 
@@ -320,7 +379,7 @@ func (s *Store) GetProject(id int) (*entities.Project, error) {
 		var segmentName, segmentDescription *string
 
 		err := rows.Scan(
-			&project.ID, &project.Name, &project.Description, &project.Url, &project.Progress, &projectStatusId, &dateStarted, &dateDeadline, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt, &project.DeletedBy,
+			&project.ID, &project.Name, &project.Description, &project.Url, &project.Progress, &projectStatusId, &dateStarted, &dateDeadline, &project.CreatedAt, &project.UpdatedAt, &project.DeletedAt, &project.DeletedBy, &project.ArchivedAt, &project.ArchivedBy,
 			&statusID, &statusName, &statusDescription, &segmentId, &segmentName, &segmentDescription,
 			&userID, &userFirstName, &userLastName, &userEmail, &userAge, &userRoleId, &userLastActiveAt, &userCreatedAt, &userUpdatedAt, &userDeletedAt, &userDeletedBy,
 		)
@@ -405,6 +464,7 @@ func (s *Store) GetProject(id int) (*entities.Project, error) {
 
 		err := taskRows.Scan(
 			&task.ID, &task.Name, &task.Description, &task.UserID, &task.PriorityID, &task.ProjectID,
+			&task.StoryPoints, &task.EstimateHours,
 			&task.CreatedAt, &task.UpdatedAt, &taskDeletedAt, &taskDeletedBy,
 			&user.ID, &user.FirstName, &user.LastName, &user.Email,
 			&priority.ID, &priority.Name, &priority.Description,
@@ -421,9 +481,24 @@ func (s *Store) GetProject(id int) (*entities.Project, error) {
 		if !taskMap[task.ID] {
 			taskMap[task.ID] = true
 			project.Tasks = append(project.Tasks, task)
+			if task.StoryPoints != nil {
+				project.StoryPoints += *task.StoryPoints
+			}
+			if task.EstimateHours != nil {
+				project.EstimateHours += *task.EstimateHours
+			}
 		}
 	}
 
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetProject, []int{project.ID})
+	if err != nil {
+		return nil, err
+	}
+	project.Labels = labelsMap[project.ID]
+	if project.Labels == nil {
+		project.Labels = []entities.Label{}
+	}
+
 	return &project, nil
 }
 
@@ -454,10 +529,15 @@ func (s *Store) ProjectCreate(payload entities.ProjectCreatePayload) (map[string
 	// 	deadline = &dateDeadline
 	// }
 
+	if err = checkReferences(tx, payload.OrgID, &payload.StatusID, payload.SegmentID, payload.UserIDs); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	proj := entities.Project{}
 	err = tx.QueryRow(`
-		INSERT INTO projects (name, description, progress, url, statusId, dateStarted, dateDeadline, createdAt, updatedAt)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+		INSERT INTO projects (name, description, progress, url, statusId, dateStarted, dateDeadline, createdAt, updatedAt, orgId)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
 		RETURNING id, name, description, progress, url, statusId, dateStarted, dateDeadline, createdAt, updatedAt`,
 		payload.Name,
 		payload.Description,
@@ -468,6 +548,7 @@ func (s *Store) ProjectCreate(payload entities.ProjectCreatePayload) (map[string
 		deadline,
 		time.Now(),
 		time.Now(),
+		payload.OrgID,
 	).Scan(
 		&proj.ID,
 		&proj.Name,
@@ -491,7 +572,7 @@ func (s *Store) ProjectCreate(payload entities.ProjectCreatePayload) (map[string
 		return nil, fmt.Errorf("failed to create project, project ID is invalid")
 	}
 
-	if len(*payload.UserIDs) > 0 {
+	if payload.UserIDs != nil {
 		for _, userID := range *payload.UserIDs {
 			_, err = tx.Exec(`
 				INSERT INTO users_projects (user_id, project_id)
@@ -504,6 +585,14 @@ func (s *Store) ProjectCreate(payload entities.ProjectCreatePayload) (map[string
 		}
 	}
 
+	// The creator owns the project
+	if payload.CreatedBy != nil {
+		if err = members.AddOwner(tx, proj.ID, *payload.CreatedBy); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if payload.SegmentID != nil {
 		_, err = tx.Exec(`
 		INSERT INTO segments_projects (segmentId, projectId, deletedAt, deletedBy)
@@ -522,6 +611,16 @@ func (s *Store) ProjectCreate(payload entities.ProjectCreatePayload) (map[string
 		return nil, err
 	}
 
+	if err = webhooks.Enqueue(tx, payload.OrgID, entities.WebhookEventProjectCreated, proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = outbox.Record(tx, payload.OrgID, entities.AggregateProject, proj.ID, "created", proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -543,6 +642,17 @@ func (s *Store) ProjectUpdate(projId int, payload entities.ProjectUpdatePayload,
 		dateDeadline = &payload.DateDeadline
 	}
 
+	if err = checkReferences(tx, payload.OrgID, payload.StatusID, payload.SegmentID, &userIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var oldStatusId *int
+	if err = tx.QueryRow(`SELECT statusId FROM projects WHERE id = $1 FOR UPDATE`, projId).Scan(&oldStatusId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to retrieve project status: %v", err)
+	}
+
 	updateQuery := `
 		UPDATE projects
 		SET name = $1, description = $2, progress = $3, url = $4, dateStarted = $5, dateDeadline = $6, statusId = $7, updatedAt = $8
@@ -565,6 +675,11 @@ func (s *Store) ProjectUpdate(projId int, payload entities.ProjectUpdatePayload,
 		return fmt.Errorf("update error: %v", err)
 	}
 
+	if err = workflows.Enforce(tx, payload.OrgID, entities.WorkflowTargetProject, projId, oldStatusId, payload.StatusID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	deleteQuery := `DELETE FROM segments_projects WHERE projectId = $1 RETURNING projectId, segmentId`
 	rows, err := tx.Query(deleteQuery, projId)
 	if err != nil {
@@ -591,9 +706,11 @@ func (s *Store) ProjectUpdate(projId int, payload entities.ProjectUpdatePayload,
 		return fmt.Errorf("failed to associate segment with project: %v", err)
 	}
 
+	// Owners are managed through the members endpoints and kept here; the roles
+	// of users who stay are preserved
 	_, err = tx.Exec(`
-		DELETE FROM users_projects WHERE project_id = $1
-	`, projId)
+		DELETE FROM users_projects WHERE project_id = $1 AND NOT (user_id = ANY($2)) AND role <> $3
+	`, projId, pq.Array(userIDs), entities.ProjectRoleOwner)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete old user-project associations: %v", err)
@@ -603,6 +720,7 @@ func (s *Store) ProjectUpdate(projId int, payload entities.ProjectUpdatePayload,
 		_, err = tx.Exec(`
 			INSERT INTO users_projects (user_id, project_id)
 			VALUES ($1, $2)
+			ON CONFLICT (user_id, project_id) DO UPDATE SET deletedAt = NULL, deletedBy = NULL
 		`, userID, projId)
 		if err != nil {
 			tx.Rollback()
@@ -610,6 +728,17 @@ func (s *Store) ProjectUpdate(projId int, payload entities.ProjectUpdatePayload,
 		}
 	}
 
+	err = webhooks.Enqueue(tx, payload.OrgID, entities.WebhookEventProjectUpdated, map[string]interface{}{"id": projId, "project": payload, "userIds": userIDs})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = outbox.Record(tx, payload.OrgID, entities.AggregateProject, projId, "updated", map[string]interface{}{"id": projId, "project": payload, "userIds": userIDs}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %v", err)
 	}
@@ -624,13 +753,15 @@ func (s *Store) ProjectDelete(id int) (*entities.Project, error) {
 	}
 	println("projectId: ", id)
 	proj := entities.Project{}
-	err = tx.QueryRow("UPDATE projects SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, name, description, createdAt, updatedAt, deletedAt", id).Scan(
+	var orgId int
+	err = tx.QueryRow("UPDATE projects SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 RETURNING id, name, description, createdAt, updatedAt, deletedAt, orgId", id).Scan(
 		&proj.ID,
 		&proj.Name,
 		&proj.Description,
 		&proj.CreatedAt,
 		&proj.UpdatedAt,
 		&proj.DeletedAt,
+		&orgId,
 	)
 
 	if err != nil {
@@ -640,6 +771,16 @@ func (s *Store) ProjectDelete(id int) (*entities.Project, error) {
 		return nil, err
 	}
 
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectDeleted, proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateProject, proj.ID, "deleted", proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -654,13 +795,15 @@ func (s *Store) ProjectRestore(id int) (*entities.Project, error) {
 	}
 
 	proj := entities.Project{}
-	err = tx.QueryRow("UPDATE projects SET deletedAt = NULL WHERE id = $1 RETURNING id, name, description, createdAt, updatedAt, deletedAt", id).Scan(
+	var orgId int
+	err = tx.QueryRow("UPDATE projects SET deletedAt = NULL WHERE id = $1 RETURNING id, name, description, createdAt, updatedAt, deletedAt, orgId", id).Scan(
 		&proj.ID,
 		&proj.Name,
 		&proj.Description,
 		&proj.CreatedAt,
 		&proj.UpdatedAt,
 		&proj.DeletedAt,
+		&orgId,
 	)
 	if err != nil {
 		if rollbackErr := tx.Rollback(); rollbackErr != nil {
@@ -669,6 +812,16 @@ func (s *Store) ProjectRestore(id int) (*entities.Project, error) {
 		return nil, err
 	}
 
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectRestored, proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateProject, proj.ID, "restored", proj); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return &proj, nil
 	}
@@ -676,6 +829,100 @@ func (s *Store) ProjectRestore(id int) (*entities.Project, error) {
 	return &proj, nil
 }
 
+// ProjectArchive hides a finished project from the default listings. Its data
+// stays readable, but its tasks and workspaces become read-only.
+func (s *Store) ProjectArchive(id int, archivedBy *int) (*entities.Project, error) {
+	return s.setArchived(id, true, archivedBy)
+}
+
+func (s *Store) ProjectUnarchive(id int) (*entities.Project, error) {
+	return s.setArchived(id, false, nil)
+}
+
+func (s *Store) setArchived(id int, archived bool, archivedBy *int) (*entities.Project, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var archivedAt *time.Time
+	var orgId int
+	err = tx.QueryRow("SELECT archivedAt, orgId FROM projects WHERE id = $1 AND deletedAt IS NULL FOR UPDATE", id).Scan(&archivedAt, &orgId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("project with ID %d not found", id)
+		}
+		return nil, err
+	}
+	if archived && archivedAt != nil {
+		err = ErrAlreadyArchived
+		return nil, err
+	}
+	if !archived && archivedAt == nil {
+		err = ErrNotArchived
+		return nil, err
+	}
+
+	proj := entities.Project{}
+	err = tx.QueryRow(`
+		UPDATE projects
+		SET archivedAt = CASE WHEN $1 THEN CURRENT_TIMESTAMP END, archivedBy = $2, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING id, name, description, createdAt, updatedAt, archivedAt, archivedBy
+	`, archived, archivedBy, id).Scan(
+		&proj.ID,
+		&proj.Name,
+		&proj.Description,
+		&proj.CreatedAt,
+		&proj.UpdatedAt,
+		&proj.ArchivedAt,
+		&proj.ArchivedBy,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project: %v", err)
+	}
+
+	event, action := entities.WebhookEventProjectUnarchived, "unarchived"
+	if archived {
+		event, action = entities.WebhookEventProjectArchived, "archived"
+	}
+	if err = webhooks.Enqueue(tx, orgId, event, proj); err != nil {
+		return nil, err
+	}
+	if err = outbox.Record(tx, orgId, entities.AggregateProject, proj.ID, action, proj); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &proj, nil
+}
+
+// checkReferences makes sure a project only points at the status, segment and
+// users of its own organization
+func checkReferences(tx *sql.Tx, orgId int, statusId, segmentId *int, userIDs *[]int) error {
+	if statusId != nil && *statusId != 0 {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantStatuses, []int{*statusId}); err != nil {
+			return err
+		}
+	}
+	if segmentId != nil && *segmentId != 0 {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantSegments, []int{*segmentId}); err != nil {
+			return err
+		}
+	}
+	if userIDs != nil {
+		return organizations.CheckOwned(tx, orgId, entities.TenantUsers, *userIDs)
+	}
+	return nil
+}
+
 func scanRowIntoProject(rows *sql.Rows, proj *entities.Project) error {
 	return rows.Scan(
 		&proj.ID,
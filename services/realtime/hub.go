@@ -0,0 +1,82 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+// subscriberBuffer is how many events a slow subscriber may lag behind before
+// events are dropped for it
+const subscriberBuffer = 32
+
+// Hub is the in-process EventBroker keyed by project.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[int]map[chan entities.BoardEvent]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: map[int]map[chan entities.BoardEvent]struct{}{}}
+}
+
+func (h *Hub) Publish(event entities.BoardEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[event.ProjectID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *Hub) Subscribe(projectId int) (<-chan entities.BoardEvent, func()) {
+	ch := make(chan entities.BoardEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[projectId] == nil {
+		h.subscribers[projectId] = map[chan entities.BoardEvent]struct{}{}
+	}
+	h.subscribers[projectId][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[projectId], ch)
+			if len(h.subscribers[projectId]) == 0 {
+				delete(h.subscribers, projectId)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+var (
+	brokerMu sync.RWMutex
+	broker   entities.EventBroker = NewHub()
+)
+
+// SetBroker replaces the broker used by Publish and the stream endpoint.
+func SetBroker(b entities.EventBroker) {
+	brokerMu.Lock()
+	defer brokerMu.Unlock()
+	broker = b
+}
+
+func Broker() entities.EventBroker {
+	brokerMu.RLock()
+	defer brokerMu.RUnlock()
+	return broker
+}
+
+// Publish pushes an event to the subscribers of its project. Stores call it
+// once their transaction is committed.
+func Publish(event entities.BoardEvent) {
+	Broker().Publish(event)
+}
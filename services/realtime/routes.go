@@ -0,0 +1,23 @@
+package realtime
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	router.HandleFunc("/workspaces/{projectId}/events", queryToken(utils.ValidateJWT(h.handleStream))).Methods("GET")
+}
+
+// queryToken accepts the JWT as ?token= since browsers cannot set headers on
+// an EventSource
+func queryToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", utils.BEARER+token)
+		}
+		next(w, r)
+	}
+}
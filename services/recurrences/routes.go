@@ -0,0 +1,14 @@
+package recurrences
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const targetPattern = "{target:tasks|project-tasks}"
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/recurrences/"+targetPattern+"/{taskId}", h.handleGetRecurrence, "GET")
+	utils.SecureRoute(router, "/recurrences/"+targetPattern+"/{taskId}", h.handleSetRecurrence, "PUT")
+	utils.SecureRoute(router, "/recurrences/"+targetPattern+"/{taskId}", h.handleDeleteRecurrence, "DELETE")
+}
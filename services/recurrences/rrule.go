@@ -0,0 +1,129 @@
+package recurrences
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rule is the supported RRULE subset: FREQ (DAILY, WEEKLY or MONTHLY),
+// INTERVAL and an optional end given by either UNTIL or COUNT.
+type rule struct {
+	freq     string
+	interval int
+	until    *time.Time
+	count    int
+}
+
+func parseRule(value string) (*rule, error) {
+	r := &rule{interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq := strings.ToUpper(val)
+			if freq != "DAILY" && freq != "WEEKLY" && freq != "MONTHLY" {
+				return nil, fmt.Errorf("unsupported FREQ %q, expected DAILY, WEEKLY or MONTHLY", val)
+			}
+			r.freq = freq
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number")
+			}
+			r.interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive number")
+			}
+			r.count = count
+		case "UNTIL":
+			until, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			r.until = &until
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("rule is missing FREQ")
+	}
+	if r.count > 0 && r.until != nil {
+		return nil, fmt.Errorf("rule cannot have both COUNT and UNTIL")
+	}
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be formatted as YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+// nth returns the n-th occurrence after anchor, the start of the series.
+// Counting from the anchor keeps the day of a monthly series once a shorter
+// month clamped it.
+func (r *rule) nth(anchor time.Time, n int) time.Time {
+	switch r.freq {
+	case "DAILY":
+		return anchor.AddDate(0, 0, n*r.interval)
+	case "WEEKLY":
+		return anchor.AddDate(0, 0, 7*n*r.interval)
+	default:
+		return addMonths(anchor, n*r.interval)
+	}
+}
+
+// after returns the first occurrence of the series started at anchor that
+// falls after t
+func (r *rule) after(anchor, t time.Time) time.Time {
+	n := 1
+	next := r.nth(anchor, n)
+	for !next.After(t) {
+		n++
+		next = r.nth(anchor, n)
+	}
+	return next
+}
+
+// ended reports whether an occurrence at t would be past the end of the rule,
+// given how many occurrences already exist.
+func (r *rule) ended(t time.Time, occurrences int) bool {
+	if r.count > 0 && occurrences >= r.count {
+		return true
+	}
+	return r.until != nil && t.After(*r.until)
+}
+
+// addMonths keeps the day of month, clamped to the length of the target month
+// so that Jan 31 is followed by Feb 28 instead of Mar 3.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,29 @@
+package recurrences
+
+import (
+	"log"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// StartScheduler materializes due occurrences every interval in the background.
+func StartScheduler(store entities.RecurrenceStore, interval time.Duration) {
+	interval = utils.PositiveDuration("recurrence interval", interval, config.DefaultRecurrenceIntervalSeconds*time.Second)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			created, err := store.MaterializeDue(time.Now())
+			if err != nil {
+				log.Printf("Recurrence scheduler: %v", err)
+			} else if created > 0 {
+				log.Printf("Recurrence scheduler: created %d occurrence(s)", created)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,101 @@
+package recurrences
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.RecurrenceStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.RecurrenceStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	recurrence, err := h.store.GetRecurrence(vars["target"], taskId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": recurrence})
+}
+
+func (h *Handler) handleSetRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	payload := entities.RecurrencePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if _, err := parseRule(payload.Rule); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid rule: %v", err))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleMember) {
+		return
+	}
+
+	recurrence, err := h.store.SetRecurrence(vars["target"], taskId, payload.Rule)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": recurrence})
+}
+
+func (h *Handler) handleDeleteRecurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	taskId, err := strconv.Atoi(vars["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, vars["target"], taskId, entities.ProjectRoleMember) {
+		return
+	}
+
+	if err := h.store.DeleteRecurrence(vars["target"], taskId); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Recurrence Successfully!"})
+}
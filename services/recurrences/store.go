@@ -0,0 +1,356 @@
+package recurrences
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/tasks"
+	"github.com/norrico31/it210-core-service-backend/services/tasksproject"
+)
+
+type Store struct {
+	db    *sql.DB
+	tasks *tasks.Store
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, tasks: tasks.NewStore(db)}
+}
+
+// recurrenceTarget describes how occurrences of a task kind are stored and copied
+type recurrenceTarget struct {
+	table       string
+	column      string
+	labelTarget string
+	fieldTarget string
+	// project is the project of a task row aliased t
+	project string
+	// create adds a copy of task taskId due on due through the create path of
+	// its kind and returns its id
+	create func(tx *sql.Tx, taskId int, due time.Time) (int, error)
+}
+
+var recurrenceTargets = map[string]recurrenceTarget{
+	entities.RecurrenceTargetTask: {
+		table:       "tasks",
+		column:      "taskId",
+		labelTarget: entities.LabelTargetTask,
+		fieldTarget: entities.CustomFieldTargetTask,
+		project:     "(SELECT projectId FROM workspaces WHERE id = t.workspaceId)",
+		create:      createTask,
+	},
+	entities.RecurrenceTargetProjectTask: {
+		table:       "project_tasks",
+		column:      "projectTaskId",
+		labelTarget: entities.LabelTargetProjectTask,
+		fieldTarget: entities.CustomFieldTargetProjectTask,
+		project:     "t.projectId",
+		create:      createProjectTask,
+	},
+}
+
+const recurrenceColumns = "id, taskId, projectTaskId, rule, occurrences, endedAt, createdAt, updatedAt"
+
+func scanRecurrence(scanner interface{ Scan(...interface{}) error }, recurrence *entities.Recurrence) error {
+	return scanner.Scan(
+		&recurrence.ID, &recurrence.TaskID, &recurrence.ProjectTaskID, &recurrence.Rule, &recurrence.Occurrences,
+		&recurrence.EndedAt, &recurrence.CreatedAt, &recurrence.UpdatedAt,
+	)
+}
+
+func (s *Store) GetRecurrence(target string, taskId int) (*entities.Recurrence, error) {
+	t, ok := recurrenceTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid recurrence target %s", target)
+	}
+
+	recurrence := entities.Recurrence{}
+	row := s.db.QueryRow(fmt.Sprintf("SELECT %s FROM task_recurrences WHERE %s = $1", recurrenceColumns, t.column), taskId)
+	if err := scanRecurrence(row, &recurrence); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with ID %d does not recur", taskId)
+		}
+		return nil, fmt.Errorf("failed to retrieve recurrence: %v", err)
+	}
+	return &recurrence, nil
+}
+
+func (s *Store) SetRecurrence(target string, taskId int, value string) (*entities.Recurrence, error) {
+	t, ok := recurrenceTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("invalid recurrence target %s", target)
+	}
+
+	var exists bool
+	err := s.db.QueryRow(fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1 AND deletedAt IS NULL)", t.table), taskId).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check task: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("task with ID %d not found", taskId)
+	}
+
+	// Changing the rule starts the series again from the current occurrence,
+	// and an ended recurrence with it
+	recurrence := entities.Recurrence{}
+	row := s.db.QueryRow(fmt.Sprintf(`
+		INSERT INTO task_recurrences (%s, rule)
+		VALUES ($1, $2)
+		ON CONFLICT (%s) WHERE %s IS NOT NULL
+		DO UPDATE SET rule = EXCLUDED.rule, endedAt = NULL, anchorAt = NULL, updatedAt = CURRENT_TIMESTAMP
+		RETURNING %s
+	`, t.column, t.column, t.column, recurrenceColumns), taskId, value)
+	if err := scanRecurrence(row, &recurrence); err != nil {
+		return nil, fmt.Errorf("failed to save recurrence: %v", err)
+	}
+	return &recurrence, nil
+}
+
+func (s *Store) DeleteRecurrence(target string, taskId int) error {
+	t, ok := recurrenceTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid recurrence target %s", target)
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM task_recurrences WHERE %s = $1", t.column), taskId)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurrence: %v", err)
+	}
+	return nil
+}
+
+// MaterializeDue creates the next occurrence of every recurring task whose
+// current occurrence was completed or reached its due date, and returns how
+// many were created. Series of archived projects wait until they are unarchived.
+func (s *Store) MaterializeDue(now time.Time) (int, error) {
+	created := 0
+	for target, t := range recurrenceTargets {
+		rows, err := s.db.Query(fmt.Sprintf(`
+			SELECT r.id
+			FROM task_recurrences r
+			JOIN %s t ON t.id = r.%s
+			WHERE r.endedAt IS NULL
+				AND (t.completedAt IS NOT NULL OR t.dueDate <= $1 OR t.deletedAt IS NOT NULL)
+				AND %s
+		`, t.table, t.column, members.ActiveCondition(t.project)), now)
+		if err != nil {
+			return created, fmt.Errorf("failed to query due recurrences: %v", err)
+		}
+
+		ids := []int{}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return created, fmt.Errorf("failed to scan recurrence: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+
+		for _, id := range ids {
+			ok, err := s.materialize(target, t, id, now)
+			if err != nil {
+				return created, fmt.Errorf("recurrence %d: %v", id, err)
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
+
+// materialize advances a single recurrence in its own transaction. The row
+// lock makes concurrent schedulers skip recurrences another one is handling.
+func (s *Store) materialize(target string, t recurrenceTarget, id int, now time.Time) (created bool, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var taskId, occurrences int
+	var value string
+	var anchorAt, dueDate, completedAt, deletedAt *time.Time
+	var createdAt time.Time
+	err = tx.QueryRow(fmt.Sprintf(`
+		SELECT r.%s, r.rule, r.occurrences, r.anchorAt, t.dueDate, t.completedAt, t.deletedAt, t.createdAt
+		FROM task_recurrences r
+		JOIN %s t ON t.id = r.%s
+		WHERE r.id = $1 AND r.endedAt IS NULL AND %s
+		FOR UPDATE OF r SKIP LOCKED
+	`, t.column, t.table, t.column, members.ActiveCondition(t.project)), id).Scan(&taskId, &value, &occurrences, &anchorAt, &dueDate, &completedAt, &deletedAt, &createdAt)
+	if err == sql.ErrNoRows {
+		// Already handled, locked by another scheduler or archived since
+		err = nil
+		return false, tx.Rollback()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	due := dueDate != nil && !dueDate.After(now)
+	if completedAt == nil && !due && deletedAt == nil {
+		return false, tx.Rollback()
+	}
+
+	r, err := parseRule(value)
+	if deletedAt != nil || err != nil {
+		// Deleting the current occurrence, or a rule that no longer parses, stops the series
+		err = endRecurrence(tx, id)
+		if err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	base := createdAt
+	if dueDate != nil {
+		base = *dueDate
+	}
+	// The current occurrence starts the series until one was materialized
+	if anchorAt == nil {
+		anchorAt = &base
+	}
+	// Skip occurrences that were missed while nothing was running
+	after := base
+	if now.After(after) {
+		after = now
+	}
+	next := r.after(*anchorAt, after)
+
+	if r.ended(next, occurrences) {
+		err = endRecurrence(tx, id)
+		if err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+
+	var newTaskId int
+	newTaskId, err = t.create(tx, taskId, next)
+	if err != nil {
+		return false, fmt.Errorf("failed to create occurrence: %v", err)
+	}
+	if err = labels.CopyLabels(tx, t.labelTarget, taskId, newTaskId); err != nil {
+		return false, err
+	}
+	if err = customfields.CopyValues(tx, t.fieldTarget, taskId, newTaskId); err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+		UPDATE task_recurrences
+		SET %s = $1, occurrences = occurrences + 1, anchorAt = $3, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, t.column), newTaskId, id, *anchorAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance recurrence: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+
+	if target == entities.RecurrenceTargetTask {
+		s.tasks.PublishTask(entities.EventTaskCreated, newTaskId)
+	}
+	return true, nil
+}
+
+func endRecurrence(tx *sql.Tx, id int) error {
+	_, err := tx.Exec("UPDATE task_recurrences SET endedAt = CURRENT_TIMESTAMP, updatedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to end recurrence: %v", err)
+	}
+	return nil
+}
+
+// createTask copies a board task into the first column of its board
+func createTask(tx *sql.Tx, taskId int, due time.Time) (int, error) {
+	var projectId int
+	var userId *int
+	payload := entities.TaskCreatePayload{}
+	err := tx.QueryRow(`
+		SELECT t.title, t.description, t.userId, t.priorityId, w.projectId,
+			COALESCE((
+				SELECT first.id FROM workspaces first
+				WHERE first.projectId = w.projectId AND first.deletedAt IS NULL
+				ORDER BY first.colOrder LIMIT 1
+			), t.workspaceId),
+			t.storyPoints, t.estimateHours
+		FROM tasks t
+		JOIN workspaces w ON w.id = t.workspaceId
+		WHERE t.id = $1
+	`, taskId).Scan(&payload.Title, &payload.Description, &userId, &payload.PriorityID, &projectId, &payload.WorkspaceID, &payload.StoryPoints, &payload.EstimateHours)
+	if err != nil {
+		return 0, err
+	}
+	if userId != nil {
+		payload.UserID = *userId
+	}
+	if payload.StatusID, err = openStatus(tx, projectId); err != nil {
+		return 0, err
+	}
+
+	task, err := tasks.CreateTask(tx, payload, &due)
+	if err != nil {
+		return 0, err
+	}
+	return task.ID, nil
+}
+
+// createProjectTask copies a project task
+func createProjectTask(tx *sql.Tx, taskId int, due time.Time) (int, error) {
+	var userId *int
+	payload := entities.TasksProjectCreatePayload{}
+	err := tx.QueryRow(`
+		SELECT name, description, userId, priorityId, projectId, storyPoints, estimateHours
+		FROM project_tasks
+		WHERE id = $1
+	`, taskId).Scan(&payload.Name, &payload.Description, &userId, &payload.PriorityID, &payload.ProjectID, &payload.StoryPoints, &payload.EstimateHours)
+	if err != nil {
+		return 0, err
+	}
+	if userId != nil {
+		payload.UserID = *userId
+	}
+	if payload.StatusID, err = openStatus(tx, payload.ProjectID); err != nil {
+		return 0, err
+	}
+
+	tasksProject, err := tasksproject.CreateTaskProject(tx, payload, &due)
+	if err != nil {
+		return 0, err
+	}
+	return tasksProject.ID, nil
+}
+
+// openStatus returns the first status of the organization of project projectId
+// that is not started, in which new occurrences begin, or nil when it has none
+func openStatus(tx *sql.Tx, projectId int) (*int, error) {
+	var statusId int
+	err := tx.QueryRow(`
+		SELECT s.id FROM statuses s
+		JOIN projects p ON p.orgId = s.orgId
+		WHERE p.id = $1 AND s.category = 'todo' AND s.deletedAt IS NULL
+		ORDER BY s.id LIMIT 1
+	`, projectId).Scan(&statusId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find open status: %v", err)
+	}
+	return &statusId, nil
+}
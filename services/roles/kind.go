@@ -0,0 +1,69 @@
+package roles
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/lookups"
+)
+
+var ErrLastAdmin = fmt.Errorf("%w: an organization needs at least one admin", lookups.ErrRefused)
+
+var Kind = lookups.Kind[entities.Role, entities.RolePayload]{
+	Table:   "roles",
+	Label:   "role",
+	Select:  []string{"name", "description", "isAdmin"},
+	Columns: []string{"name", "description"}, // isAdmin cannot be set through the API
+	OrderBy: "createdAt DESC",
+	References: []lookups.Reference{
+		{Name: "users", Table: "users", Column: "roleId", Live: "deletedAt IS NULL"},
+	},
+	CheckDelete: checkLastAdmin,
+	Fields: func(role *entities.Role) []interface{} {
+		return []interface{}{&role.ID, &role.Name, &role.Description, &role.IsAdmin, &role.CreatedAt, &role.UpdatedAt}
+	},
+	Values: func(payload entities.RolePayload) []interface{} {
+		return []interface{}{payload.Name, payload.Description}
+	},
+	Name: func(payload entities.RolePayload) string { return payload.Name },
+	Merge: func(role entities.Role, payload entities.RolePayload) entities.RolePayload {
+		if payload.Name == "" {
+			payload.Name = role.Name
+		}
+		if payload.Description == "" {
+			payload.Description = role.Description
+		}
+		return payload
+	},
+}
+
+// checkLastAdmin refuses to delete an admin role whose users are the last
+// admins of the organization, unless their replacement is an admin role too
+func checkLastAdmin(tx *sql.Tx, orgId, id int, replaceWith *int) error {
+	// Lock the admin roles so that concurrent deletes see each other
+	_, err := tx.Exec(`SELECT id FROM roles WHERE orgId = $1 AND isAdmin AND deletedAt IS NULL FOR UPDATE`, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to lock admin roles: %v", err)
+	}
+
+	var last bool
+	err = tx.QueryRow(`
+		SELECT r.isAdmin
+			AND EXISTS (SELECT 1 FROM users WHERE roleId = r.id AND deletedAt IS NULL)
+			AND NOT EXISTS (
+				SELECT 1 FROM users u JOIN roles other ON other.id = u.roleId
+				WHERE other.orgId = r.orgId AND other.id <> r.id AND other.isAdmin AND other.deletedAt IS NULL AND u.deletedAt IS NULL
+			)
+			AND NOT COALESCE((SELECT isAdmin FROM roles WHERE id = $2 AND orgId = r.orgId AND deletedAt IS NULL), FALSE)
+		FROM roles r
+		WHERE r.id = $1
+	`, id, replaceWith).Scan(&last)
+	if err != nil {
+		return fmt.Errorf("failed to check admins: %v", err)
+	}
+	if last {
+		return fmt.Errorf("role with ID %d %w", id, ErrLastAdmin)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+type Handler struct {
+	store  entities.SearchStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.SearchStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing search query"))
+		return
+	}
+
+	var types []string
+	if str := r.URL.Query().Get("types"); str != "" {
+		for _, kind := range strings.Split(str, ",") {
+			kind = strings.TrimSpace(kind)
+			if !slices.Contains(entities.SearchTypes, kind) {
+				utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid search type %q, expected one of %v", kind, entities.SearchTypes))
+				return
+			}
+			types = append(types, kind)
+		}
+	}
+
+	limit := defaultLimit
+	if str := r.URL.Query().Get("limit"); str != "" {
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 1 || n > maxLimit {
+			utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid limit, expected 1 to %d", maxLimit))
+			return
+		}
+		limit = n
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	results, err := h.store.Search(entities.SearchFilter{
+		Query:    query,
+		OrgID:    utils.GetOrgID(r),
+		MemberID: memberId,
+		Types:    types,
+		Limit:    limit,
+	})
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": results})
+}
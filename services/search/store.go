@@ -0,0 +1,129 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+)
+
+// Every source selects the same columns so they can be ranked together. The
+// query is $1, the organization $2 and the member, or NULL for admins, $3.
+// Projects, tasks and project tasks are stemmed as English, people's names
+// and emails are not.
+var sources = map[string]string{
+	entities.SearchTypeProject: `
+		SELECT 'project', p.id, p.id, p.name, COALESCE(p.description, ''),
+			p.archivedAt IS NOT NULL, 'english'::regconfig,
+			ts_rank(p.searchVector, websearch_to_tsquery('english', $1))
+		FROM projects p
+		WHERE p.searchVector @@ websearch_to_tsquery('english', $1)
+			AND p.deletedAt IS NULL AND p.orgId = $2
+			AND ($3::INT IS NULL OR ` + members.MemberCondition("p.id", "$3") + `)`,
+	entities.SearchTypeTask: `
+		SELECT 'task', t.id, p.id, t.title, COALESCE(t.description, ''),
+			p.archivedAt IS NOT NULL, 'english'::regconfig,
+			ts_rank(t.searchVector, websearch_to_tsquery('english', $1))
+		FROM tasks t
+		JOIN workspaces w ON w.id = t.workspaceId AND w.deletedAt IS NULL
+		JOIN projects p ON p.id = w.projectId AND p.deletedAt IS NULL
+		WHERE t.searchVector @@ websearch_to_tsquery('english', $1)
+			AND t.deletedAt IS NULL AND p.orgId = $2
+			AND ($3::INT IS NULL OR ` + members.MemberCondition("p.id", "$3") + `)`,
+	entities.SearchTypeProjectTask: `
+		SELECT 'projectTask', pt.id, p.id, pt.name, COALESCE(pt.description, ''),
+			p.archivedAt IS NOT NULL, 'english'::regconfig,
+			ts_rank(pt.searchVector, websearch_to_tsquery('english', $1))
+		FROM project_tasks pt
+		JOIN projects p ON p.id = pt.projectId AND p.deletedAt IS NULL
+		WHERE pt.searchVector @@ websearch_to_tsquery('english', $1)
+			AND pt.deletedAt IS NULL AND p.orgId = $2
+			AND ($3::INT IS NULL OR ` + members.MemberCondition("p.id", "$3") + `)`,
+	// Members find themselves and the people they share a project with
+	entities.SearchTypeUser: `
+		SELECT 'user', u.id, NULL::INT, u.firstName || ' ' || u.lastName, u.email,
+			FALSE, 'simple'::regconfig,
+			ts_rank(u.searchVector, websearch_to_tsquery('simple', $1))
+		FROM users u
+		WHERE u.searchVector @@ websearch_to_tsquery('simple', $1)
+			AND u.deletedAt IS NULL AND u.orgId = $2
+			AND ($3::INT IS NULL OR u.id = $3 OR EXISTS (
+				SELECT 1 FROM users_projects up
+				WHERE up.user_id = u.id AND up.deletedAt IS NULL AND ` + members.MemberCondition("up.project_id", "$3") + `
+			))`,
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Search ranks the matches of every requested source together and only
+// highlights the ones that make the page.
+func (s *Store) Search(filter entities.SearchFilter) ([]entities.SearchResult, error) {
+	types := filter.Types
+	if len(types) == 0 {
+		types = entities.SearchTypes
+	}
+
+	parts := make([]string, 0, len(types))
+	for _, kind := range types {
+		source, ok := sources[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown search type %q", kind)
+		}
+		parts = append(parts, source)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT type, id, projectId, title,
+			ts_headline(config, `+escapeHTML("title")+`, websearch_to_tsquery(config, $1), 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+			ts_headline(config, `+escapeHTML("body")+`, websearch_to_tsquery(config, $1), 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2'),
+			archived, rank
+		FROM (
+			SELECT * FROM (
+				`+strings.Join(parts, "\n\t\t\t\tUNION ALL\n")+`
+			) ranked (type, id, projectId, title, body, archived, config, rank)
+			ORDER BY rank DESC, type, id
+			LIMIT $4
+		) matches
+		ORDER BY rank DESC, type, id
+	`, filter.Query, filter.OrgID, filter.MemberID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	defer rows.Close()
+
+	results := []entities.SearchResult{}
+	for rows.Next() {
+		result := entities.SearchResult{}
+		err := rows.Scan(
+			&result.Type, &result.ID, &result.ProjectID, &result.Title, &result.TitleHighlight, &result.Snippet,
+			&result.Archived, &result.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over search results: %v", err)
+	}
+	return results, nil
+}
+
+// escapeHTML is a SQL expression escaping the text in column, so that the
+// <mark> tags are the only markup of a highlight. The text search parser
+// skips the entities, so they never match a query.
+func escapeHTML(column string) string {
+	return fmt.Sprintf(
+		`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`,
+		column,
+	)
+}
@@ -8,9 +8,12 @@ import (
 func RegisterRoutes(router *mux.Router, h *Handler) {
 	utils.SecureRoute(router, "/segments", h.handleGetSegments, "GET")
 	utils.SecureRoute(router, "/segments", h.handleCreateSegment, "POST")
+	utils.SecureRoute(router, "/segments/tree", h.handleGetSegmentTree, "GET")
 	utils.SecureRoute(router, "/segments/{segmentId}", h.handleGetSegment, "GET")
 	utils.SecureRoute(router, "/segments/{segmentId}", h.handleUpdateSegment, "PUT")
 	utils.SecureRoute(router, "/segments/{segmentId}/restore", h.handleRestoreSegment, "PUT")
+	utils.SecureRoute(router, "/segments/{segmentId}/tree", h.handleGetSegmentSubtree, "GET")
+	utils.SecureRoute(router, "/segments/{segmentId}/parent", h.handleMoveSegment, "PUT")
 	utils.SecureRoute(router, "/segments/{segmentId}", h.handleDeleteSegment, "DELETE")
 
 }
@@ -1,26 +1,31 @@
 package segments
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 
-	"github.com/go-playground/validator"
+	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
+// Handler lets every user read the segments of their organization; only
+// admins change them.
 type Handler struct {
-	store entities.SegmentsStore
+	store  entities.SegmentsStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.SegmentsStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.SegmentsStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
 }
 
 func (h *Handler) handleGetSegments(w http.ResponseWriter, r *http.Request) {
-	segments, err := h.store.GetSegments()
+	segments, err := h.store.GetSegments(utils.GetOrgID(r))
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -45,7 +50,7 @@ func (h *Handler) handleGetSegment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	segment, err := h.store.GetSegment(segmentId)
+	segment, err := h.store.GetSegment(utils.GetOrgID(r), segmentId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -54,6 +59,10 @@ func (h *Handler) handleGetSegment(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleCreateSegment(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
 	payload := entities.SegmentPayload{}
 
 	if err := utils.ParseJSON(r, &payload); err != nil {
@@ -62,17 +71,18 @@ func (h *Handler) handleCreateSegment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := utils.Validate.Struct(payload); err != nil {
-		errors := err.(validator.ValidationErrors)
-		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errors))
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
 		return
 	}
 
 	payload = entities.SegmentPayload{
 		Name:        payload.Name,
 		Description: payload.Description,
+		ParentID:    payload.ParentID,
 		ProjectIDs:  payload.ProjectIDs,
 	}
-	segment, err := h.store.CreateSegment(payload)
+	segment, err := h.store.CreateSegment(utils.GetOrgID(r), payload)
 
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
@@ -95,6 +105,9 @@ func (h *Handler) handleUpdateSegment(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid segment ID"))
 		return
 	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
 	var payload entities.SegmentPayload
 
 	if err := utils.ParseJSON(r, &payload); err != nil {
@@ -102,7 +115,7 @@ func (h *Handler) handleUpdateSegment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	segment, err := h.store.GetSegment(segmentId)
+	segment, err := h.store.GetSegment(utils.GetOrgID(r), segmentId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -116,18 +129,11 @@ func (h *Handler) handleUpdateSegment(w http.ResponseWriter, r *http.Request) {
 		segment.Description = payload.Description
 	}
 
-	var projectIds = []int{}
-
-	// TODO: STILL BUG
-	for _, id := range *payload.ProjectIDs {
-		projectIds = append(projectIds, id)
-	}
-
-	err = h.store.UpdateSegment(entities.SegmentPayload{
+	err = h.store.UpdateSegment(utils.GetOrgID(r), entities.SegmentPayload{
 		ID:          segment.ID,
 		Name:        segment.Name,
 		Description: segment.Description,
-		ProjectIDs:  &projectIds,
+		ProjectIDs:  payload.ProjectIDs,
 	})
 
 	if err != nil {
@@ -151,14 +157,21 @@ func (h *Handler) handleDeleteSegment(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
 		return
 	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
 
-	existingSegment, err := h.store.GetSegment(segmentId)
+	existingSegment, err := h.store.GetSegment(utils.GetOrgID(r), segmentId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	err = h.store.DeleteSegment(existingSegment.ID)
+	err = h.store.DeleteSegment(utils.GetOrgID(r), existingSegment.ID)
+	if errors.Is(err, ErrSegmentHasChildren) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -180,14 +193,17 @@ func (h *Handler) handleRestoreSegment(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
 		return
 	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
 
-	existingSegment, err := h.store.GetSegment(segmentId)
+	existingSegment, err := h.store.GetSegment(utils.GetOrgID(r), segmentId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	err = h.store.RestoreSegment(existingSegment.ID)
+	err = h.store.RestoreSegment(utils.GetOrgID(r), existingSegment.ID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -195,3 +211,72 @@ func (h *Handler) handleRestoreSegment(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusNoContent, nil)
 }
+
+func (h *Handler) handleGetSegmentTree(w http.ResponseWriter, r *http.Request) {
+	segments, err := h.store.GetSegmentTree(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": segments})
+}
+
+func (h *Handler) handleGetSegmentSubtree(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["segmentId"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing segment ID"))
+		return
+	}
+
+	segmentId, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid segment ID"))
+		return
+	}
+
+	segment, err := h.store.GetSegmentSubtree(utils.GetOrgID(r), segmentId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": segment})
+}
+
+func (h *Handler) handleMoveSegment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	str, ok := vars["segmentId"]
+	if !ok {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("missing segment ID"))
+		return
+	}
+
+	segmentId, err := strconv.Atoi(str)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid segment ID"))
+		return
+	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	payload := entities.SegmentMovePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.store.MoveSegment(utils.GetOrgID(r), segmentId, payload.ParentID)
+	if errors.Is(err, ErrSegmentCycle) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Move Segment Successfully!"})
+}
@@ -17,10 +17,11 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetSegments() ([]entities.Segment, error) {
+func (s *Store) GetSegments(orgId int) ([]entities.Segment, error) {
 	rows, err := s.db.Query(`
 		SELECT 
 			seg.id AS segment_id, 
+			seg.parentId AS segment_parent_id,
 			seg.name AS segment_name, 
 			seg.description AS segment_description,
 			seg.createdAt AS segment_createdAt,
@@ -39,9 +40,9 @@ func (s *Store) GetSegments() ([]entities.Segment, error) {
 		FROM segments seg
 		LEFT JOIN segments_projects sp ON seg.id = sp.segmentId
 		LEFT JOIN projects p ON sp.projectId = p.id
-		WHERE seg.deletedAt IS NULL
+		WHERE seg.deletedAt IS NULL AND seg.orgId = $1
 		ORDER BY seg.id, p.createdAt DESC
-	`)
+	`, orgId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query segments and projects: %v", err)
 	}
@@ -58,7 +59,7 @@ func (s *Store) GetSegments() ([]entities.Segment, error) {
 		var projectCreatedAt, projectUpdatedAt, projectDeletedAt *time.Time
 
 		err := rows.Scan(
-			&segment.ID, &segment.Name, &segment.Description, &segment.CreatedAt, &segment.UpdatedAt, &segment.DeletedAt,
+			&segment.ID, &segment.ParentID, &segment.Name, &segment.Description, &segment.CreatedAt, &segment.UpdatedAt, &segment.DeletedAt,
 			&projectID, &projectName, &projectDescription, &project.Progress, &project.Url,
 			&project.DateStarted, &project.DateDeadline, &projectCreatedAt, &projectUpdatedAt, &projectDeletedAt,
 		)
@@ -104,10 +105,11 @@ func (s *Store) GetSegments() ([]entities.Segment, error) {
 	return result, nil
 }
 
-func (s *Store) GetSegment(id int) (*entities.Segment, error) {
+func (s *Store) GetSegment(orgId, id int) (*entities.Segment, error) {
 	rows, err := s.db.Query(`
 		SELECT 
 			seg.id AS segment_id, 
+			seg.parentId AS segment_parent_id,
 			seg.name AS segment_name, 
 			seg.description AS segment_description,
 			seg.createdAt AS segment_createdAt,
@@ -125,9 +127,9 @@ func (s *Store) GetSegment(id int) (*entities.Segment, error) {
 		FROM segments seg
 		LEFT JOIN segments_projects sp ON seg.id = sp.segmentId
 		LEFT JOIN projects p ON sp.projectId = p.id
-		WHERE seg.id = $1 AND seg.deletedAt IS NULL
+		WHERE seg.id = $1 AND seg.orgId = $2 AND seg.deletedAt IS NULL
 		ORDER BY p.createdAt DESC
-	`, id)
+	`, id, orgId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query segment and projects: %v", err)
 	}
@@ -139,7 +141,7 @@ func (s *Store) GetSegment(id int) (*entities.Segment, error) {
 		var projectID sql.NullInt64
 
 		err := rows.Scan(
-			&segment.ID, &segment.Name, &segment.Description, &segment.CreatedAt, &segment.UpdatedAt, &segment.DeletedAt,
+			&segment.ID, &segment.ParentID, &segment.Name, &segment.Description, &segment.CreatedAt, &segment.UpdatedAt, &segment.DeletedAt,
 			&projectID, &project.Name, &project.Description, &project.Progress, &project.Url,
 			&project.DateStarted, &project.DateDeadline, &project.CreatedAt, &project.UpdatedAt,
 		)
@@ -165,18 +167,27 @@ func (s *Store) GetSegment(id int) (*entities.Segment, error) {
 	return segment, nil
 }
 
-func (s *Store) CreateSegment(payload entities.SegmentPayload) (*entities.Segment, error) {
+func (s *Store) CreateSegment(orgId int, payload entities.SegmentPayload) (*entities.Segment, error) {
 	tx, err := s.db.Begin()
 
 	if err != nil {
 		return nil, err
 	}
 
+	if payload.ParentID != nil {
+		if err = checkParent(tx, orgId, *payload.ParentID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	var segmentID int
 	err = tx.QueryRow(
-		"INSERT INTO segments (name, description) VALUES ($1, $2) RETURNING id",
+		"INSERT INTO segments (name, description, orgId, parentId) VALUES ($1, $2, $3, $4) RETURNING id",
 		payload.Name,
 		payload.Description,
+		orgId,
+		payload.ParentID,
 	).Scan(&segmentID)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -185,12 +196,13 @@ func (s *Store) CreateSegment(payload entities.SegmentPayload) (*entities.Segmen
 		return nil, err
 	}
 
-	if len(*payload.ProjectIDs) > 0 {
+	if payload.ProjectIDs != nil && len(*payload.ProjectIDs) > 0 {
 		for _, projectID := range *payload.ProjectIDs {
 			_, err = tx.Exec(
-				"INSERT INTO segments_projects (segmentId, projectId) VALUES ($1, $2)",
+				"INSERT INTO segments_projects (segmentId, projectId) SELECT $1, id FROM projects WHERE id = $2 AND orgId = $3",
 				segmentID,
 				projectID,
+				orgId,
 			)
 			if err != nil {
 				// Rollback the transaction if association fails
@@ -208,19 +220,20 @@ func (s *Store) CreateSegment(payload entities.SegmentPayload) (*entities.Segmen
 
 	return &entities.Segment{
 		ID:          segmentID,
+		ParentID:    payload.ParentID,
 		Name:        payload.Name,
 		Description: payload.Description,
 	}, nil
 }
 
-func (s *Store) UpdateSegment(payload entities.SegmentPayload) error {
+func (s *Store) UpdateSegment(orgId int, payload entities.SegmentPayload) error {
 	tx, err := s.db.Begin()
 
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE segments SET name = $1, description = $2, updatedAt = CURRENT_TIMESTAMP WHERE id = $3", payload.Name, payload.Description, payload.ID)
+	_, err = tx.Exec("UPDATE segments SET name = $1, description = $2, updatedAt = CURRENT_TIMESTAMP WHERE id = $3 AND orgId = $4", payload.Name, payload.Description, payload.ID, orgId)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("update error: %v, rollback error: %v", err, rbErr)
@@ -228,7 +241,7 @@ func (s *Store) UpdateSegment(payload entities.SegmentPayload) error {
 		return err
 	}
 
-	if len(*payload.ProjectIDs) > 0 {
+	if payload.ProjectIDs != nil && len(*payload.ProjectIDs) > 0 {
 		_, err = tx.Exec(`
 			DELETE FROM segments_projects WHERE segmentId = $1
 		`, payload.ID)
@@ -242,8 +255,8 @@ func (s *Store) UpdateSegment(payload entities.SegmentPayload) error {
 		for _, projectID := range *payload.ProjectIDs {
 			_, err = tx.Exec(`
 				INSERT INTO segments_projects (segmentId, projectId)
-				VALUES ($1, $2)
-			`, payload.ID, projectID)
+				SELECT $1, id FROM projects WHERE id = $2 AND orgId = $3
+			`, payload.ID, projectID, orgId)
 			if err != nil {
 				if rbErr := tx.Rollback(); rbErr != nil {
 					return fmt.Errorf("insert association error: %v, rollback error: %v", err, rbErr)
@@ -260,14 +273,25 @@ func (s *Store) UpdateSegment(payload entities.SegmentPayload) error {
 	return nil
 }
 
-func (s *Store) DeleteSegment(id int) error {
+func (s *Store) DeleteSegment(orgId, id int) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 
+	var hasChildren bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM segments WHERE parentId = $1 AND deletedAt IS NULL)`, id).Scan(&hasChildren)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to check sub-segments: %v", err)
+	}
+	if hasChildren {
+		tx.Rollback()
+		return ErrSegmentHasChildren
+	}
+
 	// Step 1: Mark the segment as deleted
-	_, err = tx.Exec("UPDATE segments SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	_, err = tx.Exec("UPDATE segments SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 AND orgId = $2", id, orgId)
 	if err != nil {
 		// Rollback if updating the segment fails
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -278,8 +302,9 @@ func (s *Store) DeleteSegment(id int) error {
 
 	// Step 2: Mark associated project relationships as deleted in 'segments_projects'
 	_, err = tx.Exec(`
-		UPDATE segments_projects SET deletedAt = CURRENT_TIMESTAMP WHERE segmentId = $1
-	`, id)
+		UPDATE segments_projects SET deletedAt = CURRENT_TIMESTAMP
+		WHERE segmentId = (SELECT id FROM segments WHERE id = $1 AND orgId = $2)
+	`, id, orgId)
 	if err != nil {
 		// Rollback if updating the associations fails
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -296,14 +321,14 @@ func (s *Store) DeleteSegment(id int) error {
 	return nil
 }
 
-func (s *Store) RestoreSegment(id int) error {
+func (s *Store) RestoreSegment(orgId, id int) error {
 	tx, err := s.db.Begin()
 
 	if err != nil {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE segments SET deletedAt = NULL WHERE id = $1", id)
+	_, err = tx.Exec("UPDATE segments SET deletedAt = NULL WHERE id = $1 AND orgId = $2", id, orgId)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
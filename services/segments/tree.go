@@ -0,0 +1,180 @@
+package segments
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+var (
+	ErrSegmentCycle       = errors.New("a segment cannot be moved under itself or one of its sub-segments")
+	ErrSegmentHasChildren = errors.New("the segment still has sub-segments")
+)
+
+// treeLockKey, with the organization as second key, serializes the moves in a
+// segment tree so that two concurrent moves cannot form a cycle together
+const treeLockKey = 7_302_044
+
+// subtreeQuery lists the ids of a segment and every segment below it
+const subtreeQuery = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM segments WHERE id = %[1]s AND deletedAt IS NULL
+		UNION
+		SELECT child.id FROM segments child JOIN subtree ON child.parentId = subtree.id
+		WHERE child.deletedAt IS NULL
+	)
+	SELECT id FROM subtree`
+
+// SubtreeCondition keeps the projects in column that belong to the segment
+// bound to placeholder or to any segment below it
+func SubtreeCondition(column, placeholder string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM segments_projects ssp
+		WHERE ssp.projectId = %s AND ssp.deletedAt IS NULL AND ssp.segmentId IN (%s)
+	)`, column, fmt.Sprintf(subtreeQuery, placeholder))
+}
+
+func (s *Store) GetSegmentTree(orgId int) ([]entities.Segment, error) {
+	segments, err := s.getFlatSegments(orgId)
+	if err != nil {
+		return nil, err
+	}
+	return buildTree(segments, nil), nil
+}
+
+func (s *Store) GetSegmentSubtree(orgId, id int) (*entities.Segment, error) {
+	segments, err := s.getFlatSegments(orgId)
+	if err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		if segment.ID == id {
+			segment.Children = buildTree(segments, &id)
+			return &segment, nil
+		}
+	}
+	return nil, fmt.Errorf("segment not found")
+}
+
+// MoveSegment changes the parent of a segment, refusing moves that would
+// make the segment its own ancestor
+func (s *Store) MoveSegment(orgId, id int, parentId *int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`SELECT pg_advisory_xact_lock($1, $2)`, treeLockKey, orgId); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to lock segment tree: %v", err)
+	}
+
+	var exists bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM segments WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL)`, id, orgId).Scan(&exists)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to retrieve segment: %v", err)
+	}
+	if !exists {
+		tx.Rollback()
+		return fmt.Errorf("segment not found")
+	}
+
+	if parentId != nil {
+		if err = checkParent(tx, orgId, *parentId); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		var cycle bool
+		err = tx.QueryRow(`SELECT $2 IN (`+fmt.Sprintf(subtreeQuery, "$1")+`)`, id, *parentId).Scan(&cycle)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to check segment tree: %v", err)
+		}
+		if cycle {
+			tx.Rollback()
+			return ErrSegmentCycle
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE segments SET parentId = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2 AND orgId = $3`, parentId, id, orgId)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to move segment: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) getFlatSegments(orgId int) ([]entities.Segment, error) {
+	rows, err := s.db.Query(`
+		SELECT id, parentId, name, description, createdAt, updatedAt
+		FROM segments
+		WHERE orgId = $1 AND deletedAt IS NULL
+		ORDER BY name, id
+	`, orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query segments: %v", err)
+	}
+	defer rows.Close()
+
+	segments := []entities.Segment{}
+	for rows.Next() {
+		segment := entities.Segment{}
+		var description sql.NullString
+		err := rows.Scan(&segment.ID, &segment.ParentID, &segment.Name, &description, &segment.CreatedAt, &segment.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan segment: %v", err)
+		}
+		segment.Description = description.String
+		segments = append(segments, segment)
+	}
+	return segments, rows.Err()
+}
+
+// buildTree nests the segments under parentId, or returns the top of the tree
+// when it is nil. Segments whose parent was deleted count as top level.
+func buildTree(segments []entities.Segment, parentId *int) []entities.Segment {
+	active := map[int]bool{}
+	for _, segment := range segments {
+		active[segment.ID] = true
+	}
+
+	children := map[int][]entities.Segment{}
+	roots := []entities.Segment{}
+	for _, segment := range segments {
+		if segment.ParentID == nil || !active[*segment.ParentID] {
+			roots = append(roots, segment)
+			continue
+		}
+		children[*segment.ParentID] = append(children[*segment.ParentID], segment)
+	}
+
+	var attach func(nodes []entities.Segment) []entities.Segment
+	attach = func(nodes []entities.Segment) []entities.Segment {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+
+	if parentId == nil {
+		return attach(roots)
+	}
+	return attach(children[*parentId])
+}
+
+func checkParent(tx *sql.Tx, orgId, parentId int) error {
+	var exists bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM segments WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL)`, parentId, orgId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve parent segment: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("parent segment with ID %d not found", parentId)
+	}
+	return nil
+}
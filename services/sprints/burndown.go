@@ -0,0 +1,102 @@
+package sprints
+
+import (
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+type taskState struct {
+	inScope   bool
+	completed bool
+	points    int
+}
+
+// buildBurndown replays the sprint history day by day. Each point reports the
+// scope and completed work at the end of that day, which gives both the
+// burndown (remaining) and burnup (scope vs completed) series.
+func buildBurndown(sprint entities.Sprint, events []entities.SprintTaskEvent, now time.Time) []entities.BurndownPoint {
+	start := truncateDay(sprint.DateStart)
+	end := truncateDay(sprint.DateEnd)
+
+	last := end
+	if sprint.ClosedAt != nil && truncateDay(*sprint.ClosedAt).Before(last) {
+		last = truncateDay(*sprint.ClosedAt)
+	}
+	if truncateDay(now).Before(last) {
+		last = truncateDay(now)
+	}
+
+	totalDays := int(end.Sub(start).Hours()/24) + 1
+	tasks := map[int]*taskState{}
+	points := []entities.BurndownPoint{}
+	next := 0
+	initialScope := -1
+
+	for day, i := start, 0; !day.After(last); day, i = day.AddDate(0, 0, 1), i+1 {
+		endOfDay := day.AddDate(0, 0, 1)
+		for next < len(events) && events[next].OccurredAt.Before(endOfDay) {
+			applyEvent(tasks, events[next])
+			next++
+		}
+
+		point := entities.BurndownPoint{Date: day}
+		for _, task := range tasks {
+			if !task.inScope {
+				continue
+			}
+			point.ScopePoints += task.points
+			point.ScopeTasks++
+			if task.completed {
+				point.CompletedPoints += task.points
+				point.CompletedTasks++
+			}
+		}
+		point.RemainingPoints = point.ScopePoints - point.CompletedPoints
+
+		if initialScope < 0 {
+			initialScope = point.ScopePoints
+		}
+		if totalDays > 1 {
+			point.IdealRemaining = float64(initialScope) * (1 - float64(i)/float64(totalDays-1))
+		}
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+func applyEvent(tasks map[int]*taskState, event entities.SprintTaskEvent) {
+	task, ok := tasks[event.TaskID]
+	if !ok {
+		task = &taskState{}
+		tasks[event.TaskID] = task
+	}
+	if event.StoryPoints != nil {
+		task.points = *event.StoryPoints
+	}
+
+	switch event.Event {
+	case "added":
+		// A completed task is followed by its own completed event
+		task.inScope = true
+		task.completed = false
+	case "estimated":
+		// Clearing the estimate takes the task's points out of the sprint
+		task.points = 0
+		if event.StoryPoints != nil {
+			task.points = *event.StoryPoints
+		}
+	case "removed":
+		task.inScope = false
+	case "completed":
+		task.completed = true
+	case "reopened":
+		task.completed = false
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
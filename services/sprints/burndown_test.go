@@ -0,0 +1,44 @@
+package sprints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+)
+
+func TestBurndownDropsDeletedTasks(t *testing.T) {
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	sprint := entities.Sprint{DateStart: start, DateEnd: start.AddDate(0, 0, 4)}
+	points := func(n int) *int { return &n }
+	at := func(day, hour int) time.Time { return start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+
+	// Task 2 is completed on day 1 and task 3 is deleted on day 2, which
+	// records it as removed from the sprint
+	events := []entities.SprintTaskEvent{
+		{TaskID: 1, Event: "added", StoryPoints: points(5), OccurredAt: at(0, 9)},
+		{TaskID: 2, Event: "added", StoryPoints: points(3), OccurredAt: at(0, 9)},
+		{TaskID: 3, Event: "added", StoryPoints: points(8), OccurredAt: at(0, 9)},
+		{TaskID: 2, Event: "completed", StoryPoints: points(3), OccurredAt: at(1, 15)},
+		{TaskID: 3, Event: "removed", StoryPoints: points(8), OccurredAt: at(2, 11)},
+	}
+
+	got := buildBurndown(sprint, events, at(3, 12))
+	if len(got) != 4 {
+		t.Fatalf("got %d points, want 4", len(got))
+	}
+
+	want := []struct{ scope, completed, remaining, tasks int }{
+		{16, 0, 16, 3},
+		{16, 3, 13, 3},
+		{8, 3, 5, 2},
+		{8, 3, 5, 2},
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.ScopePoints != w.scope || p.CompletedPoints != w.completed || p.RemainingPoints != w.remaining || p.ScopeTasks != w.tasks {
+			t.Errorf("day %d: got scope %d, completed %d, remaining %d, tasks %d; want %d, %d, %d, %d",
+				i, p.ScopePoints, p.CompletedPoints, p.RemainingPoints, p.ScopeTasks, w.scope, w.completed, w.remaining, w.tasks)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package sprints
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/projects/{projectId}/sprints", h.handleGetSprints, "GET")
+	utils.SecureRoute(router, "/projects/{projectId}/sprints", h.handleCreateSprint, "POST")
+	utils.SecureRoute(router, "/sprints/{sprintId}", h.handleGetSprint, "GET")
+	utils.SecureRoute(router, "/sprints/{sprintId}", h.handleUpdateSprint, "PUT")
+	utils.SecureRoute(router, "/sprints/{sprintId}", h.handleDeleteSprint, "DELETE")
+	utils.SecureRoute(router, "/sprints/{sprintId}/start", h.handleStartSprint, "PUT")
+	utils.SecureRoute(router, "/sprints/{sprintId}/close", h.handleCloseSprint, "PUT")
+	utils.SecureRoute(router, "/sprints/{sprintId}/burndown", h.handleGetBurndown, "GET")
+	utils.SecureRoute(router, "/sprints/{sprintId}/tasks", h.handleAssignTasks, "POST")
+	utils.SecureRoute(router, "/sprints/{sprintId}/tasks/{taskId}", h.handleUnassignTask, "DELETE")
+}
@@ -0,0 +1,324 @@
+package sprints
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.SprintStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.SprintStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetSprints(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	sprints, err := h.store.GetSprints(projectId)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": sprints})
+}
+
+func (h *Handler) handleGetSprint(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	sprint, err := h.store.GetSprint(sprintId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": sprint})
+}
+
+func (h *Handler) handleCreateSprint(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectId, err := strconv.Atoi(vars["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload := entities.SprintPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+	payload.ProjectID = projectId
+
+	if !members.AuthorizeWrite(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	sprint, err := h.store.CreateSprint(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": sprint})
+}
+
+func (h *Handler) handleUpdateSprint(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := entities.SprintPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	sprint, err := h.store.GetSprint(sprintId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if sprint.State == entities.SprintClosed {
+		utils.WriteError(w, http.StatusConflict, ErrSprintClosed)
+		return
+	}
+
+	if payload.Name == "" {
+		payload.Name = sprint.Name
+	}
+	if payload.Goal == "" {
+		payload.Goal = sprint.Goal
+	}
+	if payload.DateStart == "" {
+		payload.DateStart = sprint.DateStart.Format("Jan-02-2006")
+	}
+	if payload.DateEnd == "" {
+		payload.DateEnd = sprint.DateEnd.Format("Jan-02-2006")
+	}
+	payload.ID = sprint.ID
+	payload.ProjectID = sprint.ProjectID
+
+	if err := h.store.UpdateSprint(payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Sprint Successfully!"})
+}
+
+func (h *Handler) handleDeleteSprint(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	if err := h.store.DeleteSprint(sprintId); err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Sprint Successfully!"})
+}
+
+func (h *Handler) handleStartSprint(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	err = h.store.StartSprint(sprintId)
+	if errors.Is(err, ErrActiveSprintExists) || errors.Is(err, ErrSprintClosed) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Start Sprint Successfully!"})
+}
+
+func (h *Handler) handleCloseSprint(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// The body is optional: closing without one moves unfinished work to the backlog
+	payload := entities.SprintClosePayload{}
+	if r.ContentLength > 0 {
+		if err := utils.ParseJSON(r, &payload); err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
+	result, err := h.store.CloseSprint(sprintId, payload)
+	if errors.Is(err, ErrSprintClosed) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Close Sprint Successfully!", "data": result})
+}
+
+func (h *Handler) handleAssignTasks(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload := entities.SprintTasksPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMember) {
+		return
+	}
+
+	err = h.store.AssignTasks(sprintId, payload.TaskIDs)
+	if errors.Is(err, ErrSprintClosed) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Assign Tasks Successfully!"})
+}
+
+func (h *Handler) handleUnassignTask(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	taskId, err := strconv.Atoi(mux.Vars(r)["taskId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID"))
+		return
+	}
+
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleMember) {
+		return
+	}
+
+	err = h.store.UnassignTask(sprintId, taskId)
+	if errors.Is(err, ErrSprintClosed) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Remove Task Successfully!"})
+}
+
+func (h *Handler) handleGetBurndown(w http.ResponseWriter, r *http.Request) {
+	sprintId, err := getSprintID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetSprint, sprintId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	burndown, err := h.store.GetBurndown(sprintId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": burndown})
+}
+
+func getSprintID(r *http.Request) (int, error) {
+	str, ok := mux.Vars(r)["sprintId"]
+	if !ok {
+		return 0, fmt.Errorf("missing sprint ID")
+	}
+
+	sprintId, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid sprint ID")
+	}
+	return sprintId, nil
+}
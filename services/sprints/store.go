@@ -0,0 +1,544 @@
+package sprints
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/history"
+)
+
+var (
+	ErrActiveSprintExists = errors.New("project already has an active sprint")
+	ErrSprintClosed       = errors.New("sprint is already closed")
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) GetSprints(projectId int) ([]entities.Sprint, error) {
+	rows, err := s.db.Query(`
+		SELECT
+			s.id, s.projectId, s.name, COALESCE(s.goal, ''), s.dateStart, s.dateEnd, s.state, s.closedAt, s.createdAt, s.updatedAt,
+			COALESCE(SUM(pt.storyPoints), 0) AS story_points,
+			COALESCE(SUM(pt.storyPoints) FILTER (WHERE pt.completedAt IS NOT NULL), 0) AS completed_points
+		FROM sprints s
+		LEFT JOIN project_tasks pt ON pt.sprintId = s.id AND pt.deletedAt IS NULL
+		WHERE s.projectId = $1 AND s.deletedAt IS NULL
+		GROUP BY s.id
+		ORDER BY s.dateStart DESC
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprints: %v", err)
+	}
+	defer rows.Close()
+
+	sprints := []entities.Sprint{}
+	for rows.Next() {
+		sprint := entities.Sprint{}
+		err := rows.Scan(
+			&sprint.ID, &sprint.ProjectID, &sprint.Name, &sprint.Goal, &sprint.DateStart, &sprint.DateEnd, &sprint.State, &sprint.ClosedAt,
+			&sprint.CreatedAt, &sprint.UpdatedAt, &sprint.StoryPoints, &sprint.CompletedPoints,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint: %v", err)
+		}
+		sprints = append(sprints, sprint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over sprint rows: %v", err)
+	}
+	return sprints, nil
+}
+
+func (s *Store) GetSprint(id int) (*entities.Sprint, error) {
+	sprint := entities.Sprint{}
+	err := s.db.QueryRow(`
+		SELECT id, projectId, name, COALESCE(goal, ''), dateStart, dateEnd, state, closedAt, createdAt, updatedAt
+		FROM sprints
+		WHERE id = $1 AND deletedAt IS NULL
+	`, id).Scan(
+		&sprint.ID, &sprint.ProjectID, &sprint.Name, &sprint.Goal, &sprint.DateStart, &sprint.DateEnd, &sprint.State, &sprint.ClosedAt,
+		&sprint.CreatedAt, &sprint.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sprint with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve sprint: %v", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, name, description, userId, priorityId, projectId, storyPoints, estimateHours, sprintId, completedAt, createdAt, updatedAt
+		FROM project_tasks
+		WHERE sprintId = $1 AND deletedAt IS NULL
+		ORDER BY createdAt
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprint tasks: %v", err)
+	}
+	defer rows.Close()
+
+	sprint.Tasks = []entities.TasksProject{}
+	for rows.Next() {
+		task := entities.TasksProject{}
+		err := rows.Scan(
+			&task.ID, &task.Name, &task.Description, &task.UserID, &task.PriorityID, &task.ProjectID,
+			&task.StoryPoints, &task.EstimateHours, &task.SprintID, &task.CompletedAt, &task.CreatedAt, &task.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint task: %v", err)
+		}
+		if task.StoryPoints != nil {
+			sprint.StoryPoints += *task.StoryPoints
+			if task.CompletedAt != nil {
+				sprint.CompletedPoints += *task.StoryPoints
+			}
+		}
+		sprint.Tasks = append(sprint.Tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over sprint task rows: %v", err)
+	}
+	return &sprint, nil
+}
+
+func (s *Store) CreateSprint(payload entities.SprintPayload) (*entities.Sprint, error) {
+	dateStart, dateEnd, err := parseSprintDates(payload.DateStart, payload.DateEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	sprint := entities.Sprint{}
+	err = s.db.QueryRow(`
+		INSERT INTO sprints (projectId, name, goal, dateStart, dateEnd, state)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, projectId, name, COALESCE(goal, ''), dateStart, dateEnd, state, createdAt, updatedAt
+	`, payload.ProjectID, payload.Name, payload.Goal, dateStart, dateEnd, entities.SprintPlanned).Scan(
+		&sprint.ID, &sprint.ProjectID, &sprint.Name, &sprint.Goal, &sprint.DateStart, &sprint.DateEnd, &sprint.State,
+		&sprint.CreatedAt, &sprint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert sprint: %v", err)
+	}
+
+	sprint.Tasks = []entities.TasksProject{}
+	return &sprint, nil
+}
+
+func (s *Store) UpdateSprint(payload entities.SprintPayload) error {
+	dateStart, dateEnd, err := parseSprintDates(payload.DateStart, payload.DateEnd)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		UPDATE sprints
+		SET name = $1, goal = $2, dateStart = $3, dateEnd = $4, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $5 AND deletedAt IS NULL
+	`, payload.Name, payload.Goal, dateStart, dateEnd, payload.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update sprint: %v", err)
+	}
+	return nil
+}
+
+func (s *Store) DeleteSprint(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE sprints SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	// Tasks of a deleted sprint go back to the project backlog
+	_, err = tx.Exec("UPDATE project_tasks SET sprintId = NULL WHERE sprintId = $1", id)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("delete error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) StartSprint(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	var projectId int
+	var state string
+	err = tx.QueryRow("SELECT projectId, state FROM sprints WHERE id = $1 AND deletedAt IS NULL FOR UPDATE", id).Scan(&projectId, &state)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("sprint with ID %d not found", id)
+		}
+		return err
+	}
+
+	if state == entities.SprintClosed {
+		tx.Rollback()
+		return ErrSprintClosed
+	}
+
+	var activeCount int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM sprints
+		WHERE projectId = $1 AND state = $2 AND id <> $3 AND deletedAt IS NULL
+	`, projectId, entities.SprintActive, id).Scan(&activeCount)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if activeCount > 0 {
+		tx.Rollback()
+		return ErrActiveSprintExists
+	}
+
+	_, err = tx.Exec("UPDATE sprints SET state = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", entities.SprintActive, id)
+	if err != nil {
+		tx.Rollback()
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrActiveSprintExists
+		}
+		return fmt.Errorf("failed to start sprint: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) CloseSprint(id int, payload entities.SprintClosePayload) (*entities.SprintCloseResult, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var projectId int
+	var state string
+	err = tx.QueryRow("SELECT projectId, state FROM sprints WHERE id = $1 AND deletedAt IS NULL FOR UPDATE", id).Scan(&projectId, &state)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sprint with ID %d not found", id)
+		}
+		return nil, err
+	}
+	if state == entities.SprintClosed {
+		err = ErrSprintClosed
+		return nil, err
+	}
+
+	if payload.CarryOverSprintID != nil {
+		var targetProjectId int
+		var targetState string
+		err = tx.QueryRow("SELECT projectId, state FROM sprints WHERE id = $1 AND deletedAt IS NULL", *payload.CarryOverSprintID).Scan(&targetProjectId, &targetState)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("carry over sprint with ID %d not found", *payload.CarryOverSprintID)
+			}
+			return nil, err
+		}
+		if *payload.CarryOverSprintID == id || targetProjectId != projectId || targetState == entities.SprintClosed {
+			err = fmt.Errorf("carry over sprint must be another open sprint of the same project")
+			return nil, err
+		}
+	}
+
+	result := entities.SprintCloseResult{
+		SprintID:          id,
+		CompletedTaskIDs:  []int{},
+		CarriedOverIDs:    []int{},
+		CarryOverSprintID: payload.CarryOverSprintID,
+	}
+
+	rows, err := tx.Query("SELECT id, storyPoints, completedAt FROM project_tasks WHERE sprintId = $1 AND deletedAt IS NULL", id)
+	if err != nil {
+		return nil, err
+	}
+
+	carried := map[int]*int{}
+	for rows.Next() {
+		var taskId int
+		var storyPoints *int
+		var completedAt *time.Time
+		if err = rows.Scan(&taskId, &storyPoints, &completedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if completedAt != nil {
+			result.CompletedTaskIDs = append(result.CompletedTaskIDs, taskId)
+			continue
+		}
+		result.CarriedOverIDs = append(result.CarriedOverIDs, taskId)
+		carried[taskId] = storyPoints
+	}
+	rows.Close()
+
+	// Unfinished tasks keep their history in the closed sprint and start fresh in the next one
+	for _, taskId := range result.CarriedOverIDs {
+		_, err = tx.Exec("UPDATE project_tasks SET sprintId = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", payload.CarryOverSprintID, taskId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to carry over task %d: %v", taskId, err)
+		}
+		if payload.CarryOverSprintID != nil {
+			if err = RecordTaskEvent(tx, *payload.CarryOverSprintID, taskId, "added", carried[taskId]); err != nil {
+				return nil, err
+			}
+		}
+		if err = recordSprintChange(tx, taskId, &id, payload.CarryOverSprintID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE sprints SET state = $1, closedAt = CURRENT_TIMESTAMP, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", entities.SprintClosed, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close sprint: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (s *Store) AssignTasks(sprintId int, taskIds []int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var projectId int
+	var state string
+	err = tx.QueryRow("SELECT projectId, state FROM sprints WHERE id = $1 AND deletedAt IS NULL", sprintId).Scan(&projectId, &state)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("sprint with ID %d not found", sprintId)
+		}
+		return err
+	}
+	if state == entities.SprintClosed {
+		err = ErrSprintClosed
+		return err
+	}
+
+	for _, taskId := range taskIds {
+		var taskProjectId int
+		var currentSprintId, storyPoints *int
+		var completedAt *time.Time
+		err = tx.QueryRow(`
+			SELECT projectId, sprintId, storyPoints, completedAt FROM project_tasks WHERE id = $1 AND deletedAt IS NULL FOR UPDATE
+		`, taskId).Scan(&taskProjectId, &currentSprintId, &storyPoints, &completedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("task with ID %d not found", taskId)
+			}
+			return err
+		}
+		if taskProjectId != projectId {
+			err = fmt.Errorf("task %d does not belong to the sprint's project", taskId)
+			return err
+		}
+		if currentSprintId != nil && *currentSprintId == sprintId {
+			continue
+		}
+		// Moving the task out would rewrite the history of its finished sprint
+		if currentSprintId != nil {
+			var closed bool
+			if closed, err = sprintClosed(tx, *currentSprintId); err != nil {
+				return err
+			}
+			if closed {
+				err = fmt.Errorf("%w: task %d belongs to sprint %d", ErrSprintClosed, taskId, *currentSprintId)
+				return err
+			}
+		}
+
+		_, err = tx.Exec("UPDATE project_tasks SET sprintId = $1, updatedAt = CURRENT_TIMESTAMP WHERE id = $2", sprintId, taskId)
+		if err != nil {
+			return fmt.Errorf("failed to assign task %d: %v", taskId, err)
+		}
+		if currentSprintId != nil {
+			if err = RecordTaskEvent(tx, *currentSprintId, taskId, "removed", storyPoints); err != nil {
+				return err
+			}
+		}
+		if err = RecordTaskEvent(tx, sprintId, taskId, "added", storyPoints); err != nil {
+			return err
+		}
+		// Work finished before the task joined counts as done in this sprint too
+		if completedAt != nil {
+			if err = RecordTaskEvent(tx, sprintId, taskId, "completed", storyPoints); err != nil {
+				return err
+			}
+		}
+		if err = recordSprintChange(tx, taskId, currentSprintId, &sprintId); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) UnassignTask(sprintId, taskId int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	closed, err := sprintClosed(tx, sprintId)
+	if err != nil {
+		return err
+	}
+	if closed {
+		err = ErrSprintClosed
+		return err
+	}
+
+	var storyPoints *int
+	err = tx.QueryRow(`
+		UPDATE project_tasks SET sprintId = NULL, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND sprintId = $2
+		RETURNING storyPoints
+	`, taskId, sprintId).Scan(&storyPoints)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("task %d is not part of sprint %d", taskId, sprintId)
+		}
+		return err
+	}
+
+	if err = RecordTaskEvent(tx, sprintId, taskId, "removed", storyPoints); err != nil {
+		return err
+	}
+	if err = recordSprintChange(tx, taskId, &sprintId, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetBurndown(id int) ([]entities.BurndownPoint, error) {
+	sprint, err := s.GetSprint(id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, sprintId, taskId, event, storyPoints, occurredAt
+		FROM sprint_task_events
+		WHERE sprintId = $1
+		ORDER BY occurredAt, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sprint history: %v", err)
+	}
+	defer rows.Close()
+
+	events := []entities.SprintTaskEvent{}
+	for rows.Next() {
+		event := entities.SprintTaskEvent{}
+		err := rows.Scan(&event.ID, &event.SprintID, &event.TaskID, &event.Event, &event.StoryPoints, &event.OccurredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint history: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over sprint history rows: %v", err)
+	}
+
+	return buildBurndown(*sprint, events, time.Now()), nil
+}
+
+// RecordTaskEvent appends a scope, estimate or completion change to a sprint's
+// history inside the caller's transaction.
+func RecordTaskEvent(tx *sql.Tx, sprintId, taskId int, event string, storyPoints *int) error {
+	_, err := tx.Exec(`
+		INSERT INTO sprint_task_events (sprintId, taskId, event, storyPoints)
+		VALUES ($1, $2, $3, $4)
+	`, sprintId, taskId, event, storyPoints)
+	if err != nil {
+		return fmt.Errorf("failed to record sprint history: %v", err)
+	}
+	return nil
+}
+
+// RecordTaskDeleted takes a deleted task out of the scope of its sprint inside
+// the caller's transaction. Closed sprints keep the history they ended with.
+func RecordTaskDeleted(tx *sql.Tx, sprintId, taskId int, storyPoints *int) error {
+	closed, err := sprintClosed(tx, sprintId)
+	if err != nil || closed {
+		return err
+	}
+	return RecordTaskEvent(tx, sprintId, taskId, "removed", storyPoints)
+}
+
+// sprintClosed reports whether a sprint is closed, locking it so it cannot be
+// closed before the caller's transaction ends
+func sprintClosed(tx *sql.Tx, sprintId int) (bool, error) {
+	var state string
+	err := tx.QueryRow("SELECT state FROM sprints WHERE id = $1 FOR UPDATE", sprintId).Scan(&state)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("sprint with ID %d not found", sprintId)
+		}
+		return false, fmt.Errorf("failed to retrieve sprint: %v", err)
+	}
+	return state == entities.SprintClosed, nil
+}
+
+// recordSprintChange adds the sprint move of a task to its field history
+func recordSprintChange(tx *sql.Tx, taskId int, from, to *int) error {
+	changes := history.Changes{}
+	changes.Add("sprintId", from, to)
+	return history.Record(tx, entities.HistoryTargetProjectTask, taskId, nil, changes)
+}
+
+func parseSprintDates(start, end string) (time.Time, time.Time, error) {
+	dateStart, err := time.Parse("Jan-02-2006", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date format for DateStart")
+	}
+	dateEnd, err := time.Parse("Jan-02-2006", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date format for DateEnd")
+	}
+	if !dateEnd.After(dateStart) {
+		return time.Time{}, time.Time{}, fmt.Errorf("dateEnd must be after dateStart")
+	}
+	return dateStart, dateEnd, nil
+}
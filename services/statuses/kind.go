@@ -0,0 +1,46 @@
+package statuses
+
+import (
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/lookups"
+)
+
+const liveWorkflow = "workflowId IN (SELECT id FROM workflows WHERE deletedAt IS NULL)"
+
+var Kind = lookups.Kind[entities.Status, entities.StatusPayload]{
+	Table:   "statuses",
+	Label:   "status",
+	Select:  []string{"name", "description", "category"},
+	Columns: []string{"name", "description", "category"},
+	OrderBy: "createdAt DESC",
+	References: []lookups.Reference{
+		{Name: "projects", Table: "projects", Column: "statusId", Live: "deletedAt IS NULL"},
+		{Name: "tasks", Table: "tasks", Column: "statusId", Live: "deletedAt IS NULL"},
+		{Name: "projectTasks", Table: "project_tasks", Column: "statusId", Live: "deletedAt IS NULL"},
+		{Name: "workflowTransitions", Table: "workflow_transitions", Column: "fromStatusId", Live: liveWorkflow},
+		{Name: "workflowTransitions", Table: "workflow_transitions", Column: "toStatusId", Live: liveWorkflow},
+	},
+	Fields: func(status *entities.Status) []interface{} {
+		return []interface{}{&status.ID, &status.Name, &status.Description, &status.Category, &status.CreatedAt, &status.UpdatedAt}
+	},
+	Values: func(payload entities.StatusPayload) []interface{} {
+		category := payload.Category
+		if category == "" {
+			category = entities.StatusCategoryTodo
+		}
+		return []interface{}{payload.Name, payload.Description, category}
+	},
+	Name: func(payload entities.StatusPayload) string { return payload.Name },
+	Merge: func(status entities.Status, payload entities.StatusPayload) entities.StatusPayload {
+		if payload.Name == "" {
+			payload.Name = status.Name
+		}
+		if payload.Description == "" {
+			payload.Description = status.Description
+		}
+		if payload.Category == "" {
+			payload.Category = status.Category
+		}
+		return payload
+	},
+}
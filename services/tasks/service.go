@@ -8,19 +8,45 @@ import (
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Handler struct {
-	store entities.TaskStore
+	store  entities.TaskStore
+	fields entities.CustomFieldStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.TaskStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.TaskStore, fields entities.CustomFieldStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, fields: fields, access: access}
 }
 
 func (h *Handler) handleGetTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.store.GetTasks()
+	labelIDs, err := utils.ParseIntList(r.URL.Query().Get("labelIds"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	customFields, err := customfields.ParseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && sort != entities.TaskSortPriority {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid sort %q", sort))
+		return
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.store.GetTasks(entities.TaskFilter{OrgID: utils.GetOrgID(r), LabelIDs: labelIDs, CustomFields: customFields, Sort: sort, MemberID: memberId})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -32,7 +58,12 @@ func (h *Handler) handleGetTasks(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleGetDeletedTasks(w http.ResponseWriter, r *http.Request) {
-	tasks, err := h.store.GetTasks()
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.store.GetTasks(entities.TaskFilter{OrgID: utils.GetOrgID(r), MemberID: memberId})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -57,6 +88,10 @@ func (h *Handler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetTask, taskId, entities.ProjectRoleViewer) {
+		return
+	}
+
 	task, err := h.store.GetTask(taskId)
 	if err != nil {
 		utils.WriteError(w, http.StatusNotFound, err)
@@ -95,6 +130,26 @@ func (h *Handler) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetWorkspace, payload.WorkspaceID, entities.ProjectRoleMember) {
+		return
+	}
+
+	if err := utils.ValidateEstimate(payload.StoryPoints, payload.EstimateHours); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := utils.ParseOptionalDate(payload.DueDate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validateCustomFields(payload.WorkspaceID, payload.CustomFields, true); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload.CreatedBy = utils.GetUserID(r)
 	task, err := h.store.TaskCreate(payload)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -137,6 +192,10 @@ func (h *Handler) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	if len(payload.Title) == 0 {
 		payload.Title = existTask.Title
 	}
@@ -144,7 +203,7 @@ func (h *Handler) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 	if payload.Description == "" {
 		payload.Description = existTask.Description
 	}
-	if payload.UserID == 0 {
+	if payload.UserID == 0 && existTask.UserID != nil {
 		payload.UserID = *existTask.UserID
 	}
 
@@ -154,10 +213,42 @@ func (h *Handler) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 
 	if payload.WorkspaceID == 0 {
 		payload.WorkspaceID = existTask.WorkspaceID
+	} else if payload.WorkspaceID != existTask.WorkspaceID && !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetWorkspace, payload.WorkspaceID, entities.ProjectRoleMember) {
+		return
+	}
+
+	payload.StoryPoints, payload.EstimateHours, err = utils.MergeEstimate(payload.StoryPoints, existTask.StoryPoints, payload.EstimateHours, existTask.EstimateHours, payload.ClearEstimate)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if payload.DueDate == "" {
+		payload.DueDate = utils.FormatOptionalDate(existTask.DueDate)
+	}
+
+	if err := utils.ValidateEstimate(payload.StoryPoints, payload.EstimateHours); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := utils.ParseOptionalDate(payload.DueDate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
 	}
 	payload.ID = existTask.ID
+	payload.UpdatedBy = utils.GetUserID(r)
+
+	if err := h.validateCustomFields(payload.WorkspaceID, payload.CustomFields, false); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 
 	err = h.store.TaskUpdate(payload)
+	if workflows.IsViolation(err) {
+		utils.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -180,6 +271,10 @@ func (h *Handler) handleTaskDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	err = h.store.TaskDelete(taskId)
 
 	if err != nil {
@@ -205,6 +300,10 @@ func (h *Handler) handleTaskRestore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	task, err := h.store.TaskRestore(taskId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -215,6 +314,16 @@ func (h *Handler) handleTaskRestore(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// validateCustomFields checks values against the fields of the project that
+// owns the workspace
+func (h *Handler) validateCustomFields(workspaceId int, values entities.CustomFieldValues, create bool) error {
+	fields, err := h.fields.GetWorkspaceCustomFields(workspaceId)
+	if err != nil {
+		return err
+	}
+	return h.fields.ValidateValues(fields, values, create)
+}
+
 // func (h *Handler) handleTaskDragNDrop(w http.ResponseWriter, r *http.Request) {
 // 	// Parse query parameter for workspaceId
 // 	workspaceIdStr := r.URL.Query().Get("workspaceId")
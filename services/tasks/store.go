@@ -4,8 +4,22 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/history"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/notifications"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/outbox"
+	"github.com/norrico31/it210-core-service-backend/services/realtime"
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
+	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Store struct {
@@ -16,26 +30,73 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetTasks() ([]*entities.Task, error) {
+func (s *Store) GetTasks(filter entities.TaskFilter) ([]*entities.Task, error) {
 	// SQL query without subtasks
 	query := fmt.Sprintf(`
         SELECT 
-			t.id, t.title, t.description, t.userId, t.priorityId, t.workspaceId, t.taskOrder, t.createdAt, t.updatedAt, t.deletedAt, t.deletedBy
+			t.id, t.title, t.description, t.userId, t.priorityId, t.workspaceId, t.statusId, t.taskOrder, t.storyPoints, t.estimateHours, t.dueDate, t.completedAt, t.createdAt, t.updatedAt, t.deletedAt, t.deletedBy
         FROM tasks t
     `)
 
-	rows, err := s.db.Query(query)
+	conditions := []string{
+		members.OrgCondition("(SELECT projectId FROM workspaces WHERE id = t.workspaceId)", "$1"),
+		members.ActiveCondition("(SELECT projectId FROM workspaces WHERE id = t.workspaceId)"),
+	}
+	args := []interface{}{filter.OrgID}
+	if len(filter.LabelIDs) > 0 {
+		args = append(args, pq.Array(filter.LabelIDs))
+		conditions = append(conditions, labels.LabelFilterCondition(entities.LabelTargetTask, "t.id", fmt.Sprintf("$%d", len(args))))
+	}
+	for fieldId, value := range filter.CustomFields {
+		args = append(args, fieldId, value)
+		conditions = append(conditions, customfields.FilterCondition(entities.CustomFieldTargetTask, "t.id", fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.MemberID != nil {
+		args = append(args, *filter.MemberID)
+		conditions = append(conditions, members.MemberCondition("(SELECT projectId FROM workspaces WHERE id = t.workspaceId)", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.ProjectID != nil {
+		args = append(args, *filter.ProjectID)
+		conditions = append(conditions, fmt.Sprintf("(SELECT projectId FROM workspaces WHERE id = t.workspaceId) = $%d", len(args)))
+	}
+	if filter.AssigneeID != nil {
+		args = append(args, *filter.AssigneeID)
+		conditions = append(conditions, fmt.Sprintf("t.userId = $%d", len(args)))
+	}
+	if len(filter.PriorityIDs) > 0 {
+		args = append(args, pq.Array(filter.PriorityIDs))
+		conditions = append(conditions, fmt.Sprintf("t.priorityId = ANY($%d)", len(args)))
+	}
+	if len(filter.StatusIDs) > 0 {
+		args = append(args, pq.Array(filter.StatusIDs))
+		conditions = append(conditions, fmt.Sprintf("t.statusId = ANY($%d)", len(args)))
+	}
+	if filter.Completed != nil {
+		if *filter.Completed {
+			conditions = append(conditions, "t.completedAt IS NOT NULL")
+		} else {
+			conditions = append(conditions, "t.completedAt IS NULL")
+		}
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ")
+	if filter.Sort == entities.TaskSortPriority {
+		query += " ORDER BY (SELECT rank FROM priorities WHERE id = t.priorityId), t.id"
+	} else {
+		query += " ORDER BY t.id"
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	tasksMap := make(map[int]*entities.Task)
+	tasks := []*entities.Task{}
 	for rows.Next() {
 		task := entities.Task{}
 
 		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.UserID, &task.PriorityID, &task.WorkspaceID, &task.TaskOrder, &task.CreatedAt,
+			&task.ID, &task.Title, &task.Description, &task.UserID, &task.PriorityID, &task.WorkspaceID, &task.StatusID, &task.TaskOrder, &task.StoryPoints, &task.EstimateHours, &task.DueDate, &task.CompletedAt, &task.CreatedAt,
 			&task.UpdatedAt, &task.DeletedAt, &task.DeletedBy,
 		)
 
@@ -43,16 +104,35 @@ func (s *Store) GetTasks() ([]*entities.Task, error) {
 			log.Printf("Failed to scan task: %v", err)
 			continue
 		}
-		tasksMap[task.ID] = &task
+		tasks = append(tasks, &task)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to iterate over tasks rows: %v", err)
 	}
 
-	tasks := make([]*entities.Task, 0, len(tasksMap))
-	for _, task := range tasksMap {
-		tasks = append(tasks, task)
+	taskIDs := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	valuesMap, err := customfields.GetValuesFor(s.db, entities.CustomFieldTargetTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		task.Labels = labelsMap[task.ID]
+		if task.Labels == nil {
+			task.Labels = []entities.Label{}
+		}
+		task.CustomFields = valuesMap[task.ID]
+		if task.CustomFields == nil {
+			task.CustomFields = []entities.CustomFieldValue{}
+		}
 	}
 
 	return tasks, nil
@@ -61,11 +141,11 @@ func (s *Store) GetTasks() ([]*entities.Task, error) {
 func (s *Store) GetTask(id int) (*entities.Task, error) {
 	query := fmt.Sprintf(`
         SELECT 
-			t.id, t.title, t.description, t.userId, t.priorityId, t.workspaceId, t.taskOrder, t.createdAt, t.updatedAt, t.deletedAt, t.deletedBy,
+			t.id, t.title, t.description, t.userId, t.priorityId, t.workspaceId, t.statusId, t.taskOrder, t.storyPoints, t.estimateHours, t.dueDate, t.completedAt, t.createdAt, t.updatedAt, t.deletedAt, t.deletedBy,
 
 			u.id AS user_id, u.firstName, u.lastName, u.email, u.age, u.lastActiveAt, u.createdAt AS user_createdAt, u.updatedAt AS user_updatedAt, u.deletedAt AS user_deletedAt,
 
-			p.id priority_id, p.name priority_name, p.description priority_description, p.createdAt priority_createdAt, p.updatedAt priority_updatedAt, p.deletedAt priority_deletedAt,
+			p.id priority_id, p.name priority_name, p.description priority_description, p.rank priority_rank, p.color priority_color, p.icon priority_icon, p.createdAt priority_createdAt, p.updatedAt priority_updatedAt, p.deletedAt priority_deletedAt,
 
 			w.id workspace_id, w.name workspace_name, w.description workspace_description
 
@@ -87,13 +167,13 @@ func (s *Store) GetTask(id int) (*entities.Task, error) {
 	var workspace entities.Workspace
 
 	err := row.Scan(
-		&task.ID, &task.Title, &task.Description, &task.UserID, &task.PriorityID, &task.WorkspaceID, &task.TaskOrder, &task.CreatedAt,
+		&task.ID, &task.Title, &task.Description, &task.UserID, &task.PriorityID, &task.WorkspaceID, &task.StatusID, &task.TaskOrder, &task.StoryPoints, &task.EstimateHours, &task.DueDate, &task.CompletedAt, &task.CreatedAt,
 		&task.UpdatedAt, &task.DeletedAt, &task.DeletedBy,
 
 		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Age, &user.LastActiveAt, &user.CreatedAt,
 		&user.UpdatedAt, &user.DeletedAt,
 
-		&priority.ID, &priority.Name, &priority.Description, &priority.CreatedAt, &priority.UpdatedAt, &priority.DeletedAt,
+		&priority.ID, &priority.Name, &priority.Description, &priority.Rank, &priority.Color, &priority.Icon, &priority.CreatedAt, &priority.UpdatedAt, &priority.DeletedAt,
 
 		&workspace.ID, &workspace.Name, &workspace.Description,
 	)
@@ -113,6 +193,24 @@ func (s *Store) GetTask(id int) (*entities.Task, error) {
 	if workspace.ID != 0 {
 		task.Workspace = workspace
 	}
+
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetTask, []int{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.Labels = labelsMap[task.ID]
+	if task.Labels == nil {
+		task.Labels = []entities.Label{}
+	}
+
+	valuesMap, err := customfields.GetValuesFor(s.db, entities.CustomFieldTargetTask, []int{task.ID})
+	if err != nil {
+		return nil, err
+	}
+	task.CustomFields = valuesMap[task.ID]
+	if task.CustomFields == nil {
+		task.CustomFields = []entities.CustomFieldValue{}
+	}
 	return task, nil
 }
 
@@ -130,11 +228,39 @@ func (s *Store) TaskCreate(payload entities.TaskCreatePayload) (*entities.Task,
 		}
 	}()
 
+	dueDate, err := utils.ParseOptionalDate(payload.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := CreateTask(tx, payload, dueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.PublishTask(entities.EventTaskCreated, task.ID)
+	return task, nil
+}
+
+// CreateTask inserts a task due on dueDate at the bottom of its column and
+// records its webhook and outbox events in tx. The caller publishes it once
+// tx commits.
+func CreateTask(tx *sql.Tx, payload entities.TaskCreatePayload, dueDate *time.Time) (*entities.Task, error) {
+	orgId, err := checkReferences(tx, payload.WorkspaceID, payload.PriorityID, payload.UserID, payload.StatusID)
+	if err != nil {
+		return nil, err
+	}
+
 	task := entities.Task{}
 	query := `
-		INSERT INTO tasks (title, description, userId, priorityId, workspaceId, taskOrder)
-		VALUES ($1, $2, $3, $4, $5, NULL)
-		RETURNING id, title, description, userId, priorityId, workspaceId, taskOrder, createdAt, updatedAt
+		INSERT INTO tasks (title, description, userId, priorityId, workspaceId, statusId, taskOrder, storyPoints, estimateHours, dueDate)
+		VALUES ($1, $2, $3, $4, $5, $6, (SELECT COALESCE(MAX(taskOrder), 0) + 1 FROM tasks WHERE workspaceId = $5 AND deletedAt IS NULL), $7, $8, $9)
+		RETURNING id, title, description, userId, priorityId, workspaceId, statusId, taskOrder, storyPoints, estimateHours, dueDate, createdAt, updatedAt
 	`
 	err = tx.QueryRow(
 		query,
@@ -143,6 +269,10 @@ func (s *Store) TaskCreate(payload entities.TaskCreatePayload) (*entities.Task,
 		sql.NullInt64{Int64: int64(payload.UserID), Valid: payload.UserID != 0},
 		payload.PriorityID,
 		payload.WorkspaceID,
+		payload.StatusID,
+		payload.StoryPoints,
+		payload.EstimateHours,
+		dueDate,
 	).Scan(
 		&task.ID,
 		&task.Title,
@@ -150,7 +280,11 @@ func (s *Store) TaskCreate(payload entities.TaskCreatePayload) (*entities.Task,
 		&task.UserID,
 		&task.PriorityID,
 		&task.WorkspaceID,
+		&task.StatusID,
 		&task.TaskOrder,
+		&task.StoryPoints,
+		&task.EstimateHours,
+		&task.DueDate,
 		&task.CreatedAt,
 		&task.UpdatedAt,
 	)
@@ -158,11 +292,23 @@ func (s *Store) TaskCreate(payload entities.TaskCreatePayload) (*entities.Task,
 		return nil, fmt.Errorf("failed to insert task: %w", err)
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	if err = customfields.SaveValues(tx, entities.CustomFieldTargetTask, task.ID, payload.CustomFields); err != nil {
+		return nil, err
 	}
 
+	assignment := history.Changes{}
+	assignment.Add("userId", nil, task.UserID)
+	if err = notifications.NotifyTaskChanges(tx, entities.WatchTargetTask, task.ID, task.Title, payload.CreatedBy, assignment); err != nil {
+		return nil, err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventTaskCreated, task); err != nil {
+		return nil, err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateTask, task.ID, "created", task); err != nil {
+		return nil, err
+	}
 	return &task, nil
 }
 
@@ -177,13 +323,79 @@ func (s *Store) TaskUpdate(payload entities.TaskUpdatePayload) error {
 	fmt.Println("payload: ", payload.PriorityID)
 	fmt.Println("payload: ", payload.UserID)
 	fmt.Println("payload: ", payload.WorkspaceID)
-	_, err = tx.Exec(`UPDATE tasks SET title = $1, description = $2, userId = $3, priorityId = $4, workspaceId = $5, updatedAt = CURRENT_TIMESTAMP WHERE id = $6`,
+	dueDate, err := utils.ParseOptionalDate(payload.DueDate)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	orgId, err := checkReferences(tx, payload.WorkspaceID, payload.PriorityID, payload.UserID, payload.StatusID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	old := entities.Task{}
+	err = tx.QueryRow(`
+		SELECT title, description, userId, priorityId, workspaceId, statusId, storyPoints, estimateHours, dueDate, completedAt
+		FROM tasks WHERE id = $1 FOR UPDATE
+	`, payload.ID).Scan(&old.Title, &old.Description, &old.UserID, &old.PriorityID, &old.WorkspaceID, &old.StatusID, &old.StoryPoints, &old.EstimateHours, &old.DueDate, &old.CompletedAt)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("task with ID %d not found", payload.ID)
+		}
+		return err
+	}
+
+	statusId := old.StatusID
+	if payload.StatusID != nil {
+		statusId = payload.StatusID
+	}
+	payload.Completed, err = workflows.Completion(tx, old.StatusID, statusId, payload.Completed)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	wasCompleted := old.CompletedAt != nil
+	completed := wasCompleted
+	if payload.Completed != nil {
+		completed = *payload.Completed
+	}
+
+	// An unassigned task stays unassigned when no user is sent
+	var userId *int
+	if payload.UserID != 0 {
+		userId = &payload.UserID
+	}
+
+	changes := history.Changes{}
+	changes.Add("title", old.Title, payload.Title)
+	changes.Add("description", old.Description, payload.Description)
+	changes.Add("userId", old.UserID, userId)
+	changes.Add("priorityId", old.PriorityID, payload.PriorityID)
+	changes.Add("workspaceId", old.WorkspaceID, payload.WorkspaceID)
+	changes.Add("statusId", old.StatusID, statusId)
+	changes.Add("storyPoints", old.StoryPoints, payload.StoryPoints)
+	changes.Add("estimateHours", old.EstimateHours, payload.EstimateHours)
+	changes.Add("dueDate", old.DueDate, dueDate)
+	changes.Add("completed", wasCompleted, completed)
+
+	_, err = tx.Exec(`UPDATE tasks SET title = $1, description = $2, userId = $3, priorityId = $4, workspaceId = $5, storyPoints = $6, estimateHours = $7, dueDate = $8,
+		completedAt = CASE WHEN $9::BOOLEAN IS NULL THEN completedAt WHEN $9 THEN COALESCE(completedAt, CURRENT_TIMESTAMP) ELSE NULL END,
+		statusId = $11, updatedAt = CURRENT_TIMESTAMP WHERE id = $10`,
 		payload.Title,
 		payload.Description,
-		payload.UserID,
+		userId,
 		payload.PriorityID,
 		payload.WorkspaceID,
+		payload.StoryPoints,
+		payload.EstimateHours,
+		dueDate,
+		payload.Completed,
 		payload.ID,
+		statusId,
 	)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -192,9 +404,45 @@ func (s *Store) TaskUpdate(payload entities.TaskUpdatePayload) error {
 		return err
 	}
 
+	if err = workflows.Enforce(tx, orgId, entities.WorkflowTargetTask, payload.ID, old.StatusID, statusId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = customfields.SaveValues(tx, entities.CustomFieldTargetTask, payload.ID, payload.CustomFields); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = history.Record(tx, entities.HistoryTargetTask, payload.ID, payload.UpdatedBy, changes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = notifications.NotifyTaskChanges(tx, entities.WatchTargetTask, payload.ID, payload.Title, payload.UpdatedBy, changes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventTaskUpdated, map[string]interface{}{"id": payload.ID, "changes": changes}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateTask, payload.ID, "updated", map[string]interface{}{"id": payload.ID, "changes": changes}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return err
 	}
+
+	eventType := entities.EventTaskUpdated
+	if old.WorkspaceID != payload.WorkspaceID {
+		eventType = entities.EventTaskMoved
+	}
+	s.PublishTask(eventType, payload.ID)
 	return nil
 }
 
@@ -213,10 +461,27 @@ func (s *Store) TaskDelete(id int) error {
 		return nil
 	}
 
+	orgId, err := orgOf(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventTaskDeleted, map[string]int{"id": id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateTask, id, "deleted", map[string]int{"id": id}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil
 	}
 
+	s.PublishTask(entities.EventTaskDeleted, id)
 	return nil
 }
 
@@ -243,13 +508,54 @@ func (s *Store) TaskRestore(id int) (*entities.Task, error) {
 		return nil, err
 	}
 
+	orgId, err := orgOf(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventTaskRestored, task); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateTask, id, "restored", task); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return &task, nil
 	}
 
+	s.PublishTask(entities.EventTaskRestored, id)
 	return &task, nil
 }
 
+// PublishTask pushes the committed state of a task to the board of its
+// project; deleted tasks are sent as their id only.
+func (s *Store) PublishTask(eventType string, taskId int) {
+	var projectId int
+	err := s.db.QueryRow(`
+		SELECT w.projectId FROM tasks t JOIN workspaces w ON w.id = t.workspaceId WHERE t.id = $1
+	`, taskId).Scan(&projectId)
+	if err != nil {
+		log.Printf("failed to publish %s for task %d: %v", eventType, taskId, err)
+		return
+	}
+
+	var data interface{} = map[string]int{"id": taskId}
+	if eventType != entities.EventTaskDeleted {
+		task, err := s.GetTask(taskId)
+		if err != nil {
+			log.Printf("failed to publish %s for task %d: %v", eventType, taskId, err)
+			return
+		}
+		data = task
+	}
+	realtime.Publish(entities.BoardEvent{Type: eventType, ProjectID: projectId, Data: data})
+}
+
 // func (s *Store) TaskDragNDrop(workspaceId int, sourceIndex, destinationIndex int) error {
 // 	tx, err := s.db.Begin()
 // 	if err != nil {
@@ -350,3 +656,44 @@ func scanRowIntoTask(rows *sql.Rows, task *entities.Task) error {
 		&task.DeletedBy,
 	)
 }
+
+// checkReferences makes sure the task only points at rows of the organization
+// owning its workspace, which it returns
+func checkReferences(tx *sql.Tx, workspaceId, priorityId, userId int, statusId *int) (int, error) {
+	var orgId int
+	err := tx.QueryRow(`
+		SELECT p.orgId FROM workspaces w JOIN projects p ON p.id = w.projectId WHERE w.id = $1
+	`, workspaceId).Scan(&orgId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("workspace with ID %d not found", workspaceId)
+		}
+		return 0, err
+	}
+	if err := organizations.CheckOwned(tx, orgId, entities.TenantPriorities, []int{priorityId}); err != nil {
+		return 0, err
+	}
+	if userId != 0 {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantUsers, []int{userId}); err != nil {
+			return 0, err
+		}
+	}
+	if statusId != nil {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantStatuses, []int{*statusId}); err != nil {
+			return 0, err
+		}
+	}
+	return orgId, nil
+}
+
+// orgOf returns the organization owning the workspace of a task
+func orgOf(tx *sql.Tx, taskId int) (int, error) {
+	var orgId int
+	err := tx.QueryRow(`
+		SELECT p.orgId FROM tasks t JOIN workspaces w ON w.id = t.workspaceId JOIN projects p ON p.id = w.projectId WHERE t.id = $1
+	`, taskId).Scan(&orgId)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("task with ID %d not found", taskId)
+	}
+	return orgId, err
+}
@@ -8,15 +8,20 @@ import (
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Handler struct {
-	store entities.TasksProjectStore
+	store  entities.TasksProjectStore
+	fields entities.CustomFieldStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.TasksProjectStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.TasksProjectStore, fields entities.CustomFieldStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, fields: fields, access: access}
 }
 
 func (h *Handler) handleGetTasksProject(w http.ResponseWriter, r *http.Request) {
@@ -32,7 +37,26 @@ func (h *Handler) handleGetTasksProject(w http.ResponseWriter, r *http.Request)
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	tasksProject, err := h.store.GetTasksProject(projectId)
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+	labelIDs, err := utils.ParseIntList(r.URL.Query().Get("labelIds"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	customFields, err := customfields.ParseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	sort := r.URL.Query().Get("sort")
+	if sort != "" && sort != entities.TaskSortPriority {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid sort %q", sort))
+		return
+	}
+
+	tasksProject, err := h.store.GetTasksProject(projectId, entities.TasksProjectFilter{LabelIDs: labelIDs, CustomFields: customFields, Sort: sort})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -56,6 +80,9 @@ func (h *Handler) handleGetTaskProject(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	if !members.AuthorizeTarget(w, r, h.access, entities.AccessTargetProjectTask, taskId, entities.ProjectRoleViewer) {
+		return
+	}
 	tasksProject, err := h.store.GetTaskProject(taskId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -101,6 +128,26 @@ func (h *Handler) handleTasksProjectCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !members.AuthorizeWrite(w, r, h.access, payload.ProjectID, entities.ProjectRoleMember) {
+		return
+	}
+
+	if err := utils.ValidateEstimate(payload.StoryPoints, payload.EstimateHours); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := utils.ParseOptionalDate(payload.DueDate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.validateCustomFields(payload.ProjectID, payload.CustomFields, true); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	payload.CreatedBy = utils.GetUserID(r)
 	task, err := h.store.TasksProjectCreate(payload)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -142,6 +189,10 @@ func (h *Handler) handleTasksProjectUpdate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !members.AuthorizeWrite(w, r, h.access, existTask.ProjectID, entities.ProjectRoleMember) {
+		return
+	}
+
 	if len(payload.Name) == 0 {
 		payload.Name = existTask.Name
 	}
@@ -149,7 +200,7 @@ func (h *Handler) handleTasksProjectUpdate(w http.ResponseWriter, r *http.Reques
 	if payload.Description == "" {
 		payload.Description = existTask.Description
 	}
-	if payload.UserID == 0 {
+	if payload.UserID == 0 && existTask.UserID != nil {
 		payload.UserID = *existTask.UserID
 	}
 
@@ -158,10 +209,42 @@ func (h *Handler) handleTasksProjectUpdate(w http.ResponseWriter, r *http.Reques
 	}
 	if payload.ProjectID == 0 {
 		payload.ProjectID = existTask.ProjectID
+	} else if payload.ProjectID != existTask.ProjectID && !members.AuthorizeWrite(w, r, h.access, payload.ProjectID, entities.ProjectRoleMember) {
+		return
+	}
+
+	payload.StoryPoints, payload.EstimateHours, err = utils.MergeEstimate(payload.StoryPoints, existTask.StoryPoints, payload.EstimateHours, existTask.EstimateHours, payload.ClearEstimate)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if payload.DueDate == "" {
+		payload.DueDate = utils.FormatOptionalDate(existTask.DueDate)
+	}
+
+	if err := utils.ValidateEstimate(payload.StoryPoints, payload.EstimateHours); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := utils.ParseOptionalDate(payload.DueDate); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
 	}
 	payload.ID = existTask.ID
+	payload.UpdatedBy = utils.GetUserID(r)
+
+	if err := h.validateCustomFields(payload.ProjectID, payload.CustomFields, false); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 
 	err = h.store.TasksProjectUpdate(payload)
+	if workflows.IsViolation(err) {
+		utils.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -184,6 +267,10 @@ func (h *Handler) handleGetDeletedTasksProject(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetProjectTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	err = h.store.TasksProjectDelete(taskId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
@@ -209,6 +296,10 @@ func (h *Handler) handleTasksProjectDelete(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetProjectTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	err = h.store.TasksProjectDelete(taskId)
 
 	if err != nil {
@@ -234,6 +325,10 @@ func (h *Handler) handleTasksProjectRestore(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetProjectTask, taskId, entities.ProjectRoleMember) {
+		return
+	}
+
 	task, err := h.store.TasksProjectRestore(taskId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
@@ -243,3 +338,11 @@ func (h *Handler) handleTasksProjectRestore(w http.ResponseWriter, r *http.Reque
 	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Restore TasksProject Successfully!", "data": task})
 
 }
+
+func (h *Handler) validateCustomFields(projectId int, values entities.CustomFieldValues, create bool) error {
+	fields, err := h.fields.GetCustomFields(projectId)
+	if err != nil {
+		return err
+	}
+	return h.fields.ValidateValues(fields, values, create)
+}
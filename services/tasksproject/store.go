@@ -6,7 +6,17 @@ import (
 	"log"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/history"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/notifications"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/sprints"
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+	"github.com/norrico31/it210-core-service-backend/services/workflows"
+	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Store struct {
@@ -17,7 +27,7 @@ func NewStore(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error) {
+func (s *Store) GetTasksProject(projectId int, filter entities.TasksProjectFilter) ([]*entities.TasksProject, error) {
 	// SQL query to get tasks based on projectId and include user and priority details as nested objects
 	query := fmt.Sprintf(`
         SELECT 
@@ -27,6 +37,13 @@ func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error)
 			pt.userId task_user_id, 
 			pt.priorityId task_priority_id, 
 			pt.projectId task_project_id, 
+			pt.storyPoints task_story_points, 
+			pt.estimateHours task_estimate_hours, 
+			pt.statusId task_status_id, 
+			pt.sprintId task_sprint_id, 
+			pt.milestoneId task_milestone_id, 
+			pt.completedAt task_completedAt, 
+			pt.dueDate task_dueDate, 
 			pt.createdAt task_createdAt, 
 			pt.updatedAt task_updatedAt, 
 			pt.deletedAt task_deletedAt,
@@ -35,14 +52,48 @@ func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error)
 			u.age user_age,
 			u.email user_email,
 			p.name priority_name,
-			p.description priority_description
+			p.description priority_description,
+			p.rank priority_rank,
+			p.color priority_color
         FROM project_tasks pt
         JOIN users u ON pt.userId = u.id
         JOIN priorities p ON pt.priorityId = p.id
         WHERE pt.projectId = $1 AND pt.deletedAt IS NULL
     `)
 
-	rows, err := s.db.Query(query, projectId) // Pass projectId as a parameter
+	args := []interface{}{projectId}
+	if len(filter.LabelIDs) > 0 {
+		args = append(args, pq.Array(filter.LabelIDs))
+		query += " AND " + labels.LabelFilterCondition(entities.LabelTargetProjectTask, "pt.id", fmt.Sprintf("$%d", len(args)))
+	}
+	for fieldId, value := range filter.CustomFields {
+		args = append(args, fieldId, value)
+		query += " AND " + customfields.FilterCondition(entities.CustomFieldTargetProjectTask, "pt.id", fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args)))
+	}
+	if filter.AssigneeID != nil {
+		args = append(args, *filter.AssigneeID)
+		query += fmt.Sprintf(" AND pt.userId = $%d", len(args))
+	}
+	if len(filter.PriorityIDs) > 0 {
+		args = append(args, pq.Array(filter.PriorityIDs))
+		query += fmt.Sprintf(" AND pt.priorityId = ANY($%d)", len(args))
+	}
+	if len(filter.StatusIDs) > 0 {
+		args = append(args, pq.Array(filter.StatusIDs))
+		query += fmt.Sprintf(" AND pt.statusId = ANY($%d)", len(args))
+	}
+	if filter.Completed != nil {
+		if *filter.Completed {
+			query += " AND pt.completedAt IS NOT NULL"
+		} else {
+			query += " AND pt.completedAt IS NULL"
+		}
+	}
+	if filter.Sort == entities.TaskSortPriority {
+		query += " ORDER BY p.rank, pt.id"
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,14 +104,15 @@ func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error)
 		tasksProject := entities.TasksProject{}
 		var userFirstName, userLastName, userEmail string
 		var userAge int
-		var priorityName, priorityDescription string
+		var priorityName, priorityDescription, priorityColor string
+		var priorityRank int
 
 		err := rows.Scan(
 			&tasksProject.ID, &tasksProject.Name, &tasksProject.Description, &tasksProject.UserID,
-			&tasksProject.PriorityID, &tasksProject.ProjectID, &tasksProject.CreatedAt,
-			&tasksProject.UpdatedAt, &tasksProject.DeletedAt,
+			&tasksProject.PriorityID, &tasksProject.ProjectID, &tasksProject.StoryPoints, &tasksProject.EstimateHours,
+			&tasksProject.StatusID, &tasksProject.SprintID, &tasksProject.MilestoneID, &tasksProject.CompletedAt, &tasksProject.DueDate, &tasksProject.CreatedAt, &tasksProject.UpdatedAt, &tasksProject.DeletedAt,
 			&userFirstName, &userLastName, &userAge, &userEmail,
-			&priorityName, &priorityDescription,
+			&priorityName, &priorityDescription, &priorityRank, &priorityColor,
 		)
 
 		if err != nil {
@@ -82,6 +134,8 @@ func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error)
 			ID:          tasksProject.PriorityID,
 			Name:        priorityName,
 			Description: priorityDescription,
+			Rank:        priorityRank,
+			Color:       priorityColor,
 		}
 
 		tasksProjectList = append(tasksProjectList, &tasksProject)
@@ -91,6 +145,29 @@ func (s *Store) GetTasksProject(projectId int) ([]*entities.TasksProject, error)
 		return nil, fmt.Errorf("failed to iterate over tasksProject rows: %v", err)
 	}
 
+	taskIDs := make([]int, 0, len(tasksProjectList))
+	for _, tasksProject := range tasksProjectList {
+		taskIDs = append(taskIDs, tasksProject.ID)
+	}
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetProjectTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	valuesMap, err := customfields.GetValuesFor(s.db, entities.CustomFieldTargetProjectTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, tasksProject := range tasksProjectList {
+		tasksProject.Labels = labelsMap[tasksProject.ID]
+		if tasksProject.Labels == nil {
+			tasksProject.Labels = []entities.Label{}
+		}
+		tasksProject.CustomFields = valuesMap[tasksProject.ID]
+		if tasksProject.CustomFields == nil {
+			tasksProject.CustomFields = []entities.CustomFieldValue{}
+		}
+	}
+
 	return tasksProjectList, nil
 }
 
@@ -98,7 +175,7 @@ func (s *Store) GetTaskProject(taskId int) (*entities.TasksProject, error) {
 	// SQL query to get a single task with related user, priority, and project details
 	query := fmt.Sprintf(`
         SELECT 
-			pt.id, pt.name, pt.description, pt.userId, pt.priorityId, pt.projectId, pt.createdAt, pt.updatedAt, pt.deletedAt, pt.deletedBy,
+			pt.id, pt.name, pt.description, pt.userId, pt.priorityId, pt.projectId, pt.storyPoints, pt.estimateHours, pt.statusId, pt.sprintId, pt.milestoneId, pt.completedAt, pt.dueDate, pt.createdAt, pt.updatedAt, pt.deletedAt, pt.deletedBy,
 			u.firstName user_firstname, u.lastName user_lastname, u.age user_age, u.email user_email,
 			p.name priority_name, p.description priority_description,
 			pr.name project_name, pr.description project_description, pr.progress project_progress, pr.url project_url, pr.dateStarted project_dateStarted, pr.dateDeadline project_dateDeadline
@@ -121,7 +198,8 @@ func (s *Store) GetTaskProject(taskId int) (*entities.TasksProject, error) {
 
 	// Scan the result into the TasksProject and related User, Priority, and Project fields
 	err := row.Scan(
-		&tasksProject.ID, &tasksProject.Name, &tasksProject.Description, &tasksProject.UserID, &tasksProject.PriorityID, &tasksProject.ProjectID, &tasksProject.CreatedAt,
+		&tasksProject.ID, &tasksProject.Name, &tasksProject.Description, &tasksProject.UserID, &tasksProject.PriorityID, &tasksProject.ProjectID,
+		&tasksProject.StoryPoints, &tasksProject.EstimateHours, &tasksProject.StatusID, &tasksProject.SprintID, &tasksProject.MilestoneID, &tasksProject.CompletedAt, &tasksProject.DueDate, &tasksProject.CreatedAt,
 		&tasksProject.UpdatedAt, &tasksProject.DeletedAt, &tasksProject.DeletedBy,
 
 		&userFirstName, &userLastName, &userAge, &userEmail,
@@ -169,6 +247,24 @@ func (s *Store) GetTaskProject(taskId int) (*entities.TasksProject, error) {
 		}
 	}
 
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetProjectTask, []int{tasksProject.ID})
+	if err != nil {
+		return nil, err
+	}
+	tasksProject.Labels = labelsMap[tasksProject.ID]
+	if tasksProject.Labels == nil {
+		tasksProject.Labels = []entities.Label{}
+	}
+
+	valuesMap, err := customfields.GetValuesFor(s.db, entities.CustomFieldTargetProjectTask, []int{tasksProject.ID})
+	if err != nil {
+		return nil, err
+	}
+	tasksProject.CustomFields = valuesMap[tasksProject.ID]
+	if tasksProject.CustomFields == nil {
+		tasksProject.CustomFields = []entities.CustomFieldValue{}
+	}
+
 	return tasksProject, nil
 }
 
@@ -186,19 +282,49 @@ func (s *Store) TasksProjectCreate(payload entities.TasksProjectCreatePayload) (
 		}
 	}()
 
+	dueDate, err := utils.ParseOptionalDate(payload.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tasksProject, err := CreateTaskProject(tx, payload, dueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return tasksProject, nil
+}
+
+// CreateTaskProject inserts a project task due on dueDate and records its
+// webhook event in tx
+func CreateTaskProject(tx *sql.Tx, payload entities.TasksProjectCreatePayload, dueDate *time.Time) (*entities.TasksProject, error) {
+	orgId, err := checkReferences(tx, payload.ProjectID, payload.PriorityID, payload.UserID, payload.StatusID)
+	if err != nil {
+		return nil, err
+	}
+
 	tasksProject := entities.TasksProject{}
 	query := `
-		INSERT INTO project_tasks (name, description, userId, priorityId, projectId, createdAt, updatedAt)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, name, description, userId, priorityId, createdAt, updatedAt
+		INSERT INTO project_tasks (name, description, userId, priorityId, projectId, statusId, storyPoints, estimateHours, dueDate, createdAt, updatedAt)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, name, description, userId, priorityId, projectId, statusId, storyPoints, estimateHours, dueDate, createdAt, updatedAt
 	`
 	err = tx.QueryRow(
 		query,
 		payload.Name,
 		payload.Description,
-		payload.UserID,
+		sql.NullInt64{Int64: int64(payload.UserID), Valid: payload.UserID != 0},
 		payload.PriorityID,
 		payload.ProjectID,
+		payload.StatusID,
+		payload.StoryPoints,
+		payload.EstimateHours,
+		dueDate,
 		time.Now(),
 		time.Now(),
 	).Scan(
@@ -207,6 +333,11 @@ func (s *Store) TasksProjectCreate(payload entities.TasksProjectCreatePayload) (
 		&tasksProject.Description,
 		&tasksProject.UserID,
 		&tasksProject.PriorityID,
+		&tasksProject.ProjectID,
+		&tasksProject.StatusID,
+		&tasksProject.StoryPoints,
+		&tasksProject.EstimateHours,
+		&tasksProject.DueDate,
 		&tasksProject.CreatedAt,
 		&tasksProject.UpdatedAt,
 	)
@@ -214,11 +345,19 @@ func (s *Store) TasksProjectCreate(payload entities.TasksProjectCreatePayload) (
 		return nil, fmt.Errorf("failed to insert tasksProject: %w", err)
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	if err = customfields.SaveValues(tx, entities.CustomFieldTargetProjectTask, tasksProject.ID, payload.CustomFields); err != nil {
+		return nil, err
 	}
 
+	assignment := history.Changes{}
+	assignment.Add("userId", nil, tasksProject.UserID)
+	if err = notifications.NotifyTaskChanges(tx, entities.WatchTargetProjectTask, tasksProject.ID, tasksProject.Name, payload.CreatedBy, assignment); err != nil {
+		return nil, err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectTaskCreated, tasksProject); err != nil {
+		return nil, err
+	}
 	return &tasksProject, nil
 }
 
@@ -229,17 +368,84 @@ func (s *Store) TasksProjectUpdate(payload entities.TasksProjectUpdatePayload) e
 		return err
 	}
 
+	old := entities.TasksProject{}
+	err = tx.QueryRow(`
+		SELECT name, description, userId, priorityId, projectId, statusId, storyPoints, estimateHours, dueDate, sprintId, completedAt
+		FROM project_tasks WHERE id = $1 FOR UPDATE
+	`, payload.ID).Scan(&old.Name, &old.Description, &old.UserID, &old.PriorityID, &old.ProjectID, &old.StatusID, &old.StoryPoints, &old.EstimateHours, &old.DueDate, &old.SprintID, &old.CompletedAt)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("task with ID %d not found", payload.ID)
+		}
+		return err
+	}
+
+	dueDate, err := utils.ParseOptionalDate(payload.DueDate)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	orgId, err := checkReferences(tx, payload.ProjectID, payload.PriorityID, payload.UserID, payload.StatusID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	statusId := old.StatusID
+	if payload.StatusID != nil {
+		statusId = payload.StatusID
+	}
+	payload.Completed, err = workflows.Completion(tx, old.StatusID, statusId, payload.Completed)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	wasCompleted := old.CompletedAt != nil
+	completed := wasCompleted
+	if payload.Completed != nil {
+		completed = *payload.Completed
+	}
+
+	// An unassigned task stays unassigned when no user is sent
+	var userId *int
+	if payload.UserID != 0 {
+		userId = &payload.UserID
+	}
+
+	changes := history.Changes{}
+	changes.Add("name", old.Name, payload.Name)
+	changes.Add("description", old.Description, payload.Description)
+	changes.Add("userId", old.UserID, userId)
+	changes.Add("priorityId", old.PriorityID, payload.PriorityID)
+	changes.Add("projectId", old.ProjectID, payload.ProjectID)
+	changes.Add("statusId", old.StatusID, statusId)
+	changes.Add("storyPoints", old.StoryPoints, payload.StoryPoints)
+	changes.Add("estimateHours", old.EstimateHours, payload.EstimateHours)
+	changes.Add("dueDate", old.DueDate, dueDate)
+	changes.Add("completed", wasCompleted, completed)
+
 	_, err = tx.Exec(`
 		UPDATE project_tasks 
-		SET name = $1, description = $2, userId = $3, priorityId = $4, projectId = $5, updatedAt = CURRENT_TIMESTAMP 
-		WHERE id = $6
+		SET name = $1, description = $2, userId = $3, priorityId = $4, projectId = $5, storyPoints = $6, estimateHours = $7,
+			completedAt = CASE WHEN $8::BOOLEAN IS NULL THEN completedAt WHEN $8 THEN COALESCE(completedAt, CURRENT_TIMESTAMP) ELSE NULL END,
+			dueDate = $9, statusId = $11,
+			updatedAt = CURRENT_TIMESTAMP 
+		WHERE id = $10
 		`,
 		payload.Name,
 		payload.Description,
-		payload.UserID,
+		userId,
 		payload.PriorityID,
 		payload.ProjectID,
+		payload.StoryPoints,
+		payload.EstimateHours,
+		payload.Completed,
+		dueDate,
 		payload.ID,
+		statusId,
 	)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -248,6 +454,49 @@ func (s *Store) TasksProjectUpdate(payload entities.TasksProjectUpdatePayload) e
 		return err
 	}
 
+	if err = workflows.Enforce(tx, orgId, entities.WorkflowTargetProjectTask, payload.ID, old.StatusID, statusId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = customfields.SaveValues(tx, entities.CustomFieldTargetProjectTask, payload.ID, payload.CustomFields); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = history.Record(tx, entities.HistoryTargetProjectTask, payload.ID, payload.UpdatedBy, changes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = notifications.NotifyTaskChanges(tx, entities.WatchTargetProjectTask, payload.ID, payload.Name, payload.UpdatedBy, changes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectTaskUpdated, map[string]interface{}{"id": payload.ID, "changes": changes}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if old.SprintID != nil && utils.IntChanged(old.StoryPoints, payload.StoryPoints) {
+		if err = sprints.RecordTaskEvent(tx, *old.SprintID, payload.ID, "estimated", payload.StoryPoints); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if old.SprintID != nil && completed != wasCompleted {
+		event := "reopened"
+		if completed {
+			event = "completed"
+		}
+		if err = sprints.RecordTaskEvent(tx, *old.SprintID, payload.ID, event, payload.StoryPoints); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return err
 	}
@@ -260,12 +509,32 @@ func (s *Store) TasksProjectDelete(id int) error {
 		return err
 	}
 
-	_, err = tx.Exec("UPDATE project_tasks SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", id)
+	var sprintId, storyPoints *int
+	err = tx.QueryRow("UPDATE project_tasks SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 RETURNING sprintId, storyPoints", id).Scan(&sprintId, &storyPoints)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return fmt.Errorf("error deleting tasksProject: %v, rollback error: %v", err, rollbackErr)
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("project task with ID %d not found", id)
 		}
-		return nil
+		return fmt.Errorf("error deleting tasksProject: %v", err)
+	}
+
+	if sprintId != nil {
+		if err = sprints.RecordTaskDeleted(tx, *sprintId, id, storyPoints); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	orgId, err := orgOf(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectTaskDeleted, map[string]int{"id": id}); err != nil {
+		tx.Rollback()
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -298,6 +567,17 @@ func (s *Store) TasksProjectRestore(id int) (*entities.TasksProject, error) {
 		return nil, err
 	}
 
+	orgId, err := orgOf(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = webhooks.Enqueue(tx, orgId, entities.WebhookEventProjectTaskRestored, tasksProject); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return &tasksProject, nil
 	}
@@ -317,3 +597,41 @@ func scanRowIntoTasksProject(rows *sql.Rows, tasksProject *entities.TasksProject
 		&tasksProject.DeletedBy,
 	)
 }
+
+// checkReferences makes sure the priority, assignee and status of a task
+// belong to the organization of its project, which it returns
+func checkReferences(tx *sql.Tx, projectId, priorityId, userId int, statusId *int) (int, error) {
+	var orgId int
+	if err := tx.QueryRow(`SELECT orgId FROM projects WHERE id = $1`, projectId).Scan(&orgId); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("project with ID %d not found", projectId)
+		}
+		return 0, fmt.Errorf("failed to retrieve project organization: %v", err)
+	}
+	if err := organizations.CheckOwned(tx, orgId, entities.TenantPriorities, []int{priorityId}); err != nil {
+		return 0, err
+	}
+	if userId != 0 {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantUsers, []int{userId}); err != nil {
+			return 0, err
+		}
+	}
+	if statusId != nil {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantStatuses, []int{*statusId}); err != nil {
+			return 0, err
+		}
+	}
+	return orgId, nil
+}
+
+// orgOf returns the organization owning the project of a project task
+func orgOf(tx *sql.Tx, taskId int) (int, error) {
+	var orgId int
+	err := tx.QueryRow(`
+		SELECT p.orgId FROM project_tasks t JOIN projects p ON p.id = t.projectId WHERE t.id = $1
+	`, taskId).Scan(&orgId)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("project task with ID %d not found", taskId)
+	}
+	return orgId, err
+}
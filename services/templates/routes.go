@@ -0,0 +1,15 @@
+package templates
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/templates", h.handleGetTemplates, "GET")
+	utils.SecureRoute(router, "/templates/{templateId}", h.handleGetTemplate, "GET")
+	utils.SecureRoute(router, "/templates/{templateId}", h.handleDeleteTemplate, "DELETE")
+	utils.SecureRoute(router, "/templates/{templateId}/projects", h.handleCreateFromTemplate, "POST")
+	utils.SecureRoute(router, "/projects/{projectId}/templates", h.handleSaveTemplate, "POST")
+	utils.SecureRoute(router, "/projects/{projectId}/clone", h.handleCloneProject, "POST")
+}
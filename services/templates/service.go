@@ -0,0 +1,221 @@
+package templates
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.TemplateStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.TemplateStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetTemplates(w http.ResponseWriter, r *http.Request) {
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	templates, err := h.store.GetTemplates(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range templates {
+		hideMembers(&templates[i], memberId)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": templates})
+}
+
+func (h *Handler) handleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid template ID"))
+		return
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	template, err := h.store.GetTemplate(utils.GetOrgID(r), templateId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	hideMembers(template, memberId)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": template})
+}
+
+func (h *Handler) handleSaveTemplate(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload := entities.TemplateSavePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleMaintainer) {
+		return
+	}
+	payload.ProjectID = projectId
+	payload.OrgID = utils.GetOrgID(r)
+	payload.CreatedBy = utils.GetUserID(r)
+
+	template, err := h.store.SaveTemplate(payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": template})
+}
+
+func (h *Handler) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid template ID"))
+		return
+	}
+
+	orgId := utils.GetOrgID(r)
+	template, err := h.store.GetTemplate(orgId, templateId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	// The author can remove their own template, anyone else needs to be an admin
+	if !isAuthor(r, template) && !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	if err := h.store.DeleteTemplate(orgId, templateId); err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Template Successfully!"})
+}
+
+func (h *Handler) handleCreateFromTemplate(w http.ResponseWriter, r *http.Request) {
+	templateId, err := strconv.Atoi(mux.Vars(r)["templateId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid template ID"))
+		return
+	}
+
+	payload, ok := parseProjectPayload(w, r)
+	if !ok {
+		return
+	}
+
+	// Copying members is limited to those who may see them in the template
+	if payload.IncludeMembers {
+		template, err := h.store.GetTemplate(payload.OrgID, templateId)
+		if err != nil {
+			utils.WriteError(w, http.StatusNotFound, err)
+			return
+		}
+		if !isAuthor(r, template) && !members.AuthorizeAdmin(w, r, h.access) {
+			return
+		}
+	}
+
+	project, err := h.store.CreateFromTemplate(templateId, payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": project})
+}
+
+func (h *Handler) handleCloneProject(w http.ResponseWriter, r *http.Request) {
+	projectId, err := strconv.Atoi(mux.Vars(r)["projectId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid project ID"))
+		return
+	}
+
+	payload, ok := parseProjectPayload(w, r)
+	if !ok {
+		return
+	}
+
+	// Copying members exposes who works on the source, so it needs a maintainer
+	minRole := entities.ProjectRoleMember
+	if payload.IncludeMembers {
+		minRole = entities.ProjectRoleMaintainer
+	}
+	if !members.Authorize(w, r, h.access, projectId, minRole) {
+		return
+	}
+
+	project, err := h.store.CloneProject(projectId, payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": project})
+}
+
+// hideMembers leaves out who worked on the source project, and the tasks they
+// were assigned, unless memberId is nil for admins or the template's author
+func hideMembers(template *entities.ProjectTemplate, memberId *int) {
+	if memberId == nil || (template.CreatedBy != nil && *template.CreatedBy == *memberId) {
+		return
+	}
+	template.Definition.Members = nil
+	for i := range template.Definition.Tasks {
+		template.Definition.Tasks[i].UserID = nil
+	}
+}
+
+func isAuthor(r *http.Request, template *entities.ProjectTemplate) bool {
+	userId := utils.GetUserID(r)
+	return template.CreatedBy != nil && userId != nil && *template.CreatedBy == *userId
+}
+
+func parseProjectPayload(w http.ResponseWriter, r *http.Request) (entities.ProjectFromTemplatePayload, bool) {
+	payload := entities.ProjectFromTemplatePayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return payload, false
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return payload, false
+	}
+	payload.OrgID = utils.GetOrgID(r)
+	payload.CreatedBy = utils.GetUserID(r)
+	return payload, true
+}
@@ -0,0 +1,470 @@
+package templates
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/outbox"
+	"github.com/norrico31/it210-core-service-backend/services/webhooks"
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) GetTemplates(orgId int) ([]entities.ProjectTemplate, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, description, sourceProjectId, includesMembers, definition, createdBy, createdAt, updatedAt
+		FROM project_templates
+		WHERE orgId = $1 AND deletedAt IS NULL
+		ORDER BY LOWER(name), id
+	`, orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query templates: %v", err)
+	}
+	defer rows.Close()
+
+	templates := []entities.ProjectTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over template rows: %v", err)
+	}
+	return templates, nil
+}
+
+func (s *Store) GetTemplate(orgId, id int) (*entities.ProjectTemplate, error) {
+	template, err := scanTemplate(s.db.QueryRow(`
+		SELECT id, name, description, sourceProjectId, includesMembers, definition, createdBy, createdAt, updatedAt
+		FROM project_templates
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, id, orgId))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template with ID %d not found", id)
+	}
+	return template, err
+}
+
+// SaveTemplate snapshots the project of the payload as a new template
+func (s *Store) SaveTemplate(payload entities.TemplateSavePayload) (*entities.ProjectTemplate, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	definition, err := snapshot(tx, payload.ProjectID, payload.IncludeMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode template: %v", err)
+	}
+
+	template := entities.ProjectTemplate{}
+	err = tx.QueryRow(`
+		INSERT INTO project_templates (name, description, orgId, sourceProjectId, includesMembers, definition, createdBy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, name, description, sourceProjectId, includesMembers, createdBy, createdAt, updatedAt
+	`, payload.Name, payload.Description, payload.OrgID, payload.ProjectID, payload.IncludeMembers, string(data), payload.CreatedBy).Scan(
+		&template.ID, &template.Name, &template.Description, &template.SourceProjectID,
+		&template.IncludesMembers, &template.CreatedBy, &template.CreatedAt, &template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save template: %v", err)
+	}
+	template.Definition = *definition
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (s *Store) DeleteTemplate(orgId, id int) error {
+	result, err := s.db.Exec(`
+		UPDATE project_templates SET deletedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, id, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("template with ID %d not found", id)
+	}
+	return nil
+}
+
+func (s *Store) CreateFromTemplate(templateId int, payload entities.ProjectFromTemplatePayload) (*entities.Project, error) {
+	template, err := s.GetTemplate(payload.OrgID, templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := instantiate(tx, &template.Definition, payload)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// CloneProject copies a project into a new one; the snapshot and the copy
+// happen in the same transaction
+func (s *Store) CloneProject(projectId int, payload entities.ProjectFromTemplatePayload) (*entities.Project, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = organizations.CheckOwned(tx, payload.OrgID, entities.TenantProjects, []int{projectId}); err != nil {
+		return nil, err
+	}
+
+	definition, err := snapshot(tx, projectId, payload.IncludeMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := instantiate(tx, definition, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// snapshot reads the structure of a project. Deleted columns, tasks and
+// labels are left out, and so are assignees unless members are included.
+func snapshot(tx *sql.Tx, projectId int, includeMembers bool) (*entities.TemplateDefinition, error) {
+	definition := entities.TemplateDefinition{
+		Workspaces:      []entities.TemplateWorkspace{},
+		Labels:          []entities.TemplateLabel{},
+		ProjectLabelIDs: []int{},
+		Tasks:           []entities.TemplateTask{},
+	}
+
+	err := tx.QueryRow(`SELECT statusId FROM projects WHERE id = $1 AND deletedAt IS NULL`, projectId).Scan(&definition.StatusID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("project with ID %d not found", projectId)
+		}
+		return nil, fmt.Errorf("failed to retrieve project: %v", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT name, COALESCE(description, ''), colOrder FROM workspaces
+		WHERE projectId = $1 AND deletedAt IS NULL
+		ORDER BY colOrder, id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %v", err)
+	}
+	for rows.Next() {
+		workspace := entities.TemplateWorkspace{}
+		if err := rows.Scan(&workspace.Name, &workspace.Description, &workspace.ColOrder); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan workspace: %v", err)
+		}
+		definition.Workspaces = append(definition.Workspaces, workspace)
+	}
+	rows.Close()
+
+	// Every label used by the project or its tasks, global ones included
+	rows, err = tx.Query(`
+		SELECT l.id, l.name, l.color, l.projectId IS NULL FROM labels l
+		WHERE l.deletedAt IS NULL AND (
+			l.projectId = $1
+			OR l.id IN (SELECT labelId FROM labels_projects WHERE projectId = $1)
+			OR l.id IN (
+				SELECT lpt.labelId FROM labels_project_tasks lpt
+				JOIN project_tasks pt ON pt.id = lpt.projectTaskId
+				WHERE pt.projectId = $1 AND pt.deletedAt IS NULL
+			)
+		)
+		ORDER BY l.id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query labels: %v", err)
+	}
+	for rows.Next() {
+		label := entities.TemplateLabel{}
+		if err := rows.Scan(&label.ID, &label.Name, &label.Color, &label.Global); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan label: %v", err)
+		}
+		definition.Labels = append(definition.Labels, label)
+	}
+	rows.Close()
+
+	rows, err = tx.Query(`
+		SELECT lp.labelId FROM labels_projects lp JOIN labels l ON l.id = lp.labelId AND l.deletedAt IS NULL
+		WHERE lp.projectId = $1 ORDER BY lp.labelId
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project labels: %v", err)
+	}
+	for rows.Next() {
+		var labelId int
+		if err := rows.Scan(&labelId); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan project label: %v", err)
+		}
+		definition.ProjectLabelIDs = append(definition.ProjectLabelIDs, labelId)
+	}
+	rows.Close()
+
+	rows, err = tx.Query(`
+		SELECT pt.name, COALESCE(pt.description, ''), pt.priorityId, pt.storyPoints, pt.estimateHours, pt.userId,
+			COALESCE(ARRAY(
+				SELECT lpt.labelId FROM labels_project_tasks lpt JOIN labels l ON l.id = lpt.labelId AND l.deletedAt IS NULL
+				WHERE lpt.projectTaskId = pt.id ORDER BY lpt.labelId
+			), '{}')
+		FROM project_tasks pt
+		WHERE pt.projectId = $1 AND pt.deletedAt IS NULL
+		ORDER BY pt.id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project tasks: %v", err)
+	}
+	for rows.Next() {
+		task := entities.TemplateTask{}
+		var labelIds []int64
+		if err := rows.Scan(&task.Name, &task.Description, &task.PriorityID, &task.StoryPoints, &task.EstimateHours, &task.UserID, pq.Array(&labelIds)); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan project task: %v", err)
+		}
+		if !includeMembers {
+			task.UserID = nil
+		}
+		task.LabelIDs = []int{}
+		for _, id := range labelIds {
+			task.LabelIDs = append(task.LabelIDs, int(id))
+		}
+		definition.Tasks = append(definition.Tasks, task)
+	}
+	rows.Close()
+
+	if !includeMembers {
+		return &definition, nil
+	}
+
+	rows, err = tx.Query(`
+		SELECT up.user_id, up.role FROM users_projects up
+		JOIN users u ON u.id = up.user_id AND u.deletedAt IS NULL
+		WHERE up.project_id = $1 AND up.deletedAt IS NULL
+		ORDER BY up.user_id
+	`, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query members: %v", err)
+	}
+	defer rows.Close()
+	definition.Members = []entities.TemplateMember{}
+	for rows.Next() {
+		member := entities.TemplateMember{}
+		if err := rows.Scan(&member.UserID, &member.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %v", err)
+		}
+		definition.Members = append(definition.Members, member)
+	}
+	return &definition, rows.Err()
+}
+
+// instantiate creates a project from a definition. Members and assignees are
+// only copied when the payload asks for them, and only if they still belong to
+// the organization; the caller becomes the owner.
+func instantiate(tx *sql.Tx, definition *entities.TemplateDefinition, payload entities.ProjectFromTemplatePayload) (*entities.Project, error) {
+	if payload.StatusID == 0 {
+		payload.StatusID = definition.StatusID
+	}
+
+	if err := organizations.CheckOwned(tx, payload.OrgID, entities.TenantStatuses, []int{payload.StatusID}); err != nil {
+		return nil, err
+	}
+	if payload.SegmentID != nil {
+		if err := organizations.CheckOwned(tx, payload.OrgID, entities.TenantSegments, []int{*payload.SegmentID}); err != nil {
+			return nil, err
+		}
+	}
+
+	project := entities.Project{}
+	err := tx.QueryRow(`
+		INSERT INTO projects (name, description, progress, statusId, orgId)
+		VALUES ($1, $2, 0, $3, $4)
+		RETURNING id, name, description, progress, url, statusId, dateStarted, dateDeadline, createdAt, updatedAt
+	`, payload.Name, payload.Description, payload.StatusID, payload.OrgID).Scan(
+		&project.ID, &project.Name, &project.Description, &project.Progress, &project.Url,
+		&project.StatusID, &project.DateStarted, &project.DateDeadline, &project.CreatedAt, &project.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %v", err)
+	}
+
+	if payload.SegmentID != nil {
+		_, err = tx.Exec(`INSERT INTO segments_projects (segmentId, projectId) VALUES ($1, $2)`, *payload.SegmentID, project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to associate segment with project: %v", err)
+		}
+		project.SegmentID = *payload.SegmentID
+	}
+
+	for _, workspace := range definition.Workspaces {
+		_, err = tx.Exec(`
+			INSERT INTO workspaces (name, description, projectId, colOrder) VALUES ($1, $2, $3, $4)
+		`, workspace.Name, workspace.Description, project.ID, workspace.ColOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy workspace %s: %v", workspace.Name, err)
+		}
+	}
+
+	// Project labels are recreated; global labels are reused while they still exist
+	labelIds := map[int]int{}
+	for _, label := range definition.Labels {
+		var newId int
+		if label.Global {
+			err = tx.QueryRow(`
+				SELECT id FROM labels WHERE id = $1 AND projectId IS NULL AND orgId = $2 AND deletedAt IS NULL
+			`, label.ID, payload.OrgID).Scan(&newId)
+			if err == sql.ErrNoRows {
+				continue
+			}
+		} else {
+			err = tx.QueryRow(`
+				INSERT INTO labels (name, color, projectId, orgId) VALUES ($1, $2, $3, $4) RETURNING id
+			`, label.Name, label.Color, project.ID, payload.OrgID).Scan(&newId)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy label %s: %v", label.Name, err)
+		}
+		labelIds[label.ID] = newId
+	}
+
+	for _, labelId := range definition.ProjectLabelIDs {
+		if newId, ok := labelIds[labelId]; ok {
+			if _, err = tx.Exec(`INSERT INTO labels_projects (labelId, projectId) VALUES ($1, $2) ON CONFLICT DO NOTHING`, newId, project.ID); err != nil {
+				return nil, fmt.Errorf("failed to attach label: %v", err)
+			}
+		}
+	}
+
+	if payload.IncludeMembers {
+		for _, member := range definition.Members {
+			// Ownership is not copied, the caller owns the new project
+			role := member.Role
+			if role == entities.ProjectRoleOwner {
+				role = entities.ProjectRoleMaintainer
+			}
+			_, err = tx.Exec(`
+				INSERT INTO users_projects (user_id, project_id, role)
+				SELECT id, $2, $3 FROM users WHERE id = $1 AND orgId = $4 AND deletedAt IS NULL
+				ON CONFLICT (user_id, project_id) DO NOTHING
+			`, member.UserID, project.ID, role, payload.OrgID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy member %d: %v", member.UserID, err)
+			}
+		}
+	}
+
+	if payload.CreatedBy != nil {
+		if err = members.AddOwner(tx, project.ID, *payload.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, task := range definition.Tasks {
+		if err = organizations.CheckOwned(tx, payload.OrgID, entities.TenantPriorities, []int{task.PriorityID}); err != nil {
+			return nil, err
+		}
+
+		// Assignees are kept only if they ended up as members of the new project
+		var taskId int
+		err = tx.QueryRow(`
+			INSERT INTO project_tasks (name, description, userId, priorityId, projectId, storyPoints, estimateHours)
+			VALUES ($1, $2, (SELECT user_id FROM users_projects WHERE project_id = $5 AND user_id = $3 AND $8), $4, $5, $6, $7)
+			RETURNING id
+		`, task.Name, task.Description, task.UserID, task.PriorityID, project.ID, task.StoryPoints, task.EstimateHours, payload.IncludeMembers).Scan(&taskId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy task %s: %v", task.Name, err)
+		}
+
+		for _, labelId := range task.LabelIDs {
+			if newId, ok := labelIds[labelId]; ok {
+				_, err = tx.Exec(`
+					INSERT INTO labels_project_tasks (labelId, projectTaskId) VALUES ($1, $2) ON CONFLICT DO NOTHING
+				`, newId, taskId)
+				if err != nil {
+					return nil, fmt.Errorf("failed to attach label to task %s: %v", task.Name, err)
+				}
+			}
+		}
+	}
+
+	if err = webhooks.Enqueue(tx, payload.OrgID, entities.WebhookEventProjectCreated, project); err != nil {
+		return nil, err
+	}
+	if err = outbox.Record(tx, payload.OrgID, entities.AggregateProject, project.ID, "created", project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTemplate(row rowScanner) (*entities.ProjectTemplate, error) {
+	template := entities.ProjectTemplate{}
+	var definition []byte
+	err := row.Scan(
+		&template.ID, &template.Name, &template.Description, &template.SourceProjectID, &template.IncludesMembers,
+		&definition, &template.CreatedBy, &template.CreatedAt, &template.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan template: %v", err)
+	}
+	if err := json.Unmarshal(definition, &template.Definition); err != nil {
+		return nil, fmt.Errorf("failed to decode template %d: %v", template.ID, err)
+	}
+	return &template, nil
+}
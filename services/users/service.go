@@ -2,6 +2,7 @@ package users
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,16 +11,19 @@ import (
 	"github.com/go-playground/validator"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 // TODO: REFACTO ALL OF THE CRUD HERE
 type Handler struct {
-	store entities.UserStore
+	store  entities.UserStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.UserStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.UserStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
 }
 
 func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
@@ -48,6 +52,7 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 			"firstName":    user.FirstName,
 			"lastName":     user.LastName,
 			"email":        user.Email,
+			"orgId":        user.OrgID,
 			"age":          user.Age,
 			"roleId":       user.RoleId,
 			"role":         user.Role,
@@ -65,7 +70,12 @@ func (h *Handler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
 		fmt.Printf("Header: %s, Value: %v\n", key, values)
 	}
 
-	users, err := h.store.GetUsers()
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	users, err := h.store.GetUsers(utils.GetOrgID(r), memberId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -88,7 +98,11 @@ func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
 		return
 	}
-	user, err := h.store.GetUserById(userId)
+	if !h.authorizeUser(w, r, userId) {
+		return
+	}
+
+	user, err := h.store.GetUserById(utils.GetOrgID(r), userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -97,6 +111,10 @@ func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
 	var payload entities.UserCreatePayload
 
 	if err := utils.ParseJSON(r, &payload); err != nil {
@@ -132,8 +150,13 @@ func (h *Handler) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		RoleId:     payload.RoleId,
 		ProjectIDS: payload.ProjectIDS,
 		Password:   password,
+		OrgID:      utils.GetOrgID(r),
 	})
 
+	if errors.Is(err, organizations.ErrOtherOrganization) {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -164,8 +187,17 @@ func (h *Handler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users may edit their own profile; roles and project assignments are
+	// left to admins
+	currentUserId := utils.GetUserID(r)
+	if currentUserId == nil || *currentUserId != userId || payload.RoleId != nil || payload.ProjectIDS != nil {
+		if !members.AuthorizeAdmin(w, r, h.access) {
+			return
+		}
+	}
+
 	// Fetch existing user
-	userExist, err := h.store.GetUserById(userId)
+	userExist, err := h.store.GetUserById(utils.GetOrgID(r), userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to fetch user: %v", err))
 		return
@@ -225,7 +257,11 @@ func (h *Handler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update user in the store
-	err = h.store.UpdateUser(userId, user, projectIDs)
+	err = h.store.UpdateUser(utils.GetOrgID(r), userId, user, projectIDs)
+	if errors.Is(err, organizations.ErrOtherOrganization) {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, fmt.Errorf("failed to update user: %v", err))
 		return
@@ -248,7 +284,11 @@ func (h *Handler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.DeleteUser(userId)
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	err = h.store.DeleteUser(utils.GetOrgID(r), userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -271,7 +311,11 @@ func (h *Handler) handleRestoreUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.store.RestoreUser(userId)
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	err = h.store.RestoreUser(utils.GetOrgID(r), userId)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -295,3 +339,26 @@ func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	err = h.store.UpdateLastActiveTime(userId, now)
 }
+
+// authorizeUser lets users see themselves and the people they share a project
+// with; admins see everyone.
+func (h *Handler) authorizeUser(w http.ResponseWriter, r *http.Request, userId int) bool {
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return false
+	}
+	if memberId == nil || *memberId == userId {
+		return true
+	}
+
+	shares, err := h.access.SharesProject(*memberId, userId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if !shares {
+		utils.WriteError(w, http.StatusNotFound, fmt.Errorf("user with ID %d not found", userId))
+		return false
+	}
+	return true
+}
@@ -6,7 +6,11 @@ import (
 	"log"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+	"github.com/norrico31/it210-core-service-backend/services/outbox"
 	"github.com/norrico31/it210-core-service-backend/utils"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -28,6 +32,7 @@ func (s *Store) Login(payload entities.UserLoginPayload) (string, entities.User,
 			u.firstName user_firstname, 
 			u.lastName user_lastname, 
 			u.email user_email, 
+			u.orgId user_org_id,
 			u.password user_password, 
 			u.age user_age, 
 			u.roleId user_role_id, 
@@ -39,12 +44,14 @@ func (s *Store) Login(payload entities.UserLoginPayload) (string, entities.User,
 			r.id role_id, r.name role_name, r.description role_description, r.createdAt role_createdAt, r.updatedAt role_updatedAt, r.deletedAt role_deletedAt
 
 		FROM users u
+		JOIN
+			organizations o ON o.id = u.orgId AND o.deletedAt IS NULL
 		LEFT JOIN
 			roles r ON r.id = u.roleId
 		WHERE u.email = $1 AND u.deletedAt IS NULL
 
 	`, payload.Email).Scan(
-		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password,
+		&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.OrgID, &user.Password,
 		&user.Age, &user.RoleId, &user.LastActiveAt, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt,
 		&role.ID, &role.Name, &role.Description, &role.CreatedAt, &role.UpdatedAt, &role.DeletedAt,
 	)
@@ -76,7 +83,10 @@ func (s *Store) Login(payload entities.UserLoginPayload) (string, entities.User,
 	return token, user, nil
 }
 
-func (s *Store) GetUsers() ([]*entities.User, error) {
+// GetUsers lists every user of the organization, or when memberId is set only
+// that user and the users sharing a project with them, along with the
+// projects they share.
+func (s *Store) GetUsers(orgId int, memberId *int) ([]*entities.User, error) {
 	rows, err := s.db.Query(`
         SELECT 
             u.id AS user_id,
@@ -113,11 +123,13 @@ func (s *Store) GetUsers() ([]*entities.User, error) {
         FROM users u
 		LEFT JOIN roles r ON r.id = u.roleId
         LEFT JOIN users_projects up ON u.id = up.user_id
+			AND ($1::INT IS NULL OR `+members.MemberCondition("up.project_id", "$1")+`)
         LEFT JOIN projects p ON up.project_id = p.id
 		LEFT JOIN statuses s ON s.id = p.statusId
-        WHERE u.deletedAt IS NULL
+        WHERE u.deletedAt IS NULL AND u.orgId = $2
+			AND ($1::INT IS NULL OR u.id = $1 OR up.user_id IS NOT NULL)
         ORDER BY u.id, p.id;
-    `)
+    `, memberId, orgId)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to query users and projects: %v", err)
@@ -235,7 +247,7 @@ func (s *Store) GetUsers() ([]*entities.User, error) {
 	return userList, nil
 }
 
-func (s *Store) GetUserById(id int) (*entities.User, error) {
+func (s *Store) GetUserById(orgId, id int) (*entities.User, error) {
 	rows, err := s.db.Query(`
 		SELECT 
 			u.id AS user_id,
@@ -282,10 +294,10 @@ func (s *Store) GetUserById(id int) (*entities.User, error) {
 		LEFT JOIN statuses s ON s.id = p.statusId
 		LEFT JOIN segments_projects sp ON sp.projectId = p.id
 		LEFT JOIN segments seg ON seg.id = sp.segmentId
-		WHERE u.id = $1 AND u.deletedAt IS NULL
+		WHERE u.id = $1 AND u.orgId = $2 AND u.deletedAt IS NULL
 		ORDER BY p.id;
 
-	`, id)
+	`, id, orgId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user by id: %v", err)
 	}
@@ -348,6 +360,7 @@ func (s *Store) GetUserById(id int) (*entities.User, error) {
 
 		// Set user attributes
 		user.ID = userID
+		user.OrgID = orgId
 		if userAge != nil {
 			user.Age = *userAge
 		}
@@ -468,10 +481,15 @@ func (s *Store) CreateUser(payload entities.UserCreatePayload) error {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 
+	if err = checkReferences(tx, payload.OrgID, payload.RoleId, payload.ProjectIDS); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	var userID int
 	err = tx.QueryRow(`
-		INSERT INTO users (firstName, lastName, email, roleId, age, password, createdAt, updatedAt) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (firstName, lastName, email, roleId, age, password, createdAt, updatedAt, orgId) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id
 	`,
 		payload.FirstName,
@@ -482,6 +500,7 @@ func (s *Store) CreateUser(payload entities.UserCreatePayload) error {
 		payload.Password,
 		time.Now(),
 		time.Now(),
+		payload.OrgID,
 	).Scan(&userID)
 
 	if err != nil {
@@ -509,6 +528,19 @@ func (s *Store) CreateUser(payload entities.UserCreatePayload) error {
 		}
 	}
 
+	err = outbox.Record(tx, payload.OrgID, entities.AggregateUser, userID, "created", map[string]interface{}{
+		"id":         userID,
+		"firstName":  payload.FirstName,
+		"lastName":   payload.LastName,
+		"email":      payload.Email,
+		"roleId":     payload.RoleId,
+		"projectIds": payload.ProjectIDS,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Commit the transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("transaction commit error: %v", err)
@@ -518,16 +550,21 @@ func (s *Store) CreateUser(payload entities.UserCreatePayload) error {
 }
 
 // TODO: ADD USERID IN SEGMENT and OTHER RELATIONS HERE
-func (s *Store) UpdateUser(userId int, user entities.UserUpdatePayload, projectIDs []int) error {
+func (s *Store) UpdateUser(orgId, userId int, user entities.UserUpdatePayload, projectIDs []int) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %v", err)
 	}
 
-	_, err = tx.Exec(`
+	if err = checkReferences(tx, orgId, user.RoleId, &projectIDs); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	result, err := tx.Exec(`
 		UPDATE users
 		SET firstName = $1, lastName = $2, email = $3, roleId = $4, age = $5, password = $6, updatedAt = $7
-		WHERE id = $8
+		WHERE id = $8 AND orgId = $9
 	`,
 		user.FirstName,
 		user.LastName,
@@ -537,13 +574,22 @@ func (s *Store) UpdateUser(userId int, user entities.UserUpdatePayload, projectI
 		user.Password,
 		time.Now(),
 		userId,
+		orgId,
 	)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to update user: %v", err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("user with id %d not found", userId)
+	}
 
-	_, err = tx.Exec(`DELETE FROM users_projects WHERE user_id = $1`, userId)
+	// Roles in the projects the user stays in are preserved and ownerships are
+	// only given up through the members endpoints
+	_, err = tx.Exec(`
+		DELETE FROM users_projects WHERE user_id = $1 AND NOT (project_id = ANY($2)) AND role <> $3
+	`, userId, pq.Array(projectIDs), entities.ProjectRoleOwner)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to clear project associations: %v", err)
@@ -553,6 +599,7 @@ func (s *Store) UpdateUser(userId int, user entities.UserUpdatePayload, projectI
 		_, err = tx.Exec(`
 			INSERT INTO users_projects (user_id, project_id)
 			VALUES ($1, $2)
+			ON CONFLICT (user_id, project_id) DO UPDATE SET deletedAt = NULL, deletedBy = NULL
 		`, userId, projID)
 		if err != nil {
 			tx.Rollback()
@@ -587,6 +634,19 @@ func (s *Store) UpdateUser(userId int, user entities.UserUpdatePayload, projectI
 	// 	}
 	// }
 
+	err = outbox.Record(tx, orgId, entities.AggregateUser, userId, "updated", map[string]interface{}{
+		"id":         userId,
+		"firstName":  user.FirstName,
+		"lastName":   user.LastName,
+		"email":      user.Email,
+		"roleId":     user.RoleId,
+		"projectIds": projectIDs,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("transaction commit error: %v", err)
 	}
@@ -594,18 +654,22 @@ func (s *Store) UpdateUser(userId int, user entities.UserUpdatePayload, projectI
 	return nil
 }
 
-func (s *Store) DeleteUser(userId int) error {
+func (s *Store) DeleteUser(orgId, userId int) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 
 	// TODO: ADD THE USER WHO DELETED DATA
-	_, err = tx.Exec("UPDATE users SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1", userId)
+	result, err := tx.Exec("UPDATE users SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 AND orgId = $2", userId, orgId)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete user with project %d: %v", userId, err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("user with id %d not found", userId)
+	}
 	// TODO: ADD THE USER WHO DELETED DATA
 
 	_, err = tx.Exec("UPDATE users_projects SET deletedAt = CURRENT_TIMESTAMP WHERE user_id = $1", userId)
@@ -613,24 +677,33 @@ func (s *Store) DeleteUser(userId int) error {
 		tx.Rollback()
 		return fmt.Errorf("failed to delete user with project %d: %v", userId, err)
 	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateUser, userId, "deleted", map[string]int{"id": userId}); err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("transaction commit error: %v", err)
 	}
 	return nil
 }
 
-func (s *Store) RestoreUser(userId int) error {
+func (s *Store) RestoreUser(orgId, userId int) error {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 
 	// TODO: ADD THE USER WHO DELETED DATA
-	_, err = tx.Exec("UPDATE users SET deletedAt = NULL WHERE id = $1", userId)
+	result, err := tx.Exec("UPDATE users SET deletedAt = NULL WHERE id = $1 AND orgId = $2", userId, orgId)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("failed to restore user with project %d: %v", userId, err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return fmt.Errorf("user with id %d not found", userId)
+	}
 
 	// TODO: ADD THE USER WHO DELETED DATA
 	_, err = tx.Exec("UPDATE users_projects SET deletedAt = NULL WHERE user_id = $1", userId)
@@ -638,6 +711,11 @@ func (s *Store) RestoreUser(userId int) error {
 		tx.Rollback()
 		return fmt.Errorf("failed to restore user with project %d: %v", userId, err)
 	}
+
+	if err = outbox.Record(tx, orgId, entities.AggregateUser, userId, "restored", map[string]int{"id": userId}); err != nil {
+		tx.Rollback()
+		return err
+	}
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("transaction commit error: %v", err)
 	}
@@ -661,6 +739,20 @@ func (s *Store) UpdateLastActiveTime(userId int, time time.Time) error {
 	return err
 }
 
+// checkReferences makes sure a user only gets the role and projects of their
+// own organization
+func checkReferences(tx *sql.Tx, orgId int, roleId *int, projectIDs *[]int) error {
+	if roleId != nil {
+		if err := organizations.CheckOwned(tx, orgId, entities.TenantRoles, []int{*roleId}); err != nil {
+			return err
+		}
+	}
+	if projectIDs != nil {
+		return organizations.CheckOwned(tx, orgId, entities.TenantProjects, *projectIDs)
+	}
+	return nil
+}
+
 func scanRowIntoUser(rows *sql.Rows, user *entities.User) error {
 	return rows.Scan(
 		&user.ID,
@@ -0,0 +1,15 @@
+package views
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/views", h.handleGetViews, "GET")
+	utils.SecureRoute(router, "/views", h.handleCreateView, "POST")
+	utils.SecureRoute(router, "/views/{viewId}", h.handleGetView, "GET")
+	utils.SecureRoute(router, "/views/{viewId}", h.handleUpdateView, "PUT")
+	utils.SecureRoute(router, "/views/{viewId}", h.handleDeleteView, "DELETE")
+	utils.SecureRoute(router, "/views/{viewId}/results", h.handleRunView, "GET")
+}
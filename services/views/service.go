@@ -0,0 +1,236 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store        entities.ViewStore
+	tasks        entities.TaskStore
+	projectTasks entities.TasksProjectStore
+	projects     entities.ProjectStore
+	access       entities.ProjectAccess
+}
+
+func NewHandler(store entities.ViewStore, tasks entities.TaskStore, projectTasks entities.TasksProjectStore, projects entities.ProjectStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, tasks: tasks, projectTasks: projectTasks, projects: projects, access: access}
+}
+
+func (h *Handler) handleGetViews(w http.ResponseWriter, r *http.Request) {
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	views, err := h.store.GetViews(utils.GetOrgID(r), *utils.GetUserID(r), memberId)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": views})
+}
+
+func (h *Handler) handleGetView(w http.ResponseWriter, r *http.Request) {
+	view, _, ok := h.getView(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": view})
+}
+
+func (h *Handler) handleCreateView(w http.ResponseWriter, r *http.Request) {
+	userId := utils.GetUserID(r)
+	if userId == nil {
+		utils.WriteError(w, http.StatusUnauthorized, fmt.Errorf("unauthenticated"))
+		return
+	}
+
+	payload, ok := h.parsePayload(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.store.CreateView(utils.GetOrgID(r), *userId, payload)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": view})
+}
+
+func (h *Handler) handleUpdateView(w http.ResponseWriter, r *http.Request) {
+	current, _, ok := h.getView(w, r)
+	if !ok || !authorizeOwner(w, r, current) {
+		return
+	}
+
+	payload, ok := h.parsePayload(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.store.UpdateView(utils.GetOrgID(r), current.ID, current.UserID, payload)
+	if err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": view})
+}
+
+func (h *Handler) handleDeleteView(w http.ResponseWriter, r *http.Request) {
+	view, _, ok := h.getView(w, r)
+	if !ok || !authorizeOwner(w, r, view) {
+		return
+	}
+
+	if err := h.store.DeleteView(utils.GetOrgID(r), view.ID, view.UserID); err != nil {
+		writeViewError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Saved View Successfully!"})
+}
+
+// handleRunView lists what the view selects, as the list endpoint of its
+// entity type would for the current user
+func (h *Handler) handleRunView(w http.ResponseWriter, r *http.Request) {
+	view, memberId, ok := h.getView(w, r)
+	if !ok {
+		return
+	}
+
+	filters := view.Filters
+	assigneeId := filters.AssigneeID
+	if filters.AssignedToMe {
+		assigneeId = utils.GetUserID(r)
+	}
+
+	var data interface{}
+	var err error
+	switch view.EntityType {
+	case entities.ViewEntityTasks:
+		data, err = h.tasks.GetTasks(entities.TaskFilter{
+			OrgID:        utils.GetOrgID(r),
+			LabelIDs:     filters.LabelIDs,
+			CustomFields: filters.CustomFields,
+			Sort:         view.Sort,
+			MemberID:     memberId,
+			ProjectID:    view.ProjectID,
+			AssigneeID:   assigneeId,
+			PriorityIDs:  filters.PriorityIDs,
+			StatusIDs:    filters.StatusIDs,
+			Completed:    filters.Completed,
+		})
+	case entities.ViewEntityProjectTasks:
+		if !members.Authorize(w, r, h.access, *view.ProjectID, entities.ProjectRoleViewer) {
+			return
+		}
+		data, err = h.projectTasks.GetTasksProject(*view.ProjectID, entities.TasksProjectFilter{
+			LabelIDs:     filters.LabelIDs,
+			CustomFields: filters.CustomFields,
+			Sort:         view.Sort,
+			AssigneeID:   assigneeId,
+			PriorityIDs:  filters.PriorityIDs,
+			StatusIDs:    filters.StatusIDs,
+			Completed:    filters.Completed,
+		})
+	case entities.ViewEntityProjects:
+		// Like the project listing, views leave archived projects out by default
+		archived := filters.Archived
+		if archived == nil {
+			archived = new(bool)
+		}
+		data, err = h.projects.GetProjects("IS NULL", entities.ProjectFilter{
+			OrgID:     utils.GetOrgID(r),
+			LabelIDs:  filters.LabelIDs,
+			MemberID:  memberId,
+			Archived:  archived,
+			SegmentID: filters.SegmentID,
+		})
+	default:
+		err = fmt.Errorf("unknown entity type %q", view.EntityType)
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": data, "view": view})
+}
+
+// getView loads a view the current user can see, writing a 404 otherwise. It
+// also returns the member scope of the user, as members.Scope does.
+func (h *Handler) getView(w http.ResponseWriter, r *http.Request) (*entities.SavedView, *int, bool) {
+	viewId, err := strconv.Atoi(mux.Vars(r)["viewId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid saved view ID"))
+		return nil, nil, false
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return nil, nil, false
+	}
+
+	view, err := h.store.GetView(utils.GetOrgID(r), viewId, *utils.GetUserID(r), memberId)
+	if err != nil {
+		writeViewError(w, err)
+		return nil, nil, false
+	}
+	return view, memberId, true
+}
+
+// parsePayload validates a view definition; sharing it or pointing it at a
+// project takes access to that project
+func (h *Handler) parsePayload(w http.ResponseWriter, r *http.Request) (entities.ViewPayload, bool) {
+	payload := entities.ViewPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return payload, false
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return payload, false
+	}
+
+	if payload.ProjectID != nil && !members.Authorize(w, r, h.access, *payload.ProjectID, entities.ProjectRoleViewer) {
+		return payload, false
+	}
+	return payload, true
+}
+
+// authorizeOwner lets only the owner change a view, shared or not
+func authorizeOwner(w http.ResponseWriter, r *http.Request, view *entities.SavedView) bool {
+	userId := utils.GetUserID(r)
+	if userId == nil || *userId != view.UserID {
+		utils.WriteError(w, http.StatusForbidden, fmt.Errorf("only the owner can change a saved view"))
+		return false
+	}
+	return true
+}
+
+func writeViewError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrViewNotFound):
+		utils.WriteError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrInvalidView):
+		utils.WriteError(w, http.StatusBadRequest, err)
+	default:
+		utils.WriteError(w, http.StatusInternalServerError, err)
+	}
+}
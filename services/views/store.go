@@ -0,0 +1,231 @@
+package views
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+)
+
+var (
+	ErrViewNotFound = errors.New("saved view not found")
+	ErrInvalidView  = errors.New("invalid saved view")
+)
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// visibleCondition holds for the views of the user in $2 and, unless $3 is
+// NULL for admins, for the views shared with the projects of the member in $3
+var visibleCondition = `(v.userId = $2 OR (v.shared AND ($3::INT IS NULL OR ` + members.MemberCondition("v.projectId", "$3") + `)))`
+
+func (s *Store) GetViews(orgId, userId int, memberId *int) ([]entities.SavedView, error) {
+	rows, err := s.db.Query(`
+		SELECT v.id, v.userId, v.name, v.entityType, v.projectId, v.shared, v.filters, v.sort, v.createdAt, v.updatedAt
+		FROM saved_views v
+		WHERE v.orgId = $1 AND v.deletedAt IS NULL AND `+visibleCondition+`
+		ORDER BY v.name, v.id
+	`, orgId, userId, memberId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved views: %v", err)
+	}
+	defer rows.Close()
+
+	views := []entities.SavedView{}
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over saved view rows: %v", err)
+	}
+	return views, nil
+}
+
+func (s *Store) GetView(orgId, id, userId int, memberId *int) (*entities.SavedView, error) {
+	row := s.db.QueryRow(`
+		SELECT v.id, v.userId, v.name, v.entityType, v.projectId, v.shared, v.filters, v.sort, v.createdAt, v.updatedAt
+		FROM saved_views v
+		WHERE v.id = $4 AND v.orgId = $1 AND v.deletedAt IS NULL AND `+visibleCondition,
+		orgId, userId, memberId, id)
+	view, err := scanView(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: ID %d", ErrViewNotFound, id)
+	}
+	return view, err
+}
+
+func (s *Store) CreateView(orgId, userId int, payload entities.ViewPayload) (*entities.SavedView, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = checkView(tx, orgId, payload); err != nil {
+		return nil, err
+	}
+
+	filters, err := json.Marshal(payload.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filters: %v", err)
+	}
+
+	var view *entities.SavedView
+	view, err = scanView(tx.QueryRow(`
+		INSERT INTO saved_views (orgId, userId, name, entityType, projectId, shared, filters, sort)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, userId, name, entityType, projectId, shared, filters, sort, createdAt, updatedAt
+	`, orgId, userId, payload.Name, payload.EntityType, payload.ProjectID, payload.Shared, filters, payload.Sort))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// UpdateView replaces the definition of a view the user owns
+func (s *Store) UpdateView(orgId, id, userId int, payload entities.ViewPayload) (*entities.SavedView, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = checkView(tx, orgId, payload); err != nil {
+		return nil, err
+	}
+
+	filters, err := json.Marshal(payload.Filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode filters: %v", err)
+	}
+
+	var view *entities.SavedView
+	view, err = scanView(tx.QueryRow(`
+		UPDATE saved_views
+		SET name = $4, entityType = $5, projectId = $6, shared = $7, filters = $8, sort = $9, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND orgId = $2 AND userId = $3 AND deletedAt IS NULL
+		RETURNING id, userId, name, entityType, projectId, shared, filters, sort, createdAt, updatedAt
+	`, id, orgId, userId, payload.Name, payload.EntityType, payload.ProjectID, payload.Shared, filters, payload.Sort))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("%w: ID %d", ErrViewNotFound, id)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// DeleteView soft deletes a view the user owns
+func (s *Store) DeleteView(orgId, id, userId int) error {
+	result, err := s.db.Exec(`
+		UPDATE saved_views SET deletedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND orgId = $2 AND userId = $3 AND deletedAt IS NULL
+	`, id, orgId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete saved view: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ID %d", ErrViewNotFound, id)
+	}
+	return nil
+}
+
+// checkView rejects definitions that cannot run against their listing and
+// references to another organization's records
+func checkView(tx *sql.Tx, orgId int, payload entities.ViewPayload) error {
+	filters := payload.Filters
+	taskFilters := filters.CustomFields != nil || filters.AssigneeID != nil || filters.AssignedToMe ||
+		filters.PriorityIDs != nil || filters.StatusIDs != nil || filters.Completed != nil
+
+	switch {
+	case payload.Shared && payload.ProjectID == nil:
+		return fmt.Errorf("%w: shared views need a project", ErrInvalidView)
+	case payload.EntityType == entities.ViewEntityProjectTasks && payload.ProjectID == nil:
+		return fmt.Errorf("%w: project task views need a project", ErrInvalidView)
+	case filters.AssigneeID != nil && filters.AssignedToMe:
+		return fmt.Errorf("%w: assigneeId and assignedToMe exclude each other", ErrInvalidView)
+	case payload.EntityType == entities.ViewEntityProjects && (taskFilters || payload.Sort != ""):
+		return fmt.Errorf("%w: project views only filter by labels, archived and segment", ErrInvalidView)
+	case payload.EntityType != entities.ViewEntityProjects && (filters.Archived != nil || filters.SegmentID != nil):
+		return fmt.Errorf("%w: archived and segment only filter project views", ErrInvalidView)
+	}
+
+	checks := []struct {
+		table string
+		ids   []int
+	}{
+		{"labels", filters.LabelIDs},
+		{"priorities", filters.PriorityIDs},
+		{"statuses", filters.StatusIDs},
+		{"users", optionalID(filters.AssigneeID)},
+		{"segments", optionalID(filters.SegmentID)},
+		{"projects", optionalID(payload.ProjectID)},
+	}
+	for _, check := range checks {
+		if err := organizations.CheckOwned(tx, orgId, check.table, check.ids); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidView, err)
+		}
+	}
+	return nil
+}
+
+func optionalID(id *int) []int {
+	if id == nil {
+		return nil
+	}
+	return []int{*id}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanView(row rowScanner) (*entities.SavedView, error) {
+	view := entities.SavedView{}
+	var filters []byte
+	err := row.Scan(
+		&view.ID, &view.UserID, &view.Name, &view.EntityType, &view.ProjectID, &view.Shared, &filters, &view.Sort,
+		&view.CreatedAt, &view.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan saved view: %v", err)
+	}
+	if err := json.Unmarshal(filters, &view.Filters); err != nil {
+		return nil, fmt.Errorf("failed to decode filters of saved view %d: %v", view.ID, err)
+	}
+	return &view, nil
+}
@@ -0,0 +1,29 @@
+package webhooks
+
+import (
+	"log"
+	"time"
+
+	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// StartDispatcher sends due webhook deliveries every interval in the background.
+func StartDispatcher(store entities.WebhookStore, interval time.Duration) {
+	interval = utils.PositiveDuration("webhook interval", interval, config.DefaultWebhookIntervalSeconds*time.Second)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			delivered, err := store.DeliverDue(time.Now())
+			if err != nil {
+				log.Printf("Webhook dispatcher: %v", err)
+			} else if delivered > 0 {
+				log.Printf("Webhook dispatcher: delivered %d event(s)", delivered)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,16 @@
+package webhooks
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/webhooks", h.handleGetWebhooks, "GET")
+	utils.SecureRoute(router, "/webhooks", h.handleCreateWebhook, "POST")
+	utils.SecureRoute(router, "/webhooks/{webhookId}", h.handleGetWebhook, "GET")
+	utils.SecureRoute(router, "/webhooks/{webhookId}", h.handleUpdateWebhook, "PUT")
+	utils.SecureRoute(router, "/webhooks/{webhookId}", h.handleDeleteWebhook, "DELETE")
+	utils.SecureRoute(router, "/webhooks/{webhookId}/deliveries", h.handleGetDeliveries, "GET")
+	utils.SecureRoute(router, "/webhooks/deliveries/{deliveryId}/redeliver", h.handleRedeliver, "POST")
+}
@@ -0,0 +1,193 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.WebhookStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.WebhookStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	webhooks, err := h.store.GetWebhooks(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": webhooks})
+}
+
+func (h *Handler) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(mux.Vars(r)["webhookId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid webhook ID"))
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	webhook, err := h.store.GetWebhook(utils.GetOrgID(r), webhookId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": webhook})
+}
+
+func (h *Handler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := parsePayload(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	webhook, err := h.store.CreateWebhook(utils.GetOrgID(r), payload)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": webhook})
+}
+
+func (h *Handler) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(mux.Vars(r)["webhookId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid webhook ID"))
+		return
+	}
+
+	payload, err := parsePayload(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	payload.ID = webhookId
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	if err := h.store.UpdateWebhook(utils.GetOrgID(r), payload); err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Webhook Updated Successfully!"})
+}
+
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(mux.Vars(r)["webhookId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid webhook ID"))
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	if err := h.store.DeleteWebhook(utils.GetOrgID(r), webhookId); err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Webhook Deleted Successfully!"})
+}
+
+func (h *Handler) handleGetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookId, err := strconv.Atoi(mux.Vars(r)["webhookId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid webhook ID"))
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entities.DeliveryPending, entities.DeliveryDelivered, entities.DeliveryDead:
+	default:
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid delivery status %s", status))
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	deliveries, err := h.store.GetDeliveries(utils.GetOrgID(r), webhookId, status)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": deliveries})
+}
+
+func (h *Handler) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	deliveryId, err := strconv.Atoi(mux.Vars(r)["deliveryId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid delivery ID"))
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	delivery, err := h.store.Redeliver(utils.GetOrgID(r), deliveryId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	utils.WriteJSON(w, http.StatusAccepted, map[string]interface{}{"data": delivery})
+}
+
+func parsePayload(r *http.Request) (entities.WebhookPayload, error) {
+	payload := entities.WebhookPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		return payload, err
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		return payload, fmt.Errorf("invalid payload %v", errs)
+	}
+
+	for _, event := range payload.Events {
+		if !isKnownEvent(event) {
+			return payload, fmt.Errorf("unknown webhook event %s", event)
+		}
+	}
+	return payload, nil
+}
+
+func isKnownEvent(event string) bool {
+	if event == entities.WebhookEventAll {
+		return true
+	}
+	for _, known := range entities.WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,375 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/config"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// deliveryBatchSize is how many due deliveries one dispatcher run sends
+const deliveryBatchSize = 50
+
+// Retries back off exponentially from retryBaseDelay up to retryMaxDelay
+const (
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = 6 * time.Hour
+)
+
+type Store struct {
+	db          *sql.DB
+	client      *http.Client
+	maxAttempts int
+}
+
+func NewStore(db *sql.DB) *Store {
+	// The timeout also sets the lease of deliveries in flight, so it cannot be 0
+	timeout := utils.PositiveDuration("webhook timeout", time.Duration(config.Envs.WebhookTimeoutSeconds)*time.Second, config.DefaultWebhookTimeoutSeconds*time.Second)
+	return &Store{
+		db:          db,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: int(config.Envs.WebhookMaxAttempts),
+	}
+}
+
+const webhookColumns = "id, url, events, active, createdAt, updatedAt"
+
+func scanWebhook(scanner interface{ Scan(...interface{}) error }, webhook *entities.Webhook) error {
+	return scanner.Scan(&webhook.ID, &webhook.URL, pq.Array(&webhook.Events), &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt)
+}
+
+func (s *Store) GetWebhooks(orgId int) ([]entities.Webhook, error) {
+	rows, err := s.db.Query("SELECT "+webhookColumns+" FROM webhooks WHERE orgId = $1 AND deletedAt IS NULL ORDER BY id", orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %v", err)
+	}
+	defer rows.Close()
+
+	webhooks := []entities.Webhook{}
+	for rows.Next() {
+		webhook := entities.Webhook{}
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %v", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook rows: %v", err)
+	}
+	return webhooks, nil
+}
+
+func (s *Store) GetWebhook(orgId, id int) (*entities.Webhook, error) {
+	webhook := entities.Webhook{}
+	row := s.db.QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL", id, orgId)
+	if err := scanWebhook(row, &webhook); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve webhook: %v", err)
+	}
+	return &webhook, nil
+}
+
+func (s *Store) CreateWebhook(orgId int, payload entities.WebhookPayload) (*entities.Webhook, error) {
+	secret := payload.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %v", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	active := true
+	if payload.Active != nil {
+		active = *payload.Active
+	}
+
+	webhook := entities.Webhook{}
+	row := s.db.QueryRow(`
+		INSERT INTO webhooks (url, secret, events, active, orgId) VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+webhookColumns,
+		payload.URL, secret, pq.Array(payload.Events), active, orgId,
+	)
+	if err := scanWebhook(row, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %v", err)
+	}
+	webhook.Secret = secret
+	return &webhook, nil
+}
+
+// UpdateWebhook keeps the current secret when the payload has none
+func (s *Store) UpdateWebhook(orgId int, payload entities.WebhookPayload) error {
+	result, err := s.db.Exec(`
+		UPDATE webhooks
+		SET url = $1, secret = COALESCE(NULLIF($2, ''), secret), events = $3, active = COALESCE($4, active), updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $5 AND orgId = $6 AND deletedAt IS NULL
+	`, payload.URL, payload.Secret, pq.Array(payload.Events), payload.Active, payload.ID, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("webhook with ID %d not found", payload.ID)
+	}
+	return nil
+}
+
+// DeleteWebhook soft deletes the webhook and gives up on its pending deliveries
+func (s *Store) DeleteWebhook(orgId, id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec("UPDATE webhooks SET deletedAt = CURRENT_TIMESTAMP WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL", id, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		err = fmt.Errorf("webhook with ID %d not found", id)
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE webhook_deliveries SET status = $1, nextAttemptAt = NULL, lastError = 'webhook deleted', updatedAt = CURRENT_TIMESTAMP
+		WHERE webhookId = $2 AND status = $3
+	`, entities.DeliveryDead, id, entities.DeliveryPending)
+	if err != nil {
+		return fmt.Errorf("failed to cancel deliveries: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+const deliveryColumns = `
+	id, webhookId, event, payload, status, attempts, nextAttemptAt, lastStatusCode, lastError, deliveredAt, createdAt, updatedAt
+`
+
+func scanDelivery(scanner interface{ Scan(...interface{}) error }, delivery *entities.WebhookDelivery) error {
+	return scanner.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.Event, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.DeliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+}
+
+// GetDeliveries lists the latest deliveries of a webhook, optionally by status
+func (s *Store) GetDeliveries(orgId, webhookId int, status string) ([]entities.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(orgId, webhookId); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE webhookId = $1 AND ($2 = '' OR status = $2)
+		ORDER BY createdAt DESC, id DESC
+		LIMIT 100
+	`, webhookId, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	deliveries := []entities.WebhookDelivery{}
+	for rows.Next() {
+		delivery := entities.WebhookDelivery{}
+		if err := scanDelivery(rows, &delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over delivery rows: %v", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a delivery again with a fresh retry budget, whatever its
+// current status.
+func (s *Store) Redeliver(orgId, deliveryId int) (*entities.WebhookDelivery, error) {
+	delivery := entities.WebhookDelivery{}
+	row := s.db.QueryRow(`
+		UPDATE webhook_deliveries d
+		SET status = $1, attempts = 0, nextAttemptAt = CURRENT_TIMESTAMP, updatedAt = CURRENT_TIMESTAMP
+		FROM webhooks w
+		WHERE d.id = $2 AND w.id = d.webhookId AND w.orgId = $3 AND w.deletedAt IS NULL
+		RETURNING d.id, d.webhookId, d.event, d.payload, d.status, d.attempts, d.nextAttemptAt, d.lastStatusCode, d.lastError, d.deliveredAt, d.createdAt, d.updatedAt
+	`, entities.DeliveryPending, deliveryId, orgId)
+	if err := scanDelivery(row, &delivery); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("delivery with ID %d not found", deliveryId)
+		}
+		return nil, fmt.Errorf("failed to redeliver: %v", err)
+	}
+	return &delivery, nil
+}
+
+type dueDelivery struct {
+	id       int
+	event    string
+	payload  []byte
+	attempts int
+	url      string
+	secret   string
+}
+
+// DeliverDue sends the pending deliveries that are due and records the
+// outcome. Claimed rows are leased past the request timeout so concurrent
+// dispatchers skip them while they are in flight.
+func (s *Store) DeliverDue(now time.Time) (int, error) {
+	lease := now.Add(2 * s.client.Timeout)
+	rows, err := s.db.Query(`
+		UPDATE webhook_deliveries d SET nextAttemptAt = $2
+		FROM webhooks w
+		WHERE w.id = d.webhookId AND d.id IN (
+			SELECT dd.id FROM webhook_deliveries dd
+			JOIN webhooks ww ON ww.id = dd.webhookId AND ww.active AND ww.deletedAt IS NULL
+			WHERE dd.status = $3 AND dd.nextAttemptAt <= $1
+			ORDER BY dd.nextAttemptAt, dd.id
+			LIMIT $4
+			FOR UPDATE OF dd SKIP LOCKED
+		)
+		RETURNING d.id, d.event, d.payload, d.attempts, w.url, w.secret
+	`, now, lease, entities.DeliveryPending, deliveryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim deliveries: %v", err)
+	}
+
+	due := []dueDelivery{}
+	for rows.Next() {
+		d := dueDelivery{}
+		if err := rows.Scan(&d.id, &d.event, &d.payload, &d.attempts, &d.url, &d.secret); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan delivery: %v", err)
+		}
+		due = append(due, d)
+	}
+	rows.Close()
+
+	delivered := 0
+	for _, d := range due {
+		statusCode, sendErr := s.send(d)
+		if err := s.recordAttempt(d, statusCode, sendErr); err != nil {
+			return delivered, err
+		}
+		if sendErr == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (s *Store) send(d dueDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(d.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.event)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(d.id))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(d.secret, timestamp, d.payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver responded with %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func (s *Store) recordAttempt(d dueDelivery, statusCode int, sendErr error) error {
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+	attempts := d.attempts + 1
+
+	var err error
+	if sendErr == nil {
+		_, err = s.db.Exec(`
+			UPDATE webhook_deliveries
+			SET status = $1, attempts = $2, lastStatusCode = $3, lastError = NULL, nextAttemptAt = NULL,
+				deliveredAt = CURRENT_TIMESTAMP, updatedAt = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, entities.DeliveryDelivered, attempts, code, d.id)
+	} else if attempts >= s.maxAttempts {
+		_, err = s.db.Exec(`
+			UPDATE webhook_deliveries
+			SET status = $1, attempts = $2, lastStatusCode = $3, lastError = $4, nextAttemptAt = NULL, updatedAt = CURRENT_TIMESTAMP
+			WHERE id = $5
+		`, entities.DeliveryDead, attempts, code, sendErr.Error(), d.id)
+	} else {
+		_, err = s.db.Exec(`
+			UPDATE webhook_deliveries
+			SET attempts = $1, lastStatusCode = $2, lastError = $3, nextAttemptAt = $4, updatedAt = CURRENT_TIMESTAMP
+			WHERE id = $5
+		`, attempts, code, sendErr.Error(), time.Now().Add(retryDelay(attempts)), d.id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record delivery %d: %v", d.id, err)
+	}
+	return nil
+}
+
+// retryDelay doubles the wait after every failed attempt
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// Enqueue queues an event for every active webhook of the organization that
+// owns the change and is subscribed to it. It runs inside the caller's
+// transaction, so deliveries exist exactly when the change commits.
+func Enqueue(tx *sql.Tx, orgId int, event string, data interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":      event,
+		"occurredAt": time.Now().UTC(),
+		"data":       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %v", event, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO webhook_deliveries (webhookId, event, payload)
+		SELECT id, $1, $2 FROM webhooks
+		WHERE orgId = $4 AND active AND deletedAt IS NULL AND ($1 = ANY(events) OR $3 = ANY(events))
+	`, event, string(payload), entities.WebhookEventAll, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s: %v", event, err)
+	}
+	return nil
+}
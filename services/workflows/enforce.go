@@ -0,0 +1,177 @@
+package workflows
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+var (
+	ErrTransitionNotAllowed = errors.New("status transition is not allowed")
+	ErrRequiredFields       = errors.New("status transition requires")
+)
+
+// IsViolation reports whether err is a workflow rule being broken, which
+// handlers answer with a 422
+func IsViolation(err error) bool {
+	return errors.Is(err, ErrTransitionNotAllowed) || errors.Is(err, ErrRequiredFields)
+}
+
+type workflowTarget struct {
+	table string
+	// fields maps the names a transition can require to an expression that is
+	// NULL while the field is not filled in
+	fields map[string]string
+}
+
+var taskFields = map[string]string{
+	"description":   "NULLIF(description, '')",
+	"userId":        "userId",
+	"storyPoints":   "storyPoints",
+	"estimateHours": "estimateHours",
+	"dueDate":       "dueDate",
+}
+
+var workflowTargets = map[string]workflowTarget{
+	entities.WorkflowTargetProject: {
+		table: "projects",
+		fields: map[string]string{
+			"description":  "NULLIF(description, '')",
+			"url":          "NULLIF(url, '')",
+			"progress":     "progress",
+			"dateStarted":  "dateStarted",
+			"dateDeadline": "dateDeadline",
+		},
+	},
+	entities.WorkflowTargetTask:        {table: "tasks", fields: taskFields},
+	entities.WorkflowTargetProjectTask: {table: "project_tasks", fields: taskFields},
+}
+
+// Enforce checks the move of record id from one status to another against
+// the workflow of the organization. It runs after the record was updated in
+// the same transaction, so required fields are checked on the new values.
+// Records without a status yet may take any status.
+func Enforce(tx *sql.Tx, orgId int, target string, id int, from, to *int) error {
+	if from == nil || to == nil || *from == *to {
+		return nil
+	}
+	t, ok := workflowTargets[target]
+	if !ok {
+		return fmt.Errorf("invalid workflow target %s", target)
+	}
+
+	var workflowId int
+	err := tx.QueryRow(`
+		SELECT id FROM workflows WHERE orgId = $1 AND target = $2 AND deletedAt IS NULL
+	`, orgId, target).Scan(&workflowId)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve workflow: %v", err)
+	}
+
+	// A transition from this exact status wins over one from any status
+	var required []string
+	err = tx.QueryRow(`
+		SELECT requiredFields FROM workflow_transitions
+		WHERE workflowId = $1 AND (fromStatusId = $2 OR fromStatusId IS NULL) AND toStatusId = $3
+		ORDER BY fromStatusId NULLS LAST
+		LIMIT 1
+	`, workflowId, *from, *to).Scan(pq.Array(&required))
+	if err == sql.ErrNoRows {
+		return notAllowed(tx, workflowId, *from, *to)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check status transition: %v", err)
+	}
+
+	missing := []string{}
+	for _, field := range required {
+		expr, ok := t.fields[field]
+		if !ok {
+			continue
+		}
+		var empty bool
+		if err := tx.QueryRow(`SELECT `+expr+` IS NULL FROM `+t.table+` WHERE id = $1`, id).Scan(&empty); err != nil {
+			return fmt.Errorf("failed to check %s: %v", field, err)
+		}
+		if empty {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w %s to move to %q", ErrRequiredFields, strings.Join(missing, ", "), statusName(tx, *to))
+	}
+	return nil
+}
+
+// Completion returns whether an update leaves its record completed: completed
+// when the caller sent it, otherwise whether a new status is done, or nil to
+// keep the current completion when the status does not change.
+func Completion(tx *sql.Tx, from, to *int, completed *bool) (*bool, error) {
+	if completed != nil || !utils.IntChanged(from, to) {
+		return completed, nil
+	}
+	category, err := StatusCategory(tx, *to)
+	if err != nil {
+		return nil, err
+	}
+	done := category == entities.StatusCategoryDone
+	return &done, nil
+}
+
+// StatusCategory returns the category of a status
+func StatusCategory(tx *sql.Tx, statusId int) (string, error) {
+	var category string
+	err := tx.QueryRow(`SELECT category FROM statuses WHERE id = $1`, statusId).Scan(&category)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("status with ID %d not found", statusId)
+		}
+		return "", fmt.Errorf("failed to retrieve status: %v", err)
+	}
+	return category, nil
+}
+
+// notAllowed explains a rejected move with the statuses that can follow
+func notAllowed(tx *sql.Tx, workflowId, from, to int) error {
+	rows, err := tx.Query(`
+		SELECT DISTINCT s.name FROM workflow_transitions wt
+		JOIN statuses s ON s.id = wt.toStatusId AND s.deletedAt IS NULL
+		WHERE wt.workflowId = $1 AND (wt.fromStatusId = $2 OR wt.fromStatusId IS NULL) AND wt.toStatusId <> $2
+		ORDER BY s.name
+	`, workflowId, from)
+	if err != nil {
+		return fmt.Errorf("failed to query status transitions: %v", err)
+	}
+	defer rows.Close()
+
+	allowed := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to scan status transition: %v", err)
+		}
+		allowed = append(allowed, name)
+	}
+
+	next := "none"
+	if len(allowed) > 0 {
+		next = strings.Join(allowed, ", ")
+	}
+	return fmt.Errorf("%w from %q to %q, allowed next statuses: %s", ErrTransitionNotAllowed, statusName(tx, from), statusName(tx, to), next)
+}
+
+func statusName(tx *sql.Tx, id int) string {
+	var name string
+	if err := tx.QueryRow(`SELECT name FROM statuses WHERE id = $1`, id).Scan(&name); err != nil {
+		return fmt.Sprintf("#%d", id)
+	}
+	return name
+}
@@ -0,0 +1,14 @@
+package workflows
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/workflows", h.handleGetWorkflows, "GET")
+	utils.SecureRoute(router, "/workflows", h.handleCreateWorkflow, "POST")
+	utils.SecureRoute(router, "/workflows/{workflowId}", h.handleGetWorkflow, "GET")
+	utils.SecureRoute(router, "/workflows/{workflowId}", h.handleUpdateWorkflow, "PUT")
+	utils.SecureRoute(router, "/workflows/{workflowId}", h.handleDeleteWorkflow, "DELETE")
+}
@@ -0,0 +1,161 @@
+package workflows
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+type Handler struct {
+	store  entities.WorkflowStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.WorkflowStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+func (h *Handler) handleGetWorkflows(w http.ResponseWriter, r *http.Request) {
+	workflows, err := h.store.GetWorkflows(utils.GetOrgID(r))
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": workflows})
+}
+
+func (h *Handler) handleGetWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflowId, err := getWorkflowID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	workflow, err := h.store.GetWorkflow(utils.GetOrgID(r), workflowId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"data": workflow})
+}
+
+func (h *Handler) handleCreateWorkflow(w http.ResponseWriter, r *http.Request) {
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	payload := entities.WorkflowPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	workflow, err := h.store.CreateWorkflow(utils.GetOrgID(r), payload)
+	if errors.Is(err, ErrWorkflowExists) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{"data": workflow})
+}
+
+func (h *Handler) handleUpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflowId, err := getWorkflowID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	payload := entities.WorkflowPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	workflow, err := h.store.GetWorkflow(utils.GetOrgID(r), workflowId)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	if payload.Name == "" {
+		payload.Name = workflow.Name
+	}
+	if payload.Target == "" {
+		payload.Target = workflow.Target
+	}
+	payload.ID = workflow.ID
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	err = h.store.UpdateWorkflow(utils.GetOrgID(r), payload)
+	if errors.Is(err, ErrWorkflowExists) {
+		utils.WriteError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Workflow Successfully!"})
+}
+
+func (h *Handler) handleDeleteWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflowId, err := getWorkflowID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	if err := h.store.DeleteWorkflow(utils.GetOrgID(r), workflowId); err != nil {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Delete Workflow Successfully!"})
+}
+
+func getWorkflowID(r *http.Request) (int, error) {
+	str, ok := mux.Vars(r)["workflowId"]
+	if !ok {
+		return 0, fmt.Errorf("missing workflow ID")
+	}
+
+	workflowId, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid workflow ID")
+	}
+	return workflowId, nil
+}
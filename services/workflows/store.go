@@ -0,0 +1,233 @@
+package workflows
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/organizations"
+)
+
+var ErrWorkflowExists = errors.New("the organization already has a workflow for this target")
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) GetWorkflows(orgId int) ([]entities.Workflow, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, target, createdAt, updatedAt
+		FROM workflows
+		WHERE orgId = $1 AND deletedAt IS NULL
+		ORDER BY target
+	`, orgId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflows: %v", err)
+	}
+	defer rows.Close()
+
+	workflows := []entities.Workflow{}
+	for rows.Next() {
+		workflow := entities.Workflow{}
+		if err := rows.Scan(&workflow.ID, &workflow.Name, &workflow.Target, &workflow.CreatedAt, &workflow.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan workflow: %v", err)
+		}
+		workflows = append(workflows, workflow)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over workflow rows: %v", err)
+	}
+
+	for i := range workflows {
+		if workflows[i].Transitions, err = s.getTransitions(workflows[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return workflows, nil
+}
+
+func (s *Store) GetWorkflow(orgId, id int) (*entities.Workflow, error) {
+	workflow := entities.Workflow{}
+	err := s.db.QueryRow(`
+		SELECT id, name, target, createdAt, updatedAt
+		FROM workflows
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, id, orgId).Scan(&workflow.ID, &workflow.Name, &workflow.Target, &workflow.CreatedAt, &workflow.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("workflow with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to retrieve workflow: %v", err)
+	}
+
+	if workflow.Transitions, err = s.getTransitions(id); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+func (s *Store) CreateWorkflow(orgId int, payload entities.WorkflowPayload) (*entities.Workflow, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = checkTransitions(tx, orgId, payload); err != nil {
+		return nil, err
+	}
+
+	workflow := entities.Workflow{}
+	err = tx.QueryRow(`
+		INSERT INTO workflows (orgId, name, target) VALUES ($1, $2, $3)
+		RETURNING id, name, target, createdAt, updatedAt
+	`, orgId, payload.Name, payload.Target).Scan(&workflow.ID, &workflow.Name, &workflow.Target, &workflow.CreatedAt, &workflow.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			err = ErrWorkflowExists
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to insert workflow: %v", err)
+	}
+
+	if workflow.Transitions, err = saveTransitions(tx, workflow.ID, payload.Transitions); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// UpdateWorkflow renames the workflow and replaces its transitions
+func (s *Store) UpdateWorkflow(orgId int, payload entities.WorkflowPayload) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = checkTransitions(tx, orgId, payload); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE workflows SET name = $1, target = $2, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $3 AND orgId = $4 AND deletedAt IS NULL
+	`, payload.Name, payload.Target, payload.ID, orgId)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			err = ErrWorkflowExists
+			return err
+		}
+		return fmt.Errorf("failed to update workflow: %v", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM workflow_transitions WHERE workflowId = $1`, payload.ID); err != nil {
+		return fmt.Errorf("failed to replace transitions: %v", err)
+	}
+	if _, err = saveTransitions(tx, payload.ID, payload.Transitions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) DeleteWorkflow(orgId, id int) error {
+	result, err := s.db.Exec(`
+		UPDATE workflows SET deletedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, id, orgId)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("workflow with ID %d not found", id)
+	}
+	return nil
+}
+
+func (s *Store) getTransitions(workflowId int) ([]entities.WorkflowTransition, error) {
+	rows, err := s.db.Query(`
+		SELECT id, fromStatusId, toStatusId, requiredFields
+		FROM workflow_transitions
+		WHERE workflowId = $1
+		ORDER BY fromStatusId NULLS FIRST, toStatusId
+	`, workflowId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transitions: %v", err)
+	}
+	defer rows.Close()
+
+	transitions := []entities.WorkflowTransition{}
+	for rows.Next() {
+		transition := entities.WorkflowTransition{}
+		err := rows.Scan(&transition.ID, &transition.FromStatusID, &transition.ToStatusID, pq.Array(&transition.RequiredFields))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transition: %v", err)
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, rows.Err()
+}
+
+func saveTransitions(tx *sql.Tx, workflowId int, payloads []entities.WorkflowTransitionPayload) ([]entities.WorkflowTransition, error) {
+	transitions := []entities.WorkflowTransition{}
+	for _, payload := range payloads {
+		required := payload.RequiredFields
+		if required == nil {
+			required = []string{}
+		}
+
+		transition := entities.WorkflowTransition{FromStatusID: payload.FromStatusID, ToStatusID: payload.ToStatusID, RequiredFields: required}
+		err := tx.QueryRow(`
+			INSERT INTO workflow_transitions (workflowId, fromStatusId, toStatusId, requiredFields)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, workflowId, payload.FromStatusID, payload.ToStatusID, pq.Array(required)).Scan(&transition.ID)
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+				return nil, fmt.Errorf("duplicate transition to status %d", payload.ToStatusID)
+			}
+			return nil, fmt.Errorf("failed to insert transition: %v", err)
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, nil
+}
+
+// checkTransitions makes sure transitions only use statuses of the
+// organization and fields the target knows about
+func checkTransitions(tx *sql.Tx, orgId int, payload entities.WorkflowPayload) error {
+	t := workflowTargets[payload.Target]
+	statusIds := []int{}
+	for _, transition := range payload.Transitions {
+		if transition.FromStatusID != nil {
+			statusIds = append(statusIds, *transition.FromStatusID)
+		}
+		statusIds = append(statusIds, transition.ToStatusID)
+
+		for _, field := range transition.RequiredFields {
+			if _, ok := t.fields[field]; !ok {
+				return fmt.Errorf("unknown required field %s for %s", field, payload.Target)
+			}
+		}
+	}
+	return organizations.CheckOwned(tx, orgId, entities.TenantStatuses, statusIds)
+}
@@ -0,0 +1,11 @@
+package workload
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+func RegisterRoutes(router *mux.Router, h *Handler) {
+	utils.SecureRoute(router, "/reports/workload", h.handleGetWorkload, "GET")
+	utils.SecureRoute(router, "/users/{userId}/capacity", h.handleSetCapacity, "PUT")
+}
@@ -0,0 +1,125 @@
+package workload
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/utils"
+)
+
+// maxRangeDays bounds the date range of a report
+const maxRangeDays = 366
+
+type Handler struct {
+	store  entities.WorkloadStore
+	access entities.ProjectAccess
+}
+
+func NewHandler(store entities.WorkloadStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
+}
+
+// handleGetWorkload reports on the range from ?from= through ?to=, the
+// coming week by default
+func (h *Handler) handleGetWorkload(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	from, err := utils.ParseOptionalDate(r.URL.Query().Get("from"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if from == nil {
+		from = &today
+	}
+	to, err := utils.ParseOptionalDate(r.URL.Query().Get("to"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if to == nil {
+		end := from.AddDate(0, 0, 6)
+		to = &end
+	}
+	if to.Before(*from) {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the range must not end before it starts"))
+		return
+	}
+	if to.After(from.AddDate(0, 0, maxRangeDays-1)) {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("the range must not span more than %d days", maxRangeDays))
+		return
+	}
+
+	userIDs, err := utils.ParseIntList(r.URL.Query().Get("userIds"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	report, err := h.store.GetWorkload(entities.WorkloadFilter{
+		OrgID:    utils.GetOrgID(r),
+		MemberID: memberId,
+		UserIDs:  userIDs,
+		From:     *from,
+		To:       *to,
+		Today:    today,
+	})
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"data": report,
+		"from": utils.FormatOptionalDate(from),
+		"to":   utils.FormatOptionalDate(to),
+	})
+}
+
+func (h *Handler) handleSetCapacity(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(mux.Vars(r)["userId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+	if !members.AuthorizeAdmin(w, r, h.access) {
+		return
+	}
+
+	payload := entities.CapacityPayload{}
+	if err := utils.ParseJSON(r, &payload); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := utils.Validate.Struct(payload); err != nil {
+		errs := err.(validator.ValidationErrors)
+		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid payload %v", errs))
+		return
+	}
+
+	err = h.store.SetCapacity(utils.GetOrgID(r), userId, payload)
+	if errors.Is(err, ErrUserNotFound) {
+		utils.WriteError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"msg": "Update Capacity Successfully!"})
+}
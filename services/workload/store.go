@@ -0,0 +1,123 @@
+package workload
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/lib/pq"
+	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// GetWorkload reports on every selected user, including those with nothing
+// assigned. Tasks count in the projects the member in $4 can see.
+func (s *Store) GetWorkload(filter entities.WorkloadFilter) ([]entities.UserWorkload, error) {
+	inRange := "a.dueDate >= $2 AND a.dueDate < $3"
+	rows, err := s.db.Query(`
+		WITH assigned AS (
+			SELECT t.userId, w.projectId, t.estimateHours, t.storyPoints, t.dueDate
+			FROM tasks t
+			JOIN workspaces w ON w.id = t.workspaceId AND w.deletedAt IS NULL
+			WHERE t.deletedAt IS NULL AND t.completedAt IS NULL
+			UNION ALL
+			SELECT pt.userId, pt.projectId, pt.estimateHours, pt.storyPoints, pt.dueDate
+			FROM project_tasks pt
+			WHERE pt.deletedAt IS NULL AND pt.completedAt IS NULL
+		), visible AS (
+			SELECT a.*
+			FROM assigned a
+			JOIN projects p ON p.id = a.projectId AND p.deletedAt IS NULL AND p.archivedAt IS NULL
+			WHERE p.orgId = $1 AND ($4::INT IS NULL OR `+members.MemberCondition("p.id", "$4")+`)
+		)
+		SELECT
+			u.id, u.firstName, u.lastName, u.email, u.weeklyCapacityHours,
+			COUNT(a.userId) FILTER (WHERE `+inRange+`),
+			COALESCE(SUM(a.estimateHours) FILTER (WHERE `+inRange+`), 0),
+			COALESCE(SUM(a.storyPoints) FILTER (WHERE `+inRange+`), 0),
+			COUNT(a.userId) FILTER (WHERE a.dueDate < $5),
+			COUNT(a.userId) FILTER (WHERE a.dueDate IS NULL),
+			COUNT(DISTINCT a.projectId) FILTER (WHERE `+inRange+`),
+			(
+				SELECT COUNT(*) FROM users_projects up
+				JOIN projects p ON p.id = up.project_id AND p.deletedAt IS NULL AND p.archivedAt IS NULL
+				WHERE up.user_id = u.id AND up.deletedAt IS NULL
+					AND ($4::INT IS NULL OR `+members.MemberCondition("p.id", "$4")+`)
+			)
+		FROM users u
+		LEFT JOIN visible a ON a.userId = u.id
+		WHERE u.orgId = $1 AND u.deletedAt IS NULL
+			AND (COALESCE(cardinality($6::INT[]), 0) = 0 OR u.id = ANY($6))
+			AND ($4::INT IS NULL OR u.id = $4 OR EXISTS (
+				SELECT 1 FROM users_projects up
+				WHERE up.user_id = u.id AND up.deletedAt IS NULL AND `+members.MemberCondition("up.project_id", "$4")+`
+			))
+		GROUP BY u.id
+		ORDER BY u.firstName, u.lastName, u.id
+	`, filter.OrgID, filter.From, filter.To.AddDate(0, 0, 1), filter.MemberID, filter.Today, pq.Array(filter.UserIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workload: %v", err)
+	}
+	defer rows.Close()
+
+	// Capacity is prorated by the days in the range, To included
+	weeks := (filter.To.Sub(filter.From).Hours()/24 + 1) / 7
+	report := []entities.UserWorkload{}
+	for rows.Next() {
+		workload := entities.UserWorkload{}
+		err := rows.Scan(
+			&workload.UserID, &workload.FirstName, &workload.LastName, &workload.Email, &workload.WeeklyCapacityHours,
+			&workload.OpenTasks, &workload.EstimateHours, &workload.StoryPoints, &workload.OverdueTasks,
+			&workload.UnscheduledTasks, &workload.ActiveProjects, &workload.Projects,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workload: %v", err)
+		}
+		utilize(&workload, weeks)
+		report = append(report, workload)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over workload rows: %v", err)
+	}
+	return report, nil
+}
+
+func (s *Store) SetCapacity(orgId, userId int, payload entities.CapacityPayload) error {
+	result, err := s.db.Exec(`
+		UPDATE users SET weeklyCapacityHours = $3, updatedAt = CURRENT_TIMESTAMP
+		WHERE id = $1 AND orgId = $2 AND deletedAt IS NULL
+	`, userId, orgId, payload.WeeklyCapacityHours)
+	if err != nil {
+		return fmt.Errorf("failed to update capacity: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: ID %d", ErrUserNotFound, userId)
+	}
+	return nil
+}
+
+// utilize fills in the capacity for the range and the share of it the
+// estimates take, rounded to two decimals
+func utilize(workload *entities.UserWorkload, weeks float64) {
+	if workload.WeeklyCapacityHours == nil {
+		return
+	}
+	capacity := math.Round(*workload.WeeklyCapacityHours*weeks*100) / 100
+	workload.CapacityHours = &capacity
+	if capacity > 0 {
+		utilization := math.Round(workload.EstimateHours/capacity*10000) / 100
+		workload.Utilization = &utilization
+	}
+}
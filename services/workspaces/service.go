@@ -9,15 +9,18 @@ import (
 	_ "github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/members"
 	"github.com/norrico31/it210-core-service-backend/utils"
 )
 
 type Handler struct {
-	store entities.WorkspaceStore
+	store  entities.WorkspaceStore
+	access entities.ProjectAccess
 }
 
-func NewHandler(store entities.WorkspaceStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store entities.WorkspaceStore, access entities.ProjectAccess) *Handler {
+	return &Handler{store: store, access: access}
 }
 
 func (h *Handler) handleGetWorkspaces(w http.ResponseWriter, r *http.Request) {
@@ -28,7 +31,12 @@ func (h *Handler) handleGetWorkspaces(w http.ResponseWriter, r *http.Request) {
 	// 	return
 	// }
 	// projectId, err := strconv.Atoi(str)
-	workspaces, err := h.store.GetWorkspaces()
+	memberId, ok := members.Scope(w, r, h.access)
+	if !ok {
+		return
+	}
+
+	workspaces, err := h.store.GetWorkspaces(utils.GetOrgID(r), memberId)
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -52,7 +60,22 @@ func (h *Handler) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workspace, err := h.store.GetWorkspace(projectId)
+	if !members.Authorize(w, r, h.access, projectId, entities.ProjectRoleViewer) {
+		return
+	}
+
+	labelIDs, err := utils.ParseIntList(r.URL.Query().Get("labelIds"))
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	customFields, err := customfields.ParseFilter(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	workspace, err := h.store.GetWorkspace(projectId, entities.TaskFilter{LabelIDs: labelIDs, CustomFields: customFields})
 	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
@@ -74,6 +97,10 @@ func (h *Handler) handleCreateWorkspace(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !members.AuthorizeWrite(w, r, h.access, payload.ProjectID, entities.ProjectRoleMaintainer) {
+		return
+	}
+
 	workspace, err := h.store.CreateWorkspace(payload)
 
 	if err != nil {
@@ -97,6 +124,10 @@ func (h *Handler) handleUpdateWorkspace(w http.ResponseWriter, r *http.Request)
 		utils.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid workspace ID"))
 		return
 	}
+	if !members.AuthorizeWriteTarget(w, r, h.access, entities.AccessTargetWorkspace, workspaceId, entities.ProjectRoleMaintainer) {
+		return
+	}
+
 	var payload entities.WorkspacePayload
 
 	if err := utils.ParseJSON(r, &payload); err != nil {
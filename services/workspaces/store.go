@@ -3,11 +3,16 @@ package workspaces
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"sort"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/norrico31/it210-core-service-backend/entities"
+	"github.com/norrico31/it210-core-service-backend/services/customfields"
+	"github.com/norrico31/it210-core-service-backend/services/labels"
+	"github.com/norrico31/it210-core-service-backend/services/members"
+	"github.com/norrico31/it210-core-service-backend/services/realtime"
 )
 
 type Store struct {
@@ -19,16 +24,21 @@ func NewStore(db *sql.DB) *Store {
 }
 
 // TODO ADD THE FUNCTIONALITY OF DRAG N DROP HERE FOR COLUMN IN WORKSPACE
-func (s *Store) GetWorkspaces() ([]entities.Workspace, error) {
+// GetWorkspaces lists the columns of every active project of the organization,
+// or only of the projects of memberId when it is set
+func (s *Store) GetWorkspaces(orgId int, memberId *int) ([]entities.Workspace, error) {
 	queryWorkspaces := `
         SELECT 
             w.id, w.name, w.description, w.projectId, w.colOrder,
             w.createdAt, w.updatedAt, w.deletedAt
         FROM workspaces w
+        WHERE ` + members.OrgCondition("w.projectId", "$2") + `
+			AND ($1::INT IS NULL OR ` + members.MemberCondition("w.projectId", "$1") + `)
+			AND ` + members.ActiveCondition("w.projectId") + `
         ORDER BY w.createdAt DESC
     `
 
-	rows, err := s.db.Query(queryWorkspaces)
+	rows, err := s.db.Query(queryWorkspaces, memberId, orgId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query workspaces: %w", err)
 	}
@@ -63,7 +73,7 @@ func (s *Store) GetWorkspaces() ([]entities.Workspace, error) {
 	return workspaces, nil
 }
 
-func (s *Store) GetWorkspace(projectId int) ([]entities.Workspace, error) {
+func (s *Store) GetWorkspace(projectId int, filter entities.TaskFilter) ([]entities.Workspace, error) {
 	// Step 1: Fetch Workspaces
 	workspacesQuery := `
     SELECT 
@@ -124,14 +134,28 @@ func (s *Store) GetWorkspace(projectId int) ([]entities.Workspace, error) {
         userId AS task_user_id,
         priorityId AS task_priority_id,
         taskOrder AS task_order,
+        storyPoints AS task_story_points,
+        estimateHours AS task_estimate_hours,
+        dueDate AS task_due_date,
+        completedAt AS task_completedAt,
         createdAt AS task_createdAt,
         updatedAt AS task_updatedAt,
         deletedAt task_deletedAt
     FROM tasks
-    WHERE workspaceId = ANY($1) AND deletedAt IS NULL;
+    WHERE workspaceId = ANY($1) AND deletedAt IS NULL
 `
 
-	taskRows, err := s.db.Query(tasksQuery, pq.Array(workspaceIDs))
+	args := []interface{}{pq.Array(workspaceIDs)}
+	if len(filter.LabelIDs) > 0 {
+		args = append(args, pq.Array(filter.LabelIDs))
+		tasksQuery += " AND " + labels.LabelFilterCondition(entities.LabelTargetTask, "tasks.id", fmt.Sprintf("$%d", len(args)))
+	}
+	for fieldId, value := range filter.CustomFields {
+		args = append(args, fieldId, value)
+		tasksQuery += " AND " + customfields.FilterCondition(entities.CustomFieldTargetTask, "tasks.id", fmt.Sprintf("$%d", len(args)-1), fmt.Sprintf("$%d", len(args)))
+	}
+
+	taskRows, err := s.db.Query(tasksQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tasks: %w", err)
 	}
@@ -149,6 +173,10 @@ func (s *Store) GetWorkspace(projectId int) ([]entities.Workspace, error) {
 			&task.UserID,
 			&task.PriorityID,
 			&task.TaskOrder,
+			&task.StoryPoints,
+			&task.EstimateHours,
+			&task.DueDate,
+			&task.CompletedAt,
 			&task.CreatedAt,
 			&task.UpdatedAt,
 			&task.DeletedAt,
@@ -158,6 +186,40 @@ func (s *Store) GetWorkspace(projectId int) ([]entities.Workspace, error) {
 		}
 		if workspace, exists := workspacesMap[workspaceID]; exists {
 			workspace.Tasks = append(workspace.Tasks, task)
+			if task.StoryPoints != nil {
+				workspace.StoryPoints += *task.StoryPoints
+			}
+			if task.EstimateHours != nil {
+				workspace.EstimateHours += *task.EstimateHours
+			}
+		}
+	}
+
+	// Attach labels and custom field values to the board tasks
+	var taskIDs []int
+	for _, workspace := range workspacesMap {
+		for _, task := range workspace.Tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+	labelsMap, err := labels.GetLabelsFor(s.db, entities.LabelTargetTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	valuesMap, err := customfields.GetValuesFor(s.db, entities.CustomFieldTargetTask, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, workspace := range workspacesMap {
+		for i := range workspace.Tasks {
+			workspace.Tasks[i].Labels = labelsMap[workspace.Tasks[i].ID]
+			if workspace.Tasks[i].Labels == nil {
+				workspace.Tasks[i].Labels = []entities.Label{}
+			}
+			workspace.Tasks[i].CustomFields = valuesMap[workspace.Tasks[i].ID]
+			if workspace.Tasks[i].CustomFields == nil {
+				workspace.Tasks[i].CustomFields = []entities.CustomFieldValue{}
+			}
 		}
 	}
 
@@ -217,6 +279,7 @@ func (s *Store) CreateWorkspace(payload entities.WorkspacePayload) (*entities.Wo
 		UpdatedAt:   time.Now(), // Assuming this field is not overwritten by the DB
 	}
 
+	realtime.Publish(entities.BoardEvent{Type: entities.EventWorkspaceCreated, ProjectID: workspace.ProjectID, Data: workspace})
 	return workspace, nil
 }
 
@@ -248,6 +311,7 @@ func (s *Store) UpdateWorkspace(payload entities.WorkspacePayload) error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	s.publishWorkspace(entities.EventWorkspaceUpdated, payload.ID)
 	return nil
 }
 
@@ -270,6 +334,7 @@ func (s *Store) DeleteWorkspace(id int) error {
 		return err
 	}
 
+	s.publishWorkspace(entities.EventWorkspaceDeleted, id)
 	return err
 }
 
@@ -293,9 +358,23 @@ func (s *Store) RestoreWorkspace(id int) error {
 		return err
 	}
 
+	s.publishWorkspace(entities.EventWorkspaceRestored, id)
 	return err
 }
 
+// publishWorkspace pushes the committed state of a board column to its project
+func (s *Store) publishWorkspace(eventType string, id int) {
+	workspace := entities.Workspace{}
+	err := s.db.QueryRow(`
+		SELECT id, name, description, projectId, colOrder, createdAt, updatedAt FROM workspaces WHERE id = $1
+	`, id).Scan(&workspace.ID, &workspace.Name, &workspace.Description, &workspace.ProjectID, &workspace.ColOrder, &workspace.CreatedAt, &workspace.UpdatedAt)
+	if err != nil {
+		log.Printf("failed to publish %s for workspace %d: %v", eventType, id, err)
+		return
+	}
+	realtime.Publish(entities.BoardEvent{Type: eventType, ProjectID: workspace.ProjectID, Data: workspace})
+}
+
 // func (s *Store) TaskDragNDrop(workspaceId, sourceTaskId, destinationTaskId int) error {
 // 	// Step 1: Fetch tasks for the given workspace ordered by taskOrder
 // 	tasksQuery := `
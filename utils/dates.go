@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the date format used by request payloads, e.g. "Oct-19-2026".
+const DateLayout = "Jan-02-2006"
+
+// ParseOptionalDate parses a payload date, returning nil for an empty value.
+func ParseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected format %s", value, DateLayout)
+	}
+	return &date, nil
+}
+
+// FormatOptionalDate is the inverse of ParseOptionalDate.
+func FormatOptionalDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(DateLayout)
+}
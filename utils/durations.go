@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"log"
+	"time"
+)
+
+// PositiveDuration returns d, or fallback when d is zero or negative, which
+// tickers panic on and leases and client timeouts silently misuse. name says
+// which setting was replaced in the log.
+func PositiveDuration(name string, d, fallback time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	log.Printf("Invalid %s %v, using %v", name, d, fallback)
+	return fallback
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/norrico31/it210-core-service-backend/config"
+)
+
+// StoryPointScale returns the allowed story point values configured by STORY_POINT_SCALE.
+func StoryPointScale() []int {
+	scale := []int{}
+	for _, part := range strings.Split(config.Envs.StoryPointScale, ",") {
+		point, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		scale = append(scale, point)
+	}
+	return scale
+}
+
+// ValidateEstimate checks optional story points against the configured scale
+// and optional hour estimates against ESTIMATE_MAX_HOURS.
+func ValidateEstimate(storyPoints *int, estimateHours *float64) error {
+	if storyPoints != nil {
+		scale := StoryPointScale()
+		valid := false
+		for _, point := range scale {
+			if *storyPoints == point {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("story points must be one of %v", scale)
+		}
+	}
+
+	if estimateHours != nil {
+		if *estimateHours < 0 {
+			return fmt.Errorf("estimate hours must not be negative")
+		}
+		if *estimateHours > float64(config.Envs.EstimateMaxHours) {
+			return fmt.Errorf("estimate hours must not exceed %d", config.Envs.EstimateMaxHours)
+		}
+	}
+
+	return nil
+}
+
+// MergeEstimate applies the estimate of an update payload to the current one.
+// A nil value keeps the current one, and clear removes both; it cannot be
+// combined with new values.
+func MergeEstimate(storyPoints, currentPoints *int, estimateHours, currentHours *float64, clear bool) (*int, *float64, error) {
+	if clear {
+		if storyPoints != nil || estimateHours != nil {
+			return nil, nil, fmt.Errorf("clearEstimate cannot be combined with storyPoints or estimateHours")
+		}
+		return nil, nil, nil
+	}
+	if storyPoints == nil {
+		storyPoints = currentPoints
+	}
+	if estimateHours == nil {
+		estimateHours = currentHours
+	}
+	return storyPoints, estimateHours, nil
+}
@@ -0,0 +1,44 @@
+package utils
+
+import "testing"
+
+func TestMergeEstimate(t *testing.T) {
+	points := func(n int) *int { return &n }
+	hours := func(h float64) *float64 { return &h }
+
+	cases := []struct {
+		name          string
+		storyPoints   *int
+		estimateHours *float64
+		clear         bool
+		wantPoints    *int
+		wantHours     *float64
+		wantErr       bool
+	}{
+		{name: "keeps the current estimate", wantPoints: points(5), wantHours: hours(8)},
+		{name: "replaces story points only", storyPoints: points(3), wantPoints: points(3), wantHours: hours(8)},
+		{name: "replaces hours only", estimateHours: hours(2.5), wantPoints: points(5), wantHours: hours(2.5)},
+		{name: "clears the estimate", clear: true},
+		{name: "refuses a clear with new points", storyPoints: points(3), clear: true, wantErr: true},
+		{name: "refuses a clear with new hours", estimateHours: hours(1), clear: true, wantErr: true},
+	}
+	for _, c := range cases {
+		gotPoints, gotHours, err := MergeEstimate(c.storyPoints, points(5), c.estimateHours, hours(8), c.clear)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if (gotPoints == nil) != (c.wantPoints == nil) || gotPoints != nil && *gotPoints != *c.wantPoints {
+			t.Errorf("%s: got story points %v, want %v", c.name, gotPoints, c.wantPoints)
+		}
+		if (gotHours == nil) != (c.wantHours == nil) || gotHours != nil && *gotHours != *c.wantHours {
+			t.Errorf("%s: got estimate hours %v, want %v", c.name, gotHours, c.wantHours)
+		}
+	}
+}
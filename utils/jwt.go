@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +20,7 @@ func GenerateJWT(u entities.User) (string, error) {
 
 	claims := jwt.MapClaims{
 		"user_id":    u.ID,
+		"org_id":     u.OrgID,
 		"first_name": u.FirstName,
 		"last_name":  u.LastName,
 		"email":      u.Email,
@@ -77,12 +79,43 @@ func ValidateJWT(next http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
+		// Never trust identity headers sent by the client
+		r.Header.Del("X-User-ID")
+		r.Header.Del("X-Org-ID")
+
 		userID, ok := claims["user_id"].(float64)
 		if ok {
 			userIDStr := fmt.Sprintf("%.0f", userID) // Convert to string
 			r.Header.Set("X-User-ID", userIDStr)
 		}
 
+		// Tokens issued before organizations existed carry no tenant
+		orgID, ok := claims["org_id"].(float64)
+		if !ok || orgID == 0 {
+			http.Error(w, "Token has no organization, please log in again", http.StatusUnauthorized)
+			return
+		}
+		r.Header.Set("X-Org-ID", fmt.Sprintf("%.0f", orgID))
+
 		next(w, r)
 	}
 }
+
+// GetUserID returns the id of the authenticated user set by ValidateJWT, or nil.
+func GetUserID(r *http.Request) *int {
+	userId, err := strconv.Atoi(r.Header.Get("X-User-ID"))
+	if err != nil {
+		return nil
+	}
+	return &userId
+}
+
+// GetOrgID returns the organization of the authenticated user set by
+// ValidateJWT, or 0 which matches no tenant.
+func GetOrgID(r *http.Request) int {
+	orgId, err := strconv.Atoi(r.Header.Get("X-Org-ID"))
+	if err != nil {
+		return 0
+	}
+	return orgId
+}
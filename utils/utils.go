@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -32,3 +34,27 @@ func CheckIsEmail(s string) bool {
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	return emailRegex.MatchString(s)
 }
+
+// ParseIntList parses a comma separated query value such as "1,2,3".
+func ParseIntList(s string) ([]int, error) {
+	ids := []int{}
+	if strings.TrimSpace(s) == "" {
+		return ids, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// IntChanged reports whether an optional value differs, treating nil as unset.
+func IntChanged(from, to *int) bool {
+	if from == nil || to == nil {
+		return from != to
+	}
+	return *from != *to
+}